@@ -6,13 +6,21 @@ import (
 	"fmt"
 	user "github.com/kerim-dauren/user-service/gen/proto"
 	"github.com/kerim-dauren/user-service/internal/api"
+	"github.com/kerim-dauren/user-service/internal/api/grpc/interceptors"
 	v1 "github.com/kerim-dauren/user-service/internal/api/grpc/v1"
 	"github.com/kerim-dauren/user-service/internal/configs"
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/kerim-dauren/user-service/internal/notifiers"
 	"github.com/kerim-dauren/user-service/internal/services"
 	"github.com/kerim-dauren/user-service/internal/storages/pg"
+	"github.com/kerim-dauren/user-service/pkg/breachx"
+	"github.com/kerim-dauren/user-service/pkg/cryptox"
 	"github.com/kerim-dauren/user-service/pkg/hashx"
+	"github.com/kerim-dauren/user-service/pkg/mailx"
 	"github.com/kerim-dauren/user-service/pkg/postgresx"
 	"github.com/kerim-dauren/user-service/pkg/slogx"
+	"github.com/kerim-dauren/user-service/pkg/tokenx"
+	"github.com/prometheus/client_golang/prometheus"
 	"google.golang.org/grpc"
 	"log"
 	"net"
@@ -52,12 +60,241 @@ func main() {
 	defer dbPool.Close()
 
 	userStorage := pg.NewUserStorage(dbPool)
-	hasher := hashx.NewArgon2Hasher()
-	userService := services.NewUserService(logger, userStorage, hasher)
+	refreshTokenStorage := pg.NewRefreshTokenStorage(dbPool)
+	sessionStorage := pg.NewSessionStorage(dbPool)
+	sessionService := services.NewSessionService(logger, sessionStorage, refreshTokenStorage)
+	argon2Params := hashx.DefaultArgon2Params()
+	argon2Params.Memory = cfg.Hash.Argon2Memory
+	argon2Params.Iterations = cfg.Hash.Argon2Iterations
+	if cfg.Hash.Argon2Parallelism > 0 {
+		argon2Params.Parallelism = cfg.Hash.Argon2Parallelism
+	}
+	scryptParams := hashx.DefaultScryptParams()
+	scryptParams.LogN = cfg.Hash.ScryptLogN
+	scryptParams.R = cfg.Hash.ScryptR
+	scryptParams.P = cfg.Hash.ScryptP
+	hasher, err := hashx.NewHasher(hashx.Config{
+		Algorithm:  cfg.Hash.Algorithm,
+		Argon2:     argon2Params,
+		BcryptCost: cfg.Hash.BcryptCost,
+		Scrypt:     scryptParams,
+	})
+	if err != nil {
+		log.Fatalf("password hasher: %v", err)
+	}
+	checker := hashx.NewChecker()
+	// bcrypt limits passwords in bytes; a pepper hands it a fixed-size digest instead.
+	passwordMaxBytes := 0
+	if cfg.Hash.Algorithm == hashx.AlgorithmBcrypt {
+		passwordMaxBytes = hashx.BcryptMaxPasswordBytes
+	}
+	if cfg.Hash.PepperKeys != "" || cfg.Hash.PepperKeysFile != "" {
+		var pepperKeys map[string][]byte
+		if cfg.Hash.PepperKeysFile != "" {
+			pepperKeys, err = hashx.LoadPepperKeys(cfg.Hash.PepperKeysFile)
+		} else {
+			pepperKeys, err = hashx.ParsePepperKeys(cfg.Hash.PepperKeys)
+		}
+		if err != nil {
+			log.Fatalf("pepper keys: %v", err)
+		}
+		pepper, err := hashx.NewPepper(cfg.Hash.PepperCurrentKey, pepperKeys)
+		if err != nil {
+			log.Fatalf("pepper keys: %v", err)
+		}
+		hasher = hashx.NewPepperedHasher(pepper, hasher)
+		passwordMaxBytes = 0
+		checker = hashx.NewPepperedChecker(pepper, checker)
+	}
+	hashPool, err := hashx.NewPool(hashx.PoolConfig{
+		Workers:    cfg.Hash.Workers,
+		QueueSize:  cfg.Hash.QueueSize,
+		Registerer: prometheus.DefaultRegisterer,
+	}, hasher, checker)
+	if err != nil {
+		log.Fatalf("password hashing pool: %v", err)
+	}
+	var breachedPasswords services.BreachedPasswords
+	if cfg.Validation.BreachedPasswordsFile != "" {
+		list, err := breachx.Load(cfg.Validation.BreachedPasswordsFile)
+		if err != nil {
+			log.Fatalf("breached passwords: %v", err)
+		}
+		logger.Info("breached password list loaded", "passwords", list.Len())
+		breachedPasswords = list
+	}
+	userValidator, err := services.NewUserValidator(services.UserRules{
+		UsernameMinLength:      cfg.Validation.UsernameMinLength,
+		UsernameMaxLength:      cfg.Validation.UsernameMaxLength,
+		UsernamePattern:        cfg.Validation.UsernamePattern,
+		EmailMaxLength:         cfg.Validation.EmailMaxLength,
+		PasswordMinLength:      cfg.Validation.PasswordMinLength,
+		PasswordMaxLength:      cfg.Validation.PasswordMaxLength,
+		PasswordMaxBytes:       passwordMaxBytes,
+		PasswordRequireLower:   cfg.Validation.PasswordRequireLower,
+		PasswordRequireUpper:   cfg.Validation.PasswordRequireUpper,
+		PasswordRequireDigit:   cfg.Validation.PasswordRequireDigit,
+		PasswordRequireSymbol:  cfg.Validation.PasswordRequireSymbol,
+		PasswordRejectIdentity: cfg.Validation.PasswordRejectIdentity,
+	}, breachedPasswords)
+	if err != nil {
+		log.Fatalf("validation rules: %v", err)
+	}
+	pageTokens, err := tokenx.NewPageTokenCodec(cfg.Pagination.TokenSecret)
+	if err != nil {
+		log.Fatalf("page tokens: %v", err)
+	}
+	if cfg.Pagination.TokenSecret == "" {
+		logger.Warn("PAGINATION_TOKEN_SECRET is not set, using an ephemeral page token key")
+	}
+	userService := services.NewUserService(
+		logger, userStorage, hashPool, hashPool, userValidator, pageTokens, sessionService,
+		pg.NewPasswordHistoryStorage(dbPool), services.PasswordHistoryConfig{
+			Size:      cfg.Validation.PasswordHistorySize,
+			Retention: cfg.Validation.PasswordHistoryRetention,
+		},
+	)
 
-	httpRouter := api.NewHttpRouter(&api.RouterDeps{
-		UserService: userService,
+	tokenManager, err := tokenx.NewManager(tokenx.Config{
+		Algorithm:      cfg.JWT.Algorithm,
+		Secret:         cfg.JWT.Secret,
+		PrivateKeyFile: cfg.JWT.PrivateKeyFile,
+		KeyID:          cfg.JWT.KeyID,
+		Issuer:         cfg.JWT.Issuer,
+		TTL:            cfg.JWT.AccessTokenTTL,
 	})
+	if err != nil {
+		log.Fatalf("token manager: %v", err)
+	}
+	if cfg.JWT.PrivateKeyFile == "" && cfg.JWT.Algorithm != tokenx.AlgorithmHS256 {
+		logger.Warn("JWT_PRIVATE_KEY_FILE is not set, using an ephemeral signing key")
+	}
+	// An ephemeral key makes every stored TOTP secret unreadable after a restart or on another replica.
+	if cfg.MFA.EncryptionKey == "" && cfg.AppEnv != configs.AppEnvDev {
+		log.Fatalf("mfa encryption key: MFA_ENCRYPTION_KEY is required when APP_ENV is not %s", configs.AppEnvDev)
+	}
+	mfaSecrets, err := cryptox.NewBox(cfg.MFA.EncryptionKey)
+	if err != nil {
+		log.Fatalf("mfa encryption key: %v", err)
+	}
+	if cfg.MFA.EncryptionKey == "" {
+		logger.Warn("MFA_ENCRYPTION_KEY is not set, using an ephemeral key; TOTP enrollments do not survive a restart")
+	}
+	mfaService := services.NewMFAService(logger, userService, pg.NewMFAStorage(dbPool), mfaSecrets, services.MFAConfig{
+		Issuer:            cfg.MFA.Issuer,
+		ChallengeTTL:      cfg.MFA.ChallengeTTL,
+		MaxAttempts:       cfg.MFA.MaxAttempts,
+		RecoveryCodeCount: cfg.MFA.RecoveryCodeCount,
+	})
+	passkeyService, err := services.NewPasskeyService(logger, userService, pg.NewPasskeyStorage(dbPool), services.PasskeyConfig{
+		RPID:          cfg.WebAuthn.RPID,
+		RPDisplayName: cfg.WebAuthn.RPDisplayName,
+		RPOrigins:     cfg.WebAuthn.RPOrigins,
+		CeremonyTTL:   cfg.WebAuthn.CeremonyTTL,
+	})
+	if err != nil {
+		log.Fatalf("passkeys: %v", err)
+	}
+	mailSender, err := mailx.NewSender(mailx.Config{
+		Driver: cfg.Mail.Driver,
+		Dir:    cfg.Mail.Dir,
+		SMTP: mailx.SMTPConfig{
+			Host:     cfg.Mail.SMTPHost,
+			Port:     cfg.Mail.SMTPPort,
+			Username: cfg.Mail.SMTPUsername,
+			Password: cfg.Mail.SMTPPassword,
+			TLSMode:  cfg.Mail.SMTPTLSMode,
+			Timeout:  cfg.Mail.SMTPTimeout,
+		},
+	}, logger)
+	if err != nil {
+		log.Fatalf("mail sender: %v", err)
+	}
+	if cfg.Mail.Driver != mailx.DriverSMTP {
+		logger.Warn("MAIL_DRIVER is not smtp, emails are not delivered", "driver", cfg.Mail.Driver)
+	}
+	mailTemplates, err := notifiers.LoadTemplates(cfg.Mail.TemplatesDir)
+	if err != nil {
+		log.Fatalf("mail templates: %v", err)
+	}
+	notifier := notifiers.NewMailNotifier(mailSender, mailTemplates, cfg.Mail.From, cfg.Mail.Locale)
+	passwordResetService := services.NewPasswordResetService(
+		logger, userService, pg.NewPasswordResetStorage(dbPool), userValidator, notifier,
+		services.PasswordResetConfig{
+			TTL:         cfg.Auth.PasswordResetTTL,
+			URL:         cfg.Auth.PasswordResetURL,
+			Limit:       cfg.Auth.PasswordResetLimit,
+			LimitWindow: cfg.Auth.PasswordResetLimitWindow,
+		},
+	)
+	emailVerificationService := services.NewEmailVerificationService(
+		logger, userService, pg.NewEmailVerificationStorage(dbPool), notifier,
+		services.EmailVerificationConfig{
+			TTL:         cfg.Auth.EmailVerificationTTL,
+			URL:         cfg.Auth.EmailVerificationURL,
+			Limit:       cfg.Auth.EmailVerificationLimit,
+			LimitWindow: cfg.Auth.EmailVerificationLimitWindow,
+		},
+	)
+	magicLinkService := services.NewMagicLinkService(
+		logger, userService, pg.NewMagicLinkStorage(dbPool), notifier,
+		services.MagicLinkConfig{
+			TTL:         cfg.Auth.MagicLinkTTL,
+			URL:         cfg.Auth.MagicLinkURL,
+			Limit:       cfg.Auth.MagicLinkLimit,
+			LimitWindow: cfg.Auth.MagicLinkLimitWindow,
+		},
+	)
+	lockoutService := services.NewLockoutService(logger, userService, pg.NewLockoutStorage(dbPool), services.LockoutConfig{
+		Account:     services.LockoutThresholds{BackoffAfter: cfg.Lockout.AccountBackoffAfter, LockAfter: cfg.Lockout.AccountLockAfter},
+		IP:          services.LockoutThresholds{BackoffAfter: cfg.Lockout.IPBackoffAfter, LockAfter: cfg.Lockout.IPLockAfter},
+		BackoffBase: cfg.Lockout.BackoffBase,
+		BackoffMax:  cfg.Lockout.BackoffMax,
+		Duration:    cfg.Lockout.Duration,
+		ResetAfter:  cfg.Lockout.ResetAfter,
+	})
+	roleService := services.NewRoleService(logger, userService, pg.NewRoleStorage(dbPool))
+	organizationStorage := pg.NewOrganizationStorage(dbPool)
+	organizationService := services.NewOrganizationService(logger, organizationStorage)
+	invitationService := services.NewInvitationService(
+		logger, userService, organizationStorage, pg.NewInvitationStorage(dbPool), userValidator, notifier,
+		services.InvitationConfig{TTL: cfg.Auth.InvitationTTL, URL: cfg.Auth.InvitationURL},
+	)
+	for _, id := range cfg.Auth.AdminUserIDs {
+		if err := roleService.AssignRole(ctx, id, domain.RoleAdmin); err != nil {
+			logger.Warn("failed to grant the admin role", "user_id", id, "error", err)
+		}
+		// Sign-ups join the default organization as members, so it gets its owners here.
+		if err := organizationStorage.SetMemberRole(ctx, domain.DefaultOrganizationID, id, domain.OrganizationRoleOwner); err != nil {
+			logger.Warn("failed to make an owner of the default organization", "user_id", id, "error", err)
+		}
+	}
+	authService := services.NewAuthService(
+		logger, userService, tokenManager, refreshTokenStorage, sessionStorage, mfaService, passkeyService, magicLinkService,
+		lockoutService, roleService, cfg.Auth.RefreshTokenTTL,
+		services.LoginPolicy{RequireVerifiedEmail: cfg.Auth.RequireVerifiedEmail},
+	)
+
+	httpRouter, err := api.NewHttpRouter(&api.RouterDeps{
+		Logger:                   logger,
+		UserService:              userService,
+		AuthService:              authService,
+		SessionService:           sessionService,
+		PasswordResetService:     passwordResetService,
+		EmailVerificationService: emailVerificationService,
+		MFAService:               mfaService,
+		PasskeyService:           passkeyService,
+		MagicLinkService:         magicLinkService,
+		LockoutService:           lockoutService,
+		RoleService:              roleService,
+		OrganizationService:      organizationService,
+		InvitationService:        invitationService,
+		JWKS:                     tokenManager.JWKS,
+		TrustedProxies:           cfg.HttpTrustedProxies,
+	})
+	if err != nil {
+		log.Fatalf("http router: %v", err)
+	}
 
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%d", cfg.HttpPort),
@@ -84,8 +321,25 @@ func main() {
 		}
 		logger.Info("grpc server started", "port", cfg.GRPCPort)
 
-		grpcServer := grpc.NewServer()
-		user.RegisterUserServiceServer(grpcServer, v1.NewUserService(userService))
+		grpcServer := grpc.NewServer(
+			grpc.ChainUnaryInterceptor(
+				interceptors.Errors(logger),
+				interceptors.Tenant(),
+				interceptors.Auth(authService, v1.PublicMethods...),
+				interceptors.RequirePermissions(v1.MethodPermissions),
+			),
+		)
+		user.RegisterUserServiceServer(grpcServer, v1.NewUserService(v1.Services{
+			UserService:              userService,
+			AuthService:              authService,
+			SessionService:           sessionService,
+			PasswordResetService:     passwordResetService,
+			EmailVerificationService: emailVerificationService,
+			MFAService:               mfaService,
+			MagicLinkService:         magicLinkService,
+			LockoutService:           lockoutService,
+			RoleService:              roleService,
+		}))
 
 		if err := grpcServer.Serve(lis); err != nil {
 			errch <- fmt.Errorf("failed to serve grpc server: %w", err)
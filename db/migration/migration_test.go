@@ -0,0 +1,116 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The tests run the scripts against the database named by TEST_PG_URL, each in a schema
+// of its own, and are skipped without it.
+const testDatabaseEnv = "TEST_PG_URL"
+
+func TestUniqueUsernameRenamesDuplicates(t *testing.T) {
+	conn := testConn(t)
+	ctx := context.Background()
+
+	migrateUpTo(t, conn, 1)
+	_, err := conn.Exec(ctx, `INSERT INTO users (id, username, email, password, created_at) VALUES
+		(1, 'bob', 'bob@example.com', 'hash', '2024-02-01'),
+		(2, 'bob', 'bobby@example.com', 'hash', '2024-01-01'),
+		(3, 'alice', 'alice@example.com', 'hash', NULL)`)
+	require.NoError(t, err)
+	migrateUpTo(t, conn, 2)
+
+	rows, err := conn.Query(ctx, `SELECT id, username FROM users ORDER BY id`)
+	require.NoError(t, err)
+	usernames, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (string, error) {
+		var id int64
+		var username string
+		err := row.Scan(&id, &username)
+		return fmt.Sprintf("%d:%s", id, username), err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1:bob-1", "2:bob", "3:alice"}, usernames, "the earliest account keeps the name")
+}
+
+func TestOrganizationsBackfillsOwner(t *testing.T) {
+	conn := testConn(t)
+	ctx := context.Background()
+
+	migrateUpTo(t, conn, 13)
+	_, err := conn.Exec(ctx, `INSERT INTO users (username, email, password, created_at) VALUES
+		('bob', 'bob@example.com', 'hash', '2024-02-01'),
+		('alice', 'alice@example.com', 'hash', '2024-01-01'),
+		('carol', 'carol@example.com', 'hash', NULL)`)
+	require.NoError(t, err)
+	migrateUpTo(t, conn, 14)
+
+	rows, err := conn.Query(ctx, `SELECT u.username, m.role FROM organization_members m
+		JOIN users u ON u.id = m.user_id WHERE m.organization_id = 1`)
+	require.NoError(t, err)
+	roles, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) ([2]string, error) {
+		var r [2]string
+		err := row.Scan(&r[0], &r[1])
+		return r, err
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, [][2]string{{"alice", "owner"}, {"bob", "member"}, {"carol", "member"}}, roles)
+}
+
+func testConn(t *testing.T) *pgx.Conn {
+	t.Helper()
+	url := os.Getenv(testDatabaseEnv)
+	if url == "" {
+		t.Skipf("%s is not set", testDatabaseEnv)
+	}
+	ctx := context.Background()
+	conn, err := pgx.Connect(ctx, url)
+	require.NoError(t, err)
+
+	schema := fmt.Sprintf("migration_test_%d", time.Now().UnixNano())
+	_, err = conn.Exec(ctx, `CREATE SCHEMA `+schema+`; SET search_path TO `+schema)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = conn.Exec(ctx, `DROP SCHEMA `+schema+` CASCADE`)
+		_ = conn.Close(ctx)
+	})
+	return conn
+}
+
+// migrateUpTo runs the Up section of every script after the last one applied up to version,
+// as goose would.
+func migrateUpTo(t *testing.T, conn *pgx.Conn, version int) {
+	t.Helper()
+	ctx := context.Background()
+	_, err := conn.Exec(ctx, `CREATE TABLE IF NOT EXISTS applied_scripts (version INT PRIMARY KEY)`)
+	require.NoError(t, err)
+	var applied int
+	require.NoError(t, conn.QueryRow(ctx, `SELECT COALESCE(max(version), 0) FROM applied_scripts`).Scan(&applied))
+
+	scripts, err := filepath.Glob(filepath.Join("scripts", "*.sql"))
+	require.NoError(t, err)
+	for _, script := range scripts {
+		var n int
+		_, err := fmt.Sscanf(filepath.Base(script), "%05d_", &n)
+		require.NoError(t, err, script)
+		if n <= applied || n > version {
+			continue
+		}
+		data, err := os.ReadFile(script)
+		require.NoError(t, err)
+		up, _, _ := strings.Cut(string(data), "-- +goose Down")
+		_, err = conn.Exec(ctx, up, pgx.QueryExecModeSimpleProtocol)
+		require.NoError(t, err, script)
+		_, err = conn.Exec(ctx, `INSERT INTO applied_scripts (version) VALUES ($1)`, n)
+		require.NoError(t, err)
+	}
+}
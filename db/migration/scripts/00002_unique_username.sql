@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Databases from before logins by username may hold duplicates. The earliest account keeps the
+-- name and the others get their ID appended, e.g. "bob-42"; they can still log in by email.
+UPDATE users
+SET username = left(users.username, 99 - length(users.id::TEXT)) || '-' || users.id
+FROM (SELECT id, row_number() OVER (PARTITION BY username ORDER BY created_at NULLS LAST, id) AS n
+      FROM users) duplicates
+WHERE duplicates.id = users.id
+  AND duplicates.n > 1;
+CREATE UNIQUE INDEX IF NOT EXISTS users_username_key ON users (username);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS users_username_key
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+UPDATE users SET created_at = NOW() WHERE created_at IS NULL;
+ALTER TABLE users ALTER COLUMN created_at SET DEFAULT NOW();
+ALTER TABLE users ALTER COLUMN created_at SET NOT NULL;
+CREATE INDEX IF NOT EXISTS users_created_at_id_idx ON users (created_at, id);
+CREATE INDEX IF NOT EXISTS users_email_pattern_idx ON users (email text_pattern_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS users_email_pattern_idx;
+DROP INDEX IF EXISTS users_created_at_id_idx;
+ALTER TABLE users ALTER COLUMN created_at DROP NOT NULL;
+ALTER TABLE users ALTER COLUMN created_at DROP DEFAULT;
+-- +goose StatementEnd
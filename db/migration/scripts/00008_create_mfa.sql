@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS user_totp_credentials
+(
+    user_id          BIGINT PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    secret_encrypted TEXT         NOT NULL,
+    enabled_at       TIMESTAMP(3),
+    last_used_step   BIGINT       NOT NULL DEFAULT 0,
+    created_at       TIMESTAMP(3) NOT NULL
+);
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes
+(
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    BIGINT       NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    code_hash  VARCHAR(64)  NOT NULL,
+    used_at    TIMESTAMP(3),
+    created_at TIMESTAMP(3) NOT NULL,
+    UNIQUE (user_id, code_hash)
+);
+CREATE TABLE IF NOT EXISTS mfa_challenges
+(
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    BIGINT       NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    token_hash VARCHAR(64)  NOT NULL UNIQUE,
+    expires_at TIMESTAMP(3) NOT NULL,
+    attempts   INT          NOT NULL DEFAULT 0,
+    created_at TIMESTAMP(3) NOT NULL
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS mfa_challenges CASCADE;
+DROP TABLE IF EXISTS mfa_recovery_codes CASCADE;
+DROP TABLE IF EXISTS user_totp_credentials CASCADE;
+-- +goose StatementEnd
@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS webauthn_credentials
+(
+    id               BIGSERIAL PRIMARY KEY,
+    user_id          BIGINT       NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    credential_id    BYTEA        NOT NULL UNIQUE,
+    name             VARCHAR(100) NOT NULL,
+    public_key       BYTEA        NOT NULL,
+    attestation_type VARCHAR(32)  NOT NULL,
+    transports       TEXT[]       NOT NULL DEFAULT '{}',
+    aaguid           BYTEA        NOT NULL,
+    sign_count       BIGINT       NOT NULL DEFAULT 0,
+    backup_eligible  BOOLEAN      NOT NULL DEFAULT FALSE,
+    backup_state     BOOLEAN      NOT NULL DEFAULT FALSE,
+    created_at       TIMESTAMP(3) NOT NULL,
+    last_used_at     TIMESTAMP(3)
+);
+CREATE INDEX IF NOT EXISTS webauthn_credentials_user_id_idx ON webauthn_credentials (user_id);
+CREATE TABLE IF NOT EXISTS webauthn_ceremonies
+(
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    BIGINT REFERENCES users (id) ON DELETE CASCADE,
+    kind       VARCHAR(16)  NOT NULL,
+    token_hash VARCHAR(64)  NOT NULL UNIQUE,
+    data       JSONB        NOT NULL,
+    expires_at TIMESTAMP(3) NOT NULL,
+    created_at TIMESTAMP(3) NOT NULL
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS webauthn_ceremonies CASCADE;
+DROP TABLE IF EXISTS webauthn_credentials CASCADE;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS login_failures
+(
+    scope           VARCHAR(16)  NOT NULL,
+    key             VARCHAR(64)  NOT NULL,
+    failures        INT          NOT NULL,
+    last_failure_at TIMESTAMP(3) NOT NULL,
+    locked_until    TIMESTAMP(3),
+    PRIMARY KEY (scope, key)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS login_failures CASCADE
+-- +goose StatementEnd
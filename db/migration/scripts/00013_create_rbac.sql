@@ -0,0 +1,52 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS roles
+(
+    id          BIGSERIAL PRIMARY KEY,
+    name        VARCHAR(64)  NOT NULL UNIQUE,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    created_at  TIMESTAMP(3) NOT NULL DEFAULT now()
+);
+CREATE TABLE IF NOT EXISTS permissions
+(
+    name        VARCHAR(64) PRIMARY KEY,
+    description VARCHAR(255) NOT NULL DEFAULT ''
+);
+CREATE TABLE IF NOT EXISTS role_permissions
+(
+    role_id    BIGINT      NOT NULL REFERENCES roles (id) ON DELETE CASCADE,
+    permission VARCHAR(64) NOT NULL REFERENCES permissions (name) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission)
+);
+CREATE TABLE IF NOT EXISTS user_roles
+(
+    user_id     BIGINT       NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role_id     BIGINT       NOT NULL REFERENCES roles (id) ON DELETE CASCADE,
+    assigned_at TIMESTAMP(3) NOT NULL,
+    PRIMARY KEY (user_id, role_id)
+);
+CREATE INDEX IF NOT EXISTS user_roles_role_id_idx ON user_roles (role_id);
+
+INSERT INTO permissions (name, description)
+VALUES ('users:read', 'Read and list the accounts of other users'),
+       ('users:write', 'Update the accounts of other users'),
+       ('users:delete', 'Delete the accounts of other users'),
+       ('users:unlock', 'Lift login lockouts'),
+       ('roles:read', 'List roles and role assignments'),
+       ('roles:write', 'Assign and revoke roles');
+INSERT INTO roles (name, description)
+VALUES ('admin', 'Full administrative access');
+INSERT INTO role_permissions (role_id, permission)
+SELECT roles.id, permissions.name
+FROM roles,
+     permissions
+WHERE roles.name = 'admin';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_roles CASCADE;
+DROP TABLE IF EXISTS role_permissions CASCADE;
+DROP TABLE IF EXISTS permissions CASCADE;
+DROP TABLE IF EXISTS roles CASCADE
+-- +goose StatementEnd
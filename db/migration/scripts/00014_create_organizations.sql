@@ -0,0 +1,57 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS organizations
+(
+    id         BIGSERIAL PRIMARY KEY,
+    slug       VARCHAR(63)  NOT NULL UNIQUE,
+    name       VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP(3) NOT NULL DEFAULT now()
+);
+INSERT INTO organizations (id, slug, name)
+VALUES (1, 'default', 'Default');
+SELECT setval(pg_get_serial_sequence('organizations', 'id'), (SELECT max(id) FROM organizations));
+
+-- Accounts stay global, with usernames and emails unique across organizations, and join
+-- any number of organizations through memberships, each with its own role.
+CREATE TABLE IF NOT EXISTS organization_members
+(
+    organization_id BIGINT       NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    user_id         BIGINT       NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role            VARCHAR(32)  NOT NULL,
+    joined_at       TIMESTAMP(3) NOT NULL,
+    PRIMARY KEY (organization_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS organization_members_user_id_idx ON organization_members (user_id, joined_at);
+-- Existing accounts join the default organization, and the earliest one becomes its owner.
+INSERT INTO organization_members (organization_id, user_id, role, joined_at)
+SELECT 1,
+       id,
+       CASE WHEN row_number() OVER (ORDER BY created_at NULLS LAST, id) = 1 THEN 'owner' ELSE 'member' END,
+       COALESCE(created_at, now())
+FROM users;
+
+-- A session acts in the organization it was started in.
+ALTER TABLE sessions
+    ADD COLUMN organization_id BIGINT NOT NULL DEFAULT 1 REFERENCES organizations (id) ON DELETE CASCADE;
+ALTER TABLE sessions
+    ALTER COLUMN organization_id DROP DEFAULT;
+
+INSERT INTO permissions (name, description)
+VALUES ('organizations:read', 'List organizations'),
+       ('organizations:write', 'Create organizations');
+INSERT INTO role_permissions (role_id, permission)
+SELECT roles.id, permissions.name
+FROM roles,
+     permissions
+WHERE roles.name = 'admin'
+  AND permissions.name IN ('organizations:read', 'organizations:write');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM permissions WHERE name IN ('organizations:read', 'organizations:write');
+DROP TABLE IF EXISTS organization_members CASCADE;
+ALTER TABLE sessions
+    DROP COLUMN IF EXISTS organization_id;
+DROP TABLE IF EXISTS organizations CASCADE
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS organization_invitations
+(
+    id              BIGSERIAL PRIMARY KEY,
+    organization_id BIGINT       NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    email           VARCHAR(100) NOT NULL,
+    role            VARCHAR(32)  NOT NULL,
+    inviter_id      BIGINT       REFERENCES users (id) ON DELETE SET NULL,
+    status          VARCHAR(16)  NOT NULL DEFAULT 'pending',
+    token_hash      VARCHAR(64)  NOT NULL UNIQUE,
+    expires_at      TIMESTAMP(3) NOT NULL,
+    responded_at    TIMESTAMP(3),
+    created_at      TIMESTAMP(3) NOT NULL
+);
+CREATE INDEX IF NOT EXISTS organization_invitations_organization_id_idx ON organization_invitations (organization_id, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS organization_invitations CASCADE
+-- +goose StatementEnd
@@ -0,0 +1,44 @@
+package apierr
+
+import (
+	"strings"
+
+	"github.com/kerim-dauren/user-service/internal/domain"
+)
+
+// ContentTypeProblem is the media type of RFC 7807 problem details.
+const ContentTypeProblem = "application/problem+json"
+
+// Problem is an RFC 7807 problem details document extended with a stable code,
+// the request trace ID and, for validation failures, the offending fields.
+type Problem struct {
+	Type     string                  `json:"type"`
+	Title    string                  `json:"title"`
+	Status   int                     `json:"status"`
+	Detail   string                  `json:"detail,omitempty"`
+	Instance string                  `json:"instance,omitempty"`
+	Code     string                  `json:"code"`
+	TraceID  string                  `json:"trace_id,omitempty"`
+	Errors   []domain.FieldViolation `json:"errors,omitempty"`
+}
+
+// NewProblem builds the problem document for err. The boolean result is false
+// when err is not registered and was reported as an internal error.
+func NewProblem(err error, instance, traceID string) (Problem, bool) {
+	entry, ok := Lookup(err)
+	return Problem{
+		Type:     TypeURI(entry.Code),
+		Title:    entry.Title,
+		Status:   entry.HTTPStatus,
+		Detail:   entry.Message,
+		Instance: instance,
+		Code:     entry.Code,
+		TraceID:  traceID,
+		Errors:   Violations(err),
+	}, ok
+}
+
+// TypeURI returns the problem type URI of a code, e.g. urn:user-service:problem:user-not-found.
+func TypeURI(code string) string {
+	return "urn:" + Domain + ":problem:" + strings.ReplaceAll(strings.ToLower(code), "_", "-")
+}
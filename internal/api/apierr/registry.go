@@ -0,0 +1,214 @@
+// Package apierr maps domain errors to the stable codes and transport statuses
+// exposed by the HTTP and gRPC APIs.
+package apierr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"google.golang.org/grpc/codes"
+)
+
+// Domain is reported as google.rpc.ErrorInfo.domain.
+const Domain = "user-service"
+
+const (
+	CodeInternal         = "INTERNAL"
+	CodeValidationFailed = "VALIDATION_FAILED"
+)
+
+// StatusClientClosedRequest is reported when the client goes away before the response.
+const StatusClientClosedRequest = 499
+
+// Entry describes how an error is presented to clients.
+type Entry struct {
+	Code       string     // Stable machine-readable code, e.g. USER_NOT_FOUND
+	Title      string     // Short human-readable summary of the problem type
+	Message    string     // Client-safe message
+	HTTPStatus int        // HTTP status code
+	GRPCCode   codes.Code // gRPC status code
+}
+
+type registration struct {
+	err   error
+	entry Entry
+}
+
+var registry = []registration{
+	{domain.ErrUserNotFound, Entry{
+		Code: "USER_NOT_FOUND", Title: "User not found",
+		HTTPStatus: http.StatusNotFound, GRPCCode: codes.NotFound,
+	}},
+	{domain.ErrSessionNotFound, Entry{
+		Code: "SESSION_NOT_FOUND", Title: "Session not found",
+		HTTPStatus: http.StatusNotFound, GRPCCode: codes.NotFound,
+	}},
+	{domain.ErrUserMailAlreadyExists, Entry{
+		Code: "EMAIL_ALREADY_EXISTS", Title: "Email already exists",
+		HTTPStatus: http.StatusConflict, GRPCCode: codes.AlreadyExists,
+	}},
+	{domain.ErrUsernameAlreadyExists, Entry{
+		Code: "USERNAME_ALREADY_EXISTS", Title: "Username already exists",
+		HTTPStatus: http.StatusConflict, GRPCCode: codes.AlreadyExists,
+	}},
+	{domain.ErrInvalidCredentials, Entry{
+		Code: "INVALID_CREDENTIALS", Title: "Invalid credentials",
+		HTTPStatus: http.StatusUnauthorized, GRPCCode: codes.Unauthenticated,
+	}},
+	{domain.ErrUnauthorized, Entry{
+		Code: "UNAUTHENTICATED", Title: "Authentication required",
+		HTTPStatus: http.StatusUnauthorized, GRPCCode: codes.Unauthenticated,
+	}},
+	{domain.ErrIncorrectPassword, Entry{
+		Code: "INCORRECT_PASSWORD", Title: "Incorrect password",
+		HTTPStatus: http.StatusForbidden, GRPCCode: codes.PermissionDenied,
+	}},
+	{domain.ErrEmailNotVerified, Entry{
+		Code: "EMAIL_NOT_VERIFIED", Title: "Email not verified",
+		HTTPStatus: http.StatusForbidden, GRPCCode: codes.PermissionDenied,
+	}},
+	{domain.ErrInvalidMFACode, Entry{
+		Code: "INVALID_MFA_CODE", Title: "Invalid MFA code",
+		HTTPStatus: http.StatusForbidden, GRPCCode: codes.PermissionDenied,
+	}},
+	{domain.ErrInvalidMFAChallenge, Entry{
+		Code: "INVALID_MFA_CHALLENGE", Title: "Invalid MFA challenge",
+		HTTPStatus: http.StatusUnauthorized, GRPCCode: codes.Unauthenticated,
+	}},
+	{domain.ErrMFAAlreadyEnabled, Entry{
+		Code: "MFA_ALREADY_ENABLED", Title: "MFA already enabled",
+		HTTPStatus: http.StatusConflict, GRPCCode: codes.FailedPrecondition,
+	}},
+	{domain.ErrMFANotEnabled, Entry{
+		Code: "MFA_NOT_ENABLED", Title: "MFA not enabled",
+		HTTPStatus: http.StatusConflict, GRPCCode: codes.FailedPrecondition,
+	}},
+	{domain.ErrPasskeyNotFound, Entry{
+		Code: "PASSKEY_NOT_FOUND", Title: "Passkey not found",
+		HTTPStatus: http.StatusNotFound, GRPCCode: codes.NotFound,
+	}},
+	{domain.ErrPasskeyExists, Entry{
+		Code: "PASSKEY_ALREADY_EXISTS", Title: "Passkey already registered",
+		HTTPStatus: http.StatusConflict, GRPCCode: codes.AlreadyExists,
+	}},
+	{domain.ErrInvalidPasskeySession, Entry{
+		Code: "INVALID_PASSKEY_SESSION", Title: "Invalid passkey session",
+		HTTPStatus: http.StatusBadRequest, GRPCCode: codes.InvalidArgument,
+	}},
+	{domain.ErrInvalidPasskeyAttestation, Entry{
+		Code: "INVALID_PASSKEY_ATTESTATION", Title: "Invalid passkey attestation",
+		HTTPStatus: http.StatusBadRequest, GRPCCode: codes.InvalidArgument,
+	}},
+	{domain.ErrInvalidPasskey, Entry{
+		Code: "INVALID_PASSKEY", Title: "Passkey verification failed",
+		HTTPStatus: http.StatusUnauthorized, GRPCCode: codes.Unauthenticated,
+	}},
+	{domain.ErrLoginLocked, Entry{
+		Code: "LOGIN_LOCKED", Title: "Login temporarily locked",
+		HTTPStatus: http.StatusTooManyRequests, GRPCCode: codes.ResourceExhausted,
+	}},
+	{domain.ErrRoleNotFound, Entry{
+		Code: "ROLE_NOT_FOUND", Title: "Role not found",
+		HTTPStatus: http.StatusNotFound, GRPCCode: codes.NotFound,
+	}},
+	{domain.ErrOrganizationNotFound, Entry{
+		Code: "ORGANIZATION_NOT_FOUND", Title: "Organization not found",
+		HTTPStatus: http.StatusNotFound, GRPCCode: codes.NotFound,
+	}},
+	{domain.ErrOrganizationExists, Entry{
+		Code: "ORGANIZATION_ALREADY_EXISTS", Title: "Organization already exists",
+		HTTPStatus: http.StatusConflict, GRPCCode: codes.AlreadyExists,
+	}},
+	{domain.ErrInvalidInvitationToken, Entry{
+		Code: "INVALID_INVITATION_TOKEN", Title: "Invalid invitation token",
+		HTTPStatus: http.StatusBadRequest, GRPCCode: codes.InvalidArgument,
+	}},
+	{domain.ErrInvitationNotFound, Entry{
+		Code: "INVITATION_NOT_FOUND", Title: "Invitation not found",
+		HTTPStatus: http.StatusNotFound, GRPCCode: codes.NotFound,
+	}},
+	{domain.ErrServiceBusy, Entry{
+		Code: "SERVICE_BUSY", Title: "Service busy",
+		HTTPStatus: http.StatusTooManyRequests, GRPCCode: codes.ResourceExhausted,
+	}},
+	{domain.ErrForbidden, Entry{
+		Code: "PERMISSION_DENIED", Title: "Permission denied",
+		HTTPStatus: http.StatusForbidden, GRPCCode: codes.PermissionDenied,
+	}},
+	{domain.ErrInvalidRefreshToken, Entry{
+		Code: "INVALID_REFRESH_TOKEN", Title: "Invalid refresh token",
+		HTTPStatus: http.StatusUnauthorized, GRPCCode: codes.Unauthenticated,
+	}},
+	{domain.ErrRefreshTokenReused, Entry{
+		Code: "REFRESH_TOKEN_REUSED", Title: "Refresh token reused",
+		HTTPStatus: http.StatusUnauthorized, GRPCCode: codes.Unauthenticated,
+	}},
+	{domain.ErrInvalidPasswordResetToken, Entry{
+		Code: "INVALID_PASSWORD_RESET_TOKEN", Title: "Invalid password reset token",
+		HTTPStatus: http.StatusBadRequest, GRPCCode: codes.InvalidArgument,
+	}},
+	{domain.ErrInvalidEmailVerificationToken, Entry{
+		Code: "INVALID_EMAIL_VERIFICATION_TOKEN", Title: "Invalid email verification token",
+		HTTPStatus: http.StatusBadRequest, GRPCCode: codes.InvalidArgument,
+	}},
+	{domain.ErrInvalidMagicLinkToken, Entry{
+		Code: "INVALID_MAGIC_LINK_TOKEN", Title: "Invalid magic link token",
+		HTTPStatus: http.StatusUnauthorized, GRPCCode: codes.Unauthenticated,
+	}},
+	{domain.ErrInvalidPageToken, Entry{
+		Code: "INVALID_PAGE_TOKEN", Title: "Invalid page token",
+		HTTPStatus: http.StatusBadRequest, GRPCCode: codes.InvalidArgument,
+	}},
+	{context.Canceled, Entry{
+		Code: "CANCELED", Title: "Request canceled",
+		HTTPStatus: StatusClientClosedRequest, GRPCCode: codes.Canceled,
+	}},
+	{context.DeadlineExceeded, Entry{
+		Code: "DEADLINE_EXCEEDED", Title: "Deadline exceeded",
+		HTTPStatus: http.StatusGatewayTimeout, GRPCCode: codes.DeadlineExceeded,
+	}},
+}
+
+var (
+	internal = Entry{
+		Code: CodeInternal, Title: "Internal server error", Message: "internal error",
+		HTTPStatus: http.StatusInternalServerError, GRPCCode: codes.Internal,
+	}
+	validationFailed = Entry{
+		Code: CodeValidationFailed, Title: "Validation failed", Message: "validation failed",
+		HTTPStatus: http.StatusBadRequest, GRPCCode: codes.InvalidArgument,
+	}
+)
+
+// Lookup returns the entry registered for err. Unknown errors resolve to an
+// internal error whose message does not leak err, and ok is false so that the
+// caller can log the original.
+func Lookup(err error) (entry Entry, ok bool) {
+	var validationErr *domain.ValidationError
+	if errors.As(err, &validationErr) {
+		entry = validationFailed
+		entry.Message = validationErr.Error()
+		return entry, true
+	}
+	for _, r := range registry {
+		if errors.Is(err, r.err) {
+			entry = r.entry
+			if entry.Message == "" {
+				entry.Message = r.err.Error()
+			}
+			return entry, true
+		}
+	}
+	return internal, false
+}
+
+// Violations returns the field violations carried by err, if any.
+func Violations(err error) []domain.FieldViolation {
+	var validationErr *domain.ValidationError
+	if errors.As(err, &validationErr) {
+		return validationErr.Violations
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package interceptors
+
+import (
+	"context"
+	"strings"
+
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const metadataAuthorization = "authorization"
+
+// AccessTokenVerifier validates a bearer token and returns its principal.
+type AccessTokenVerifier interface {
+	VerifyAccessToken(ctx context.Context, token string) (*domain.Principal, error)
+}
+
+// Auth is a unary interceptor that requires a valid bearer token in the "authorization"
+// metadata and stores the principal in the context. Methods listed in publicMethods
+// (full method names, e.g. "/user.UserService/Login") are let through unauthenticated.
+func Auth(verifier AccessTokenVerifier, publicMethods ...string) grpc.UnaryServerInterceptor {
+	public := make(map[string]struct{}, len(publicMethods))
+	for _, m := range publicMethods {
+		public[m] = struct{}{}
+	}
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if _, ok := public[info.FullMethod]; ok {
+			return handler(ctx, req)
+		}
+
+		token, ok := bearerToken(ctx)
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "missing bearer token")
+		}
+		principal, err := verifier.VerifyAccessToken(ctx, token)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "invalid access token")
+		}
+
+		return handler(domain.ContextWithPrincipal(ctx, principal), req)
+	}
+}
+
+// RequirePermissions is a unary interceptor, chained after Auth, that allows the methods in
+// permissions (full method name to permission) only to callers whose roles grant the permission.
+// Other methods are let through.
+func RequirePermissions(permissions map[string]string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		permission, ok := permissions[info.FullMethod]
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		principal, ok := domain.PrincipalFromContext(ctx)
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "missing bearer token")
+		}
+		if !principal.HasPermission(permission) {
+			return nil, status.Errorf(codes.PermissionDenied, "missing permission %s", permission)
+		}
+		return handler(ctx, req)
+	}
+}
+
+func bearerToken(ctx context.Context) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+	values := md.Get(metadataAuthorization)
+	if len(values) == 0 {
+		return "", false
+	}
+	scheme, token, found := strings.Cut(values[0], " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
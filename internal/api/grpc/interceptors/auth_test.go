@@ -0,0 +1,89 @@
+package interceptors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type stubVerifier map[string]*domain.Principal
+
+func (v stubVerifier) VerifyAccessToken(_ context.Context, token string) (*domain.Principal, error) {
+	if p, ok := v[token]; ok {
+		return p, nil
+	}
+	return nil, domain.ErrUnauthorized
+}
+
+func TestAuth(t *testing.T) {
+	interceptor := Auth(stubVerifier{"good": {UserID: 7}}, "/user.UserService/Login")
+
+	handler := func(ctx context.Context, _ any) (any, error) {
+		p, ok := domain.PrincipalFromContext(ctx)
+		if !ok {
+			return "anonymous", nil
+		}
+		return p.UserID, nil
+	}
+	withToken := func(token string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+	}
+
+	t.Run("ValidToken", func(t *testing.T) {
+		resp, err := interceptor(withToken("good"), nil, &grpc.UnaryServerInfo{FullMethod: "/user.UserService/GetUserByID"}, handler)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(7), resp)
+	})
+
+	t.Run("InvalidToken", func(t *testing.T) {
+		_, err := interceptor(withToken("bad"), nil, &grpc.UnaryServerInfo{FullMethod: "/user.UserService/GetUserByID"}, handler)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("MissingMetadata", func(t *testing.T) {
+		_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/user.UserService/GetUserByID"}, handler)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("PublicMethod", func(t *testing.T) {
+		resp, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/user.UserService/Login"}, handler)
+		assert.NoError(t, err)
+		assert.Equal(t, "anonymous", resp)
+	})
+}
+
+func TestRequirePermissions(t *testing.T) {
+	interceptor := RequirePermissions(map[string]string{"/user.UserService/ListUsers": domain.PermissionUsersRead})
+	handler := func(context.Context, any) (any, error) { return "ok", nil }
+	withPrincipal := func(p *domain.Principal) context.Context {
+		return domain.ContextWithPrincipal(context.Background(), p)
+	}
+	listUsers := &grpc.UnaryServerInfo{FullMethod: "/user.UserService/ListUsers"}
+
+	t.Run("Granted", func(t *testing.T) {
+		resp, err := interceptor(withPrincipal(&domain.Principal{UserID: 1, Permissions: []string{domain.PermissionUsersRead}}), nil, listUsers, handler)
+		assert.NoError(t, err)
+		assert.Equal(t, "ok", resp)
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		_, err := interceptor(withPrincipal(&domain.Principal{UserID: 1}), nil, listUsers, handler)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		_, err := interceptor(context.Background(), nil, listUsers, handler)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("UnlistedMethod", func(t *testing.T) {
+		_, err := interceptor(withPrincipal(&domain.Principal{UserID: 1}), nil, &grpc.UnaryServerInfo{FullMethod: "/user.UserService/GetUserByID"}, handler)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,59 @@
+package interceptors
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/kerim-dauren/user-service/internal/api/apierr"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// Errors is a unary interceptor that converts domain errors returned by handlers into
+// gRPC statuses with google.rpc.ErrorInfo and, for validation failures, google.rpc.BadRequest
+// details. Errors that already carry a status pass through unchanged. Unknown errors are
+// logged and reported as Internal without their message.
+func Errors(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
+		return nil, toStatus(logger, info.FullMethod, err).Err()
+	}
+}
+
+func toStatus(logger *slog.Logger, method string, err error) *status.Status {
+	entry, ok := apierr.Lookup(err)
+	if !ok {
+		logger.Error("unhandled grpc error", "method", method, "err", err)
+	}
+
+	st := status.New(entry.GRPCCode, entry.Message)
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{
+		Reason: entry.Code,
+		Domain: apierr.Domain,
+	}}
+	if violations := apierr.Violations(err); len(violations) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, v := range violations {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       v.Field,
+				Description: v.Description,
+			})
+		}
+		details = append(details, badRequest)
+	}
+
+	withDetails, detailsErr := st.WithDetails(details...)
+	if detailsErr != nil {
+		logger.Error("failed to attach grpc error details", "err", detailsErr)
+		return st
+	}
+	return withDetails
+}
@@ -0,0 +1,95 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestErrors(t *testing.T) {
+	interceptor := Errors(slog.Default())
+	info := &grpc.UnaryServerInfo{FullMethod: "/user.UserService/GetUserByID"}
+	call := func(err error) *status.Status {
+		_, got := interceptor(context.Background(), nil, info, func(context.Context, any) (any, error) {
+			return nil, err
+		})
+		st, ok := status.FromError(got)
+		require.True(t, ok, "expected a gRPC status, got %v", got)
+		return st
+	}
+	errorInfo := func(st *status.Status) *errdetails.ErrorInfo {
+		for _, d := range st.Details() {
+			if info, ok := d.(*errdetails.ErrorInfo); ok {
+				return info
+			}
+		}
+		t.Fatalf("ErrorInfo detail missing in %v", st)
+		return nil
+	}
+
+	tests := []struct {
+		name    string
+		err     error
+		code    codes.Code
+		reason  string
+		message string
+	}{
+		{"NotFound", domain.ErrUserNotFound, codes.NotFound, "USER_NOT_FOUND", "user not found"},
+		{"Wrapped", fmt.Errorf("lookup: %w", domain.ErrUserNotFound), codes.NotFound, "USER_NOT_FOUND", "user not found"},
+		{"AlreadyExists", domain.ErrUserMailAlreadyExists, codes.AlreadyExists, "EMAIL_ALREADY_EXISTS", "user mail already exists"},
+		{"Forbidden", domain.ErrForbidden, codes.PermissionDenied, "PERMISSION_DENIED", "forbidden"},
+		{"Internal", errors.New("pq: connection refused to 10.0.0.5"), codes.Internal, "INTERNAL", "internal error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := call(tt.err)
+			assert.Equal(t, tt.code, st.Code())
+			assert.Equal(t, tt.message, st.Message())
+			info := errorInfo(st)
+			assert.Equal(t, tt.reason, info.Reason)
+			assert.Equal(t, "user-service", info.Domain)
+		})
+	}
+
+	t.Run("ValidationError", func(t *testing.T) {
+		st := call(domain.NewValidationError(
+			domain.FieldViolation{Field: "email", Description: "must be a valid email address"},
+		))
+		assert.Equal(t, codes.InvalidArgument, st.Code())
+		assert.Equal(t, "VALIDATION_FAILED", errorInfo(st).Reason)
+
+		var badRequest *errdetails.BadRequest
+		for _, d := range st.Details() {
+			if br, ok := d.(*errdetails.BadRequest); ok {
+				badRequest = br
+			}
+		}
+		require.NotNil(t, badRequest)
+		require.Len(t, badRequest.FieldViolations, 1)
+		assert.Equal(t, "email", badRequest.FieldViolations[0].Field)
+	})
+
+	t.Run("StatusPassThrough", func(t *testing.T) {
+		st := call(status.Error(codes.Unauthenticated, "missing bearer token"))
+		assert.Equal(t, codes.Unauthenticated, st.Code())
+		assert.Equal(t, "missing bearer token", st.Message())
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		resp, err := interceptor(context.Background(), nil, info, func(context.Context, any) (any, error) {
+			return "ok", nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "ok", resp)
+	})
+}
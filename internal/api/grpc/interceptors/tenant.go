@@ -0,0 +1,32 @@
+package interceptors
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const metadataTenantID = "x-tenant-id"
+
+// Tenant is a unary interceptor that stores the organization named by the "x-tenant-id"
+// metadata in the context. Calls without it act in the default organization, and
+// authenticated calls always act in the organization of their access token.
+func Tenant() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get(metadataTenantID)
+		if len(values) == 0 {
+			return handler(ctx, req)
+		}
+		id, err := strconv.ParseInt(values[0], 10, 64)
+		if err != nil || id <= 0 {
+			return nil, status.Error(codes.InvalidArgument, "x-tenant-id must be a positive integer")
+		}
+		return handler(domain.ContextWithTenant(ctx, id), req)
+	}
+}
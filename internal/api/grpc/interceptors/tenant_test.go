@@ -0,0 +1,35 @@
+package interceptors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestTenant(t *testing.T) {
+	interceptor := Tenant()
+	info := &grpc.UnaryServerInfo{FullMethod: "/user.UserService/Login"}
+	handler := func(ctx context.Context, _ any) (any, error) {
+		return domain.TenantFromContext(ctx), nil
+	}
+	withTenant := func(tenant string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-tenant-id", tenant))
+	}
+
+	resp, err := interceptor(context.Background(), nil, info, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.DefaultOrganizationID, resp)
+
+	resp, err = interceptor(withTenant("3"), nil, info, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), resp)
+
+	_, err = interceptor(withTenant("acme"), nil, info, handler)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
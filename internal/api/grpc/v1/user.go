@@ -2,21 +2,87 @@ package v1
 
 import (
 	"context"
+	"net"
 
 	user "github.com/kerim-dauren/user-service/gen/proto"
 	"github.com/kerim-dauren/user-service/internal/domain"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// PublicMethods lists the RPCs that can be called without an access token.
+var PublicMethods = []string{
+	"/user.UserService/CreateUser",
+	"/user.UserService/Login",
+	"/user.UserService/VerifyMFA",
+	"/user.UserService/RefreshToken",
+	"/user.UserService/RevokeToken",
+	"/user.UserService/RequestPasswordReset",
+	"/user.UserService/ConfirmPasswordReset",
+	"/user.UserService/SendEmailVerification",
+	"/user.UserService/ConfirmEmail",
+	"/user.UserService/RequestMagicLink",
+	"/user.UserService/LoginWithMagicLink",
+}
+
+// MethodPermissions lists the RPCs that require a permission whatever their arguments. RPCs
+// that act on an account also let its owner through and check for themselves.
+var MethodPermissions = map[string]string{
+	"/user.UserService/ListUsers":     domain.PermissionUsersRead,
+	"/user.UserService/UnlockAccount": domain.PermissionUsersUnlock,
+	"/user.UserService/ListRoles":     domain.PermissionRolesRead,
+	"/user.UserService/ListUserRoles": domain.PermissionRolesRead,
+	"/user.UserService/AssignRole":    domain.PermissionRolesWrite,
+	"/user.UserService/RevokeRole":    domain.PermissionRolesWrite,
+}
+
+// Services are the domain services behind the gRPC API.
+type Services struct {
+	UserService              domain.UserService
+	AuthService              domain.AuthService
+	SessionService           domain.SessionService
+	PasswordResetService     domain.PasswordResetService
+	EmailVerificationService domain.EmailVerificationService
+	MFAService               domain.MFAService
+	MagicLinkService         domain.MagicLinkService
+	LockoutService           domain.LockoutService
+	RoleService              domain.RoleService
+}
+
 type grpcUserService struct {
 	user.UnimplementedUserServiceServer
-	userService domain.UserService
+	userService              domain.UserService
+	authService              domain.AuthService
+	sessionService           domain.SessionService
+	passwordResetService     domain.PasswordResetService
+	emailVerificationService domain.EmailVerificationService
+	mfaService               domain.MFAService
+	magicLinkService         domain.MagicLinkService
+	lockoutService           domain.LockoutService
+	roleService              domain.RoleService
 }
 
-func NewUserService(userService domain.UserService) user.UserServiceServer {
-	return &grpcUserService{userService: userService}
+func NewUserService(services Services) user.UserServiceServer {
+	return &grpcUserService{
+		userService:              services.UserService,
+		authService:              services.AuthService,
+		sessionService:           services.SessionService,
+		passwordResetService:     services.PasswordResetService,
+		emailVerificationService: services.EmailVerificationService,
+		mfaService:               services.MFAService,
+		magicLinkService:         services.MagicLinkService,
+		lockoutService:           services.LockoutService,
+		roleService:              services.RoleService,
+	}
 }
 
 func (s *grpcUserService) CreateUser(ctx context.Context, req *user.CreateUserRequest) (*user.CreateUserResponse, error) {
+	if err := requireUser(req.User); err != nil {
+		return nil, err
+	}
+
 	id, err := s.userService.CreateUser(ctx, &domain.User{
 		Username: req.User.Username,
 		Email:    req.User.Email,
@@ -25,6 +91,8 @@ func (s *grpcUserService) CreateUser(ctx context.Context, req *user.CreateUserRe
 	if err != nil {
 		return nil, err
 	}
+	// The account exists either way; failures are logged and a new link can be requested.
+	_ = s.emailVerificationService.SendEmailVerification(ctx, req.User.Email)
 
 	return &user.CreateUserResponse{
 		Id: id,
@@ -32,21 +100,72 @@ func (s *grpcUserService) CreateUser(ctx context.Context, req *user.CreateUserRe
 }
 
 func (s *grpcUserService) GetUserByID(ctx context.Context, req *user.GetUserByIDRequest) (*user.GetUserByIDResponse, error) {
+	if err := authorizeOwnerOr(ctx, req.Id, domain.PermissionUsersRead); err != nil {
+		return nil, err
+	}
+
 	foundUser, err := s.userService.GetUserByID(ctx, req.Id)
 	if err != nil {
 		return nil, err
 	}
 
 	return &user.GetUserByIDResponse{
-		User: &user.UserResponse{
-			Id:       foundUser.ID,
-			Username: foundUser.Username,
-			Email:    foundUser.Email,
-		},
+		User: toUserResponse(foundUser),
 	}, nil
 }
 
+func (s *grpcUserService) ListUsers(ctx context.Context, req *user.ListUsersRequest) (*user.ListUsersResponse, error) {
+	params := domain.ListUsersParams{
+		Filter: domain.UserFilter{
+			EmailPrefix:      req.EmailPrefix,
+			UsernameContains: req.UsernameContains,
+		},
+		SortBy:    req.SortBy,
+		SortOrder: req.SortOrder,
+		PageSize:  int(req.PageSize),
+		PageToken: req.PageToken,
+	}
+	if req.CreatedAfter != nil {
+		params.Filter.CreatedAfter = req.CreatedAfter.AsTime()
+	}
+	if req.CreatedBefore != nil {
+		params.Filter.CreatedBefore = req.CreatedBefore.AsTime()
+	}
+
+	page, err := s.userService.ListUsers(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &user.ListUsersResponse{
+		Users:         make([]*user.UserResponse, 0, len(page.Users)),
+		NextPageToken: page.NextPageToken,
+	}
+	for _, u := range page.Users {
+		resp.Users = append(resp.Users, toUserResponse(u))
+	}
+	return resp, nil
+}
+
 func (s *grpcUserService) UpdateUser(ctx context.Context, req *user.UpdateUserRequest) (*user.UpdateUserResponse, error) {
+	if err := requireUser(req.User); err != nil {
+		return nil, err
+	}
+	if err := authorizeOwnerOr(ctx, req.User.Id, domain.PermissionUsersWrite); err != nil {
+		return nil, err
+	}
+
+	if req.UpdateMask != nil {
+		patch, err := userPatch(req.User, req.UpdateMask)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := s.userService.PatchUser(ctx, req.User.Id, patch); err != nil {
+			return nil, err
+		}
+		return &user.UpdateUserResponse{}, nil
+	}
+
 	err := s.userService.UpdateUser(ctx, &domain.User{
 		ID:       req.User.Id,
 		Username: req.User.Username,
@@ -60,7 +179,23 @@ func (s *grpcUserService) UpdateUser(ctx context.Context, req *user.UpdateUserRe
 	return &user.UpdateUserResponse{}, nil
 }
 
+func (s *grpcUserService) ChangePassword(ctx context.Context, req *user.ChangePasswordRequest) (*user.ChangePasswordResponse, error) {
+	if err := authorizeOwner(ctx, req.Id); err != nil {
+		return nil, err
+	}
+
+	if err := s.userService.ChangePassword(ctx, req.Id, req.CurrentPassword, req.NewPassword); err != nil {
+		return nil, err
+	}
+
+	return &user.ChangePasswordResponse{}, nil
+}
+
 func (s *grpcUserService) DeleteUser(ctx context.Context, req *user.DeleteUserRequest) (*user.DeleteUserResponse, error) {
+	if err := authorizeOwnerOr(ctx, req.Id, domain.PermissionUsersDelete); err != nil {
+		return nil, err
+	}
+
 	err := s.userService.DeleteUser(ctx, req.Id)
 	if err != nil {
 		return nil, err
@@ -68,3 +203,350 @@ func (s *grpcUserService) DeleteUser(ctx context.Context, req *user.DeleteUserRe
 
 	return &user.DeleteUserResponse{}, nil
 }
+
+func (s *grpcUserService) Login(ctx context.Context, req *user.LoginRequest) (*user.LoginResponse, error) {
+	result, err := s.authService.Login(ctx, req.Login, req.Password, clientInfo(ctx, req.Device))
+	if err != nil {
+		return nil, err
+	}
+
+	if challenge := result.MFAChallenge; challenge != nil {
+		return &user.LoginResponse{
+			MfaRequired:       true,
+			MfaToken:          challenge.Token,
+			MfaTokenExpiresIn: challenge.ExpiresIn,
+		}, nil
+	}
+	return &user.LoginResponse{
+		AccessToken:  result.Tokens.AccessToken,
+		TokenType:    result.Tokens.TokenType,
+		ExpiresIn:    result.Tokens.ExpiresIn,
+		RefreshToken: result.Tokens.RefreshToken,
+	}, nil
+}
+
+func (s *grpcUserService) VerifyMFA(ctx context.Context, req *user.VerifyMFARequest) (*user.VerifyMFAResponse, error) {
+	tokens, err := s.authService.VerifyMFA(ctx, req.MfaToken, req.Code, clientInfo(ctx, req.Device))
+	if err != nil {
+		return nil, err
+	}
+
+	return &user.VerifyMFAResponse{
+		AccessToken:  tokens.AccessToken,
+		TokenType:    tokens.TokenType,
+		ExpiresIn:    tokens.ExpiresIn,
+		RefreshToken: tokens.RefreshToken,
+	}, nil
+}
+
+func (s *grpcUserService) RefreshToken(ctx context.Context, req *user.RefreshTokenRequest) (*user.RefreshTokenResponse, error) {
+	tokens, err := s.authService.Refresh(ctx, req.RefreshToken, clientInfo(ctx, ""))
+	if err != nil {
+		return nil, err
+	}
+
+	return &user.RefreshTokenResponse{
+		AccessToken:  tokens.AccessToken,
+		TokenType:    tokens.TokenType,
+		ExpiresIn:    tokens.ExpiresIn,
+		RefreshToken: tokens.RefreshToken,
+	}, nil
+}
+
+func (s *grpcUserService) RevokeToken(ctx context.Context, req *user.RevokeTokenRequest) (*user.RevokeTokenResponse, error) {
+	if err := s.authService.Logout(ctx, req.RefreshToken); err != nil {
+		return nil, err
+	}
+
+	return &user.RevokeTokenResponse{}, nil
+}
+
+func (s *grpcUserService) RequestPasswordReset(ctx context.Context, req *user.RequestPasswordResetRequest) (*user.RequestPasswordResetResponse, error) {
+	if err := s.passwordResetService.RequestPasswordReset(ctx, req.Email); err != nil {
+		return nil, err
+	}
+
+	return &user.RequestPasswordResetResponse{}, nil
+}
+
+func (s *grpcUserService) ConfirmPasswordReset(ctx context.Context, req *user.ConfirmPasswordResetRequest) (*user.ConfirmPasswordResetResponse, error) {
+	if err := s.passwordResetService.ConfirmPasswordReset(ctx, req.Token, req.NewPassword); err != nil {
+		return nil, err
+	}
+
+	return &user.ConfirmPasswordResetResponse{}, nil
+}
+
+func (s *grpcUserService) SendEmailVerification(ctx context.Context, req *user.SendEmailVerificationRequest) (*user.SendEmailVerificationResponse, error) {
+	if err := s.emailVerificationService.SendEmailVerification(ctx, req.Email); err != nil {
+		return nil, err
+	}
+
+	return &user.SendEmailVerificationResponse{}, nil
+}
+
+func (s *grpcUserService) ConfirmEmail(ctx context.Context, req *user.ConfirmEmailRequest) (*user.ConfirmEmailResponse, error) {
+	if err := s.emailVerificationService.ConfirmEmail(ctx, req.Token); err != nil {
+		return nil, err
+	}
+
+	return &user.ConfirmEmailResponse{}, nil
+}
+
+func (s *grpcUserService) RequestMagicLink(ctx context.Context, req *user.RequestMagicLinkRequest) (*user.RequestMagicLinkResponse, error) {
+	if err := s.magicLinkService.RequestMagicLink(ctx, req.Email); err != nil {
+		return nil, err
+	}
+
+	return &user.RequestMagicLinkResponse{}, nil
+}
+
+func (s *grpcUserService) LoginWithMagicLink(ctx context.Context, req *user.LoginWithMagicLinkRequest) (*user.LoginWithMagicLinkResponse, error) {
+	result, err := s.authService.LoginWithMagicLink(ctx, req.Token, clientInfo(ctx, req.Device))
+	if err != nil {
+		return nil, err
+	}
+
+	if challenge := result.MFAChallenge; challenge != nil {
+		return &user.LoginWithMagicLinkResponse{
+			MfaRequired:       true,
+			MfaToken:          challenge.Token,
+			MfaTokenExpiresIn: challenge.ExpiresIn,
+		}, nil
+	}
+	return &user.LoginWithMagicLinkResponse{
+		AccessToken:  result.Tokens.AccessToken,
+		TokenType:    result.Tokens.TokenType,
+		ExpiresIn:    result.Tokens.ExpiresIn,
+		RefreshToken: result.Tokens.RefreshToken,
+	}, nil
+}
+
+func (s *grpcUserService) EnrollTOTP(ctx context.Context, req *user.EnrollTOTPRequest) (*user.EnrollTOTPResponse, error) {
+	if err := authorizeOwner(ctx, req.UserId); err != nil {
+		return nil, err
+	}
+
+	enrollment, err := s.mfaService.EnrollTOTP(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user.EnrollTOTPResponse{
+		Secret:     enrollment.Secret,
+		OtpauthUri: enrollment.URI,
+		QrCodePng:  enrollment.QRCode,
+	}, nil
+}
+
+func (s *grpcUserService) ConfirmTOTP(ctx context.Context, req *user.ConfirmTOTPRequest) (*user.ConfirmTOTPResponse, error) {
+	if err := authorizeOwner(ctx, req.UserId); err != nil {
+		return nil, err
+	}
+
+	recoveryCodes, err := s.mfaService.ConfirmTOTP(ctx, req.UserId, req.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user.ConfirmTOTPResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+func (s *grpcUserService) DisableMFA(ctx context.Context, req *user.DisableMFARequest) (*user.DisableMFAResponse, error) {
+	if err := authorizeOwner(ctx, req.UserId); err != nil {
+		return nil, err
+	}
+
+	if err := s.mfaService.DisableMFA(ctx, req.UserId, req.Code); err != nil {
+		return nil, err
+	}
+
+	return &user.DisableMFAResponse{}, nil
+}
+
+func (s *grpcUserService) RegenerateRecoveryCodes(ctx context.Context, req *user.RegenerateRecoveryCodesRequest) (*user.RegenerateRecoveryCodesResponse, error) {
+	if err := authorizeOwner(ctx, req.UserId); err != nil {
+		return nil, err
+	}
+
+	recoveryCodes, err := s.mfaService.RegenerateRecoveryCodes(ctx, req.UserId, req.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user.RegenerateRecoveryCodesResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+func (s *grpcUserService) ListSessions(ctx context.Context, req *user.ListSessionsRequest) (*user.ListSessionsResponse, error) {
+	if err := authorizeOwner(ctx, req.UserId); err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.sessionService.ListSessions(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	var current string
+	if principal, ok := domain.PrincipalFromContext(ctx); ok {
+		current = principal.SessionID
+	}
+	resp := &user.ListSessionsResponse{Sessions: make([]*user.Session, 0, len(sessions))}
+	for _, session := range sessions {
+		resp.Sessions = append(resp.Sessions, &user.Session{
+			Id:         session.ID,
+			Device:     session.Device,
+			Ip:         session.IP,
+			UserAgent:  session.UserAgent,
+			CreatedAt:  timestamppb.New(session.CreatedAt),
+			LastSeenAt: timestamppb.New(session.LastSeenAt),
+			Current:    session.ID == current,
+		})
+	}
+	return resp, nil
+}
+
+func (s *grpcUserService) RevokeSession(ctx context.Context, req *user.RevokeSessionRequest) (*user.RevokeSessionResponse, error) {
+	if err := authorizeOwner(ctx, req.UserId); err != nil {
+		return nil, err
+	}
+
+	if err := s.sessionService.RevokeSession(ctx, req.UserId, req.SessionId); err != nil {
+		return nil, err
+	}
+
+	return &user.RevokeSessionResponse{}, nil
+}
+
+func (s *grpcUserService) UnlockAccount(ctx context.Context, req *user.UnlockAccountRequest) (*user.UnlockAccountResponse, error) {
+	if err := s.lockoutService.UnlockAccount(ctx, req.UserId); err != nil {
+		return nil, err
+	}
+
+	return &user.UnlockAccountResponse{}, nil
+}
+
+func (s *grpcUserService) ListRoles(ctx context.Context, _ *user.ListRolesRequest) (*user.ListRolesResponse, error) {
+	roles, err := s.roleService.ListRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user.ListRolesResponse{Roles: toRoles(roles)}, nil
+}
+
+func (s *grpcUserService) ListUserRoles(ctx context.Context, req *user.ListUserRolesRequest) (*user.ListUserRolesResponse, error) {
+	roles, err := s.roleService.ListUserRoles(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user.ListUserRolesResponse{Roles: toRoles(roles)}, nil
+}
+
+func (s *grpcUserService) AssignRole(ctx context.Context, req *user.AssignRoleRequest) (*user.AssignRoleResponse, error) {
+	if err := s.roleService.AssignRole(ctx, req.UserId, req.Role); err != nil {
+		return nil, err
+	}
+
+	return &user.AssignRoleResponse{}, nil
+}
+
+func (s *grpcUserService) RevokeRole(ctx context.Context, req *user.RevokeRoleRequest) (*user.RevokeRoleResponse, error) {
+	if err := s.roleService.RevokeRole(ctx, req.UserId, req.Role); err != nil {
+		return nil, err
+	}
+
+	return &user.RevokeRoleResponse{}, nil
+}
+
+func toRoles(roles []*domain.Role) []*user.Role {
+	resp := make([]*user.Role, 0, len(roles))
+	for _, r := range roles {
+		resp = append(resp, &user.Role{Name: r.Name, Description: r.Description, Permissions: r.Permissions})
+	}
+	return resp
+}
+
+func toUserResponse(u *domain.UserResponse) *user.UserResponse {
+	resp := &user.UserResponse{
+		Id:               u.ID,
+		Username:         u.Username,
+		Email:            u.Email,
+		OrganizationId:   u.OrganizationID,
+		OrganizationRole: u.OrganizationRole,
+	}
+	if !u.CreatedAt.IsZero() {
+		resp.CreatedAt = timestamppb.New(u.CreatedAt)
+	}
+	if u.EmailVerifiedAt != nil {
+		resp.EmailVerifiedAt = timestamppb.New(*u.EmailVerifiedAt)
+	}
+	return resp
+}
+
+// userPatch picks the fields named by mask from u.
+func userPatch(u *user.User, mask *fieldmaskpb.FieldMask) (*domain.UserPatch, error) {
+	mask.Normalize()
+
+	patch := &domain.UserPatch{}
+	var violations []domain.FieldViolation
+	for _, path := range mask.GetPaths() {
+		switch path {
+		case "username":
+			patch.Username = &u.Username
+		case "email":
+			patch.Email = &u.Email
+		case "password":
+			violations = append(violations, domain.FieldViolation{Field: "update_mask", Description: "password must be changed with ChangePassword"})
+		default:
+			violations = append(violations, domain.FieldViolation{Field: "update_mask", Description: "unknown or immutable field: " + path})
+		}
+	}
+	if len(violations) > 0 {
+		return nil, domain.NewValidationError(violations...)
+	}
+	return patch, nil
+}
+
+// authorizeOwner restricts the call to the user the resource belongs to.
+func authorizeOwner(ctx context.Context, id int64) error {
+	return authorizeOwnerOr(ctx, id, "")
+}
+
+// authorizeOwnerOr is authorizeOwner that also lets through users whose roles grant permission.
+func authorizeOwnerOr(ctx context.Context, id int64, permission string) error {
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok {
+		return domain.ErrUnauthorized
+	}
+	if principal.UserID != id && (permission == "" || !principal.HasPermission(permission)) {
+		return domain.ErrForbidden
+	}
+	return nil
+}
+
+// requireUser rejects requests without the mandatory user message.
+func requireUser(u *user.User) error {
+	if u == nil {
+		return domain.NewValidationError(domain.FieldViolation{Field: "user", Description: "is required"})
+	}
+	return nil
+}
+
+// clientInfo collects the session metadata of the calling client.
+func clientInfo(ctx context.Context, device string) domain.ClientInfo {
+	info := domain.ClientInfo{Device: device}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		info.IP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(info.IP); err == nil {
+			info.IP = host
+		}
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ua := md.Get("user-agent"); len(ua) > 0 {
+			info.UserAgent = ua[0]
+		}
+	}
+	return info
+}
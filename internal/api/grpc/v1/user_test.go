@@ -4,11 +4,14 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	user "github.com/kerim-dauren/user-service/gen/proto"
 	"github.com/kerim-dauren/user-service/internal/domain"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func TestCreateUser(t *testing.T) {
@@ -16,7 +19,8 @@ func TestCreateUser(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockUserService := domain.NewMockUserService(ctrl)
-	grpcService := NewUserService(mockUserService)
+	mockEmailVerificationService := domain.NewMockEmailVerificationService(ctrl)
+	grpcService := NewUserService(Services{UserService: mockUserService, EmailVerificationService: mockEmailVerificationService})
 
 	req := &user.CreateUserRequest{
 		User: &user.User{
@@ -31,6 +35,7 @@ func TestCreateUser(t *testing.T) {
 		Email:    "test@example.com",
 		Password: "password123",
 	}).Return(int64(1), nil)
+	mockEmailVerificationService.EXPECT().SendEmailVerification(gomock.Any(), "test@example.com").Return(errors.New("smtp down"))
 
 	resp, err := grpcService.CreateUser(context.Background(), req)
 	assert.NoError(t, err)
@@ -42,7 +47,7 @@ func TestGetUserByID(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockUserService := domain.NewMockUserService(ctrl)
-	grpcService := NewUserService(mockUserService)
+	grpcService := NewUserService(Services{UserService: mockUserService})
 
 	req := &user.GetUserByIDRequest{Id: 1}
 
@@ -52,7 +57,8 @@ func TestGetUserByID(t *testing.T) {
 		Email:    "test@example.com",
 	}, nil)
 
-	resp, err := grpcService.GetUserByID(context.Background(), req)
+	ctx := domain.ContextWithPrincipal(context.Background(), &domain.Principal{UserID: 2, Permissions: []string{domain.PermissionUsersRead}})
+	resp, err := grpcService.GetUserByID(ctx, req)
 	assert.NoError(t, err)
 	assert.Equal(t, int64(1), resp.User.Id)
 	assert.Equal(t, "testuser", resp.User.Username)
@@ -64,7 +70,7 @@ func TestUpdateUser(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockUserService := domain.NewMockUserService(ctrl)
-	grpcService := NewUserService(mockUserService)
+	grpcService := NewUserService(Services{UserService: mockUserService})
 
 	req := &user.UpdateUserRequest{
 		User: &user.User{
@@ -82,7 +88,8 @@ func TestUpdateUser(t *testing.T) {
 		Password: "newpassword123",
 	}).Return(nil)
 
-	resp, err := grpcService.UpdateUser(context.Background(), req)
+	ctx := domain.ContextWithPrincipal(context.Background(), &domain.Principal{UserID: 1})
+	resp, err := grpcService.UpdateUser(ctx, req)
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 }
@@ -92,13 +99,14 @@ func TestDeleteUser(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockUserService := domain.NewMockUserService(ctrl)
-	grpcService := NewUserService(mockUserService)
+	grpcService := NewUserService(Services{UserService: mockUserService})
 
 	req := &user.DeleteUserRequest{Id: 1}
 
 	mockUserService.EXPECT().DeleteUser(gomock.Any(), int64(1)).Return(nil)
 
-	resp, err := grpcService.DeleteUser(context.Background(), req)
+	ctx := domain.ContextWithPrincipal(context.Background(), &domain.Principal{UserID: 1})
+	resp, err := grpcService.DeleteUser(ctx, req)
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 }
@@ -108,7 +116,7 @@ func TestCreateUser_Error(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockUserService := domain.NewMockUserService(ctrl)
-	grpcService := NewUserService(mockUserService)
+	grpcService := NewUserService(Services{UserService: mockUserService})
 
 	req := &user.CreateUserRequest{
 		User: &user.User{
@@ -124,3 +132,393 @@ func TestCreateUser_Error(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, resp)
 }
+
+func TestDeleteUser_NotOwner(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := domain.NewMockUserService(ctrl)
+	grpcService := NewUserService(Services{UserService: mockUserService})
+
+	ctx := domain.ContextWithPrincipal(context.Background(), &domain.Principal{UserID: 2})
+	resp, err := grpcService.DeleteUser(ctx, &user.DeleteUserRequest{Id: 1})
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	assert.Nil(t, resp)
+}
+
+func TestLogin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := domain.NewMockAuthService(ctrl)
+	grpcService := NewUserService(Services{AuthService: mockAuthService})
+
+	req := &user.LoginRequest{Login: "testuser", Password: "password123"}
+
+	mockAuthService.EXPECT().Login(gomock.Any(), "testuser", "password123", gomock.Any()).Return(&domain.LoginResult{
+		Tokens: &domain.AuthTokens{
+			AccessToken: "token",
+			TokenType:   "Bearer",
+			ExpiresIn:   900,
+		},
+	}, nil)
+
+	resp, err := grpcService.Login(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, "token", resp.AccessToken)
+	assert.Equal(t, "Bearer", resp.TokenType)
+	assert.Equal(t, int64(900), resp.ExpiresIn)
+	assert.False(t, resp.MfaRequired)
+}
+
+func TestLogin_MFARequired(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := domain.NewMockAuthService(ctrl)
+	grpcService := NewUserService(Services{AuthService: mockAuthService})
+
+	mockAuthService.EXPECT().Login(gomock.Any(), "testuser", "password123", gomock.Any()).Return(&domain.LoginResult{
+		MFAChallenge: &domain.MFAChallenge{Token: "mfa-token", ExpiresIn: 300},
+	}, nil)
+
+	resp, err := grpcService.Login(context.Background(), &user.LoginRequest{Login: "testuser", Password: "password123"})
+	assert.NoError(t, err)
+	assert.True(t, resp.MfaRequired)
+	assert.Equal(t, "mfa-token", resp.MfaToken)
+	assert.Equal(t, int64(300), resp.MfaTokenExpiresIn)
+	assert.Empty(t, resp.AccessToken)
+}
+
+func TestVerifyMFA(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := domain.NewMockAuthService(ctrl)
+	grpcService := NewUserService(Services{AuthService: mockAuthService})
+
+	mockAuthService.EXPECT().VerifyMFA(gomock.Any(), "mfa-token", "123456", gomock.Any()).Return(&domain.AuthTokens{
+		AccessToken: "token",
+		TokenType:   "Bearer",
+	}, nil)
+
+	resp, err := grpcService.VerifyMFA(context.Background(), &user.VerifyMFARequest{MfaToken: "mfa-token", Code: "123456"})
+	assert.NoError(t, err)
+	assert.Equal(t, "token", resp.AccessToken)
+}
+
+func TestLoginWithMagicLink(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := domain.NewMockAuthService(ctrl)
+	grpcService := NewUserService(Services{AuthService: mockAuthService})
+
+	mockAuthService.EXPECT().LoginWithMagicLink(gomock.Any(), "link-token", gomock.Any()).Return(&domain.LoginResult{
+		Tokens: &domain.AuthTokens{AccessToken: "token", TokenType: "Bearer"},
+	}, nil)
+
+	resp, err := grpcService.LoginWithMagicLink(context.Background(), &user.LoginWithMagicLinkRequest{Token: "link-token"})
+	assert.NoError(t, err)
+	assert.Equal(t, "token", resp.AccessToken)
+	assert.False(t, resp.MfaRequired)
+}
+
+func TestRefreshToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := domain.NewMockAuthService(ctrl)
+	grpcService := NewUserService(Services{AuthService: mockAuthService})
+
+	mockAuthService.EXPECT().Refresh(gomock.Any(), "old", gomock.Any()).Return(&domain.AuthTokens{
+		AccessToken:  "access",
+		TokenType:    "Bearer",
+		ExpiresIn:    900,
+		RefreshToken: "new",
+	}, nil)
+
+	resp, err := grpcService.RefreshToken(context.Background(), &user.RefreshTokenRequest{RefreshToken: "old"})
+	assert.NoError(t, err)
+	assert.Equal(t, "access", resp.AccessToken)
+	assert.Equal(t, "new", resp.RefreshToken)
+}
+
+func TestRevokeToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := domain.NewMockAuthService(ctrl)
+	grpcService := NewUserService(Services{AuthService: mockAuthService})
+
+	mockAuthService.EXPECT().Logout(gomock.Any(), "token").Return(nil)
+
+	resp, err := grpcService.RevokeToken(context.Background(), &user.RevokeTokenRequest{RefreshToken: "token"})
+	assert.NoError(t, err)
+	assert.NotNil(t, resp)
+}
+
+func TestListSessions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSessionService := domain.NewMockSessionService(ctrl)
+	grpcService := NewUserService(Services{SessionService: mockSessionService})
+
+	mockSessionService.EXPECT().ListSessions(gomock.Any(), int64(1)).Return([]*domain.Session{
+		{ID: "s1", UserID: 1, Device: "laptop"},
+		{ID: "s2", UserID: 1, Device: "phone"},
+	}, nil)
+
+	ctx := domain.ContextWithPrincipal(context.Background(), &domain.Principal{UserID: 1, SessionID: "s2"})
+	resp, err := grpcService.ListSessions(ctx, &user.ListSessionsRequest{UserId: 1})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Sessions, 2)
+	assert.False(t, resp.Sessions[0].Current)
+	assert.True(t, resp.Sessions[1].Current)
+	assert.Equal(t, "phone", resp.Sessions[1].Device)
+}
+
+func TestRevokeSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSessionService := domain.NewMockSessionService(ctrl)
+	grpcService := NewUserService(Services{SessionService: mockSessionService})
+
+	mockSessionService.EXPECT().RevokeSession(gomock.Any(), int64(1), "s1").Return(nil)
+
+	ctx := domain.ContextWithPrincipal(context.Background(), &domain.Principal{UserID: 1})
+	resp, err := grpcService.RevokeSession(ctx, &user.RevokeSessionRequest{UserId: 1, SessionId: "s1"})
+	assert.NoError(t, err)
+	assert.NotNil(t, resp)
+
+	other := domain.ContextWithPrincipal(context.Background(), &domain.Principal{UserID: 2})
+	_, err = grpcService.RevokeSession(other, &user.RevokeSessionRequest{UserId: 1, SessionId: "s1"})
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+}
+
+func TestCreateUser_MissingUser(t *testing.T) {
+	grpcService := NewUserService(Services{})
+
+	resp, err := grpcService.CreateUser(context.Background(), &user.CreateUserRequest{})
+	var validationErr *domain.ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "user", validationErr.Violations[0].Field)
+	assert.Nil(t, resp)
+}
+
+func TestListUsers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := domain.NewMockUserService(ctrl)
+	grpcService := NewUserService(Services{UserService: mockUserService})
+	after := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mockUserService.EXPECT().ListUsers(gomock.Any(), domain.ListUsersParams{
+		Filter:    domain.UserFilter{EmailPrefix: "a", CreatedAfter: after},
+		SortBy:    domain.UserSortByUsername,
+		SortOrder: domain.SortOrderDesc,
+		PageSize:  10,
+		PageToken: "token",
+	}).Return(&domain.UserPage{
+		Users:         []*domain.UserResponse{{ID: 1, Username: "alice", CreatedAt: after}},
+		NextPageToken: "next",
+	}, nil)
+
+	resp, err := grpcService.ListUsers(context.Background(), &user.ListUsersRequest{
+		PageSize:     10,
+		PageToken:    "token",
+		EmailPrefix:  "a",
+		CreatedAfter: timestamppb.New(after),
+		SortBy:       domain.UserSortByUsername,
+		SortOrder:    domain.SortOrderDesc,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Users, 1)
+	assert.Equal(t, "alice", resp.Users[0].Username)
+	assert.Equal(t, after, resp.Users[0].CreatedAt.AsTime())
+	assert.Equal(t, "next", resp.NextPageToken)
+}
+
+func TestUpdateUser_WithMask(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := domain.NewMockUserService(ctrl)
+	grpcService := NewUserService(Services{UserService: mockUserService})
+	email := "updated@example.com"
+
+	mockUserService.EXPECT().PatchUser(gomock.Any(), int64(1), &domain.UserPatch{Email: &email}).
+		Return(&domain.UserResponse{ID: 1, Email: email}, nil)
+
+	ctx := domain.ContextWithPrincipal(context.Background(), &domain.Principal{UserID: 1})
+	resp, err := grpcService.UpdateUser(ctx, &user.UpdateUserRequest{
+		User:       &user.User{Id: 1, Username: "ignored", Email: email, Password: "ignored"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"email"}},
+	})
+	assert.NoError(t, err)
+	assert.NotNil(t, resp)
+}
+
+func TestUpdateUser_MaskWithPassword(t *testing.T) {
+	grpcService := NewUserService(Services{})
+
+	ctx := domain.ContextWithPrincipal(context.Background(), &domain.Principal{UserID: 1})
+	_, err := grpcService.UpdateUser(ctx, &user.UpdateUserRequest{
+		User:       &user.User{Id: 1, Password: "newpassword"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"password", "id"}},
+	})
+
+	var validationErr *domain.ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Len(t, validationErr.Violations, 2)
+}
+
+func TestChangePassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := domain.NewMockUserService(ctrl)
+	grpcService := NewUserService(Services{UserService: mockUserService})
+	req := &user.ChangePasswordRequest{Id: 1, CurrentPassword: "oldpassword", NewPassword: "newpassword"}
+
+	mockUserService.EXPECT().ChangePassword(gomock.Any(), int64(1), "oldpassword", "newpassword").Return(nil)
+
+	ctx := domain.ContextWithPrincipal(context.Background(), &domain.Principal{UserID: 1})
+	resp, err := grpcService.ChangePassword(ctx, req)
+	assert.NoError(t, err)
+	assert.NotNil(t, resp)
+
+	other := domain.ContextWithPrincipal(context.Background(), &domain.Principal{UserID: 2})
+	_, err = grpcService.ChangePassword(other, req)
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+}
+
+func TestRequestPasswordReset(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPasswordResetService := domain.NewMockPasswordResetService(ctrl)
+	grpcService := NewUserService(Services{PasswordResetService: mockPasswordResetService})
+
+	mockPasswordResetService.EXPECT().RequestPasswordReset(gomock.Any(), "test@example.com").Return(nil)
+
+	resp, err := grpcService.RequestPasswordReset(context.Background(), &user.RequestPasswordResetRequest{Email: "test@example.com"})
+	assert.NoError(t, err)
+	assert.NotNil(t, resp)
+}
+
+func TestConfirmPasswordReset(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPasswordResetService := domain.NewMockPasswordResetService(ctrl)
+	grpcService := NewUserService(Services{PasswordResetService: mockPasswordResetService})
+
+	mockPasswordResetService.EXPECT().ConfirmPasswordReset(gomock.Any(), "token", "newpassword").Return(domain.ErrInvalidPasswordResetToken)
+
+	resp, err := grpcService.ConfirmPasswordReset(context.Background(), &user.ConfirmPasswordResetRequest{Token: "token", NewPassword: "newpassword"})
+	assert.ErrorIs(t, err, domain.ErrInvalidPasswordResetToken)
+	assert.Nil(t, resp)
+}
+
+func TestSendEmailVerification(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEmailVerificationService := domain.NewMockEmailVerificationService(ctrl)
+	grpcService := NewUserService(Services{EmailVerificationService: mockEmailVerificationService})
+
+	mockEmailVerificationService.EXPECT().SendEmailVerification(gomock.Any(), "test@example.com").Return(nil)
+
+	resp, err := grpcService.SendEmailVerification(context.Background(), &user.SendEmailVerificationRequest{Email: "test@example.com"})
+	assert.NoError(t, err)
+	assert.NotNil(t, resp)
+}
+
+func TestConfirmEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEmailVerificationService := domain.NewMockEmailVerificationService(ctrl)
+	grpcService := NewUserService(Services{EmailVerificationService: mockEmailVerificationService})
+
+	mockEmailVerificationService.EXPECT().ConfirmEmail(gomock.Any(), "token").Return(domain.ErrInvalidEmailVerificationToken)
+
+	resp, err := grpcService.ConfirmEmail(context.Background(), &user.ConfirmEmailRequest{Token: "token"})
+	assert.ErrorIs(t, err, domain.ErrInvalidEmailVerificationToken)
+	assert.Nil(t, resp)
+}
+
+func TestConfirmTOTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMFAService := domain.NewMockMFAService(ctrl)
+	grpcService := NewUserService(Services{MFAService: mockMFAService})
+	ctx := domain.ContextWithPrincipal(context.Background(), &domain.Principal{UserID: 1})
+
+	mockMFAService.EXPECT().ConfirmTOTP(ctx, int64(1), "123456").Return([]string{"aaaa-bbbb-cccc-dddd"}, nil)
+
+	resp, err := grpcService.ConfirmTOTP(ctx, &user.ConfirmTOTPRequest{UserId: 1, Code: "123456"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"aaaa-bbbb-cccc-dddd"}, resp.RecoveryCodes)
+}
+
+func TestDisableMFA_NotOwner(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	grpcService := NewUserService(Services{MFAService: domain.NewMockMFAService(ctrl)})
+	ctx := domain.ContextWithPrincipal(context.Background(), &domain.Principal{UserID: 2})
+
+	resp, err := grpcService.DisableMFA(ctx, &user.DisableMFARequest{UserId: 1, Code: "123456"})
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	assert.Nil(t, resp)
+}
+
+func TestUnlockAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLockoutService := domain.NewMockLockoutService(ctrl)
+	grpcService := NewUserService(Services{LockoutService: mockLockoutService})
+
+	mockLockoutService.EXPECT().UnlockAccount(gomock.Any(), int64(2)).Return(nil)
+	ctx := domain.ContextWithPrincipal(context.Background(), &domain.Principal{UserID: 1, Permissions: []string{domain.PermissionUsersUnlock}})
+	resp, err := grpcService.UnlockAccount(ctx, &user.UnlockAccountRequest{UserId: 2})
+	assert.NoError(t, err)
+	assert.NotNil(t, resp)
+}
+
+func TestGetUserByID_NotOwner(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	grpcService := NewUserService(Services{UserService: domain.NewMockUserService(ctrl)})
+
+	ctx := domain.ContextWithPrincipal(context.Background(), &domain.Principal{UserID: 2})
+	resp, err := grpcService.GetUserByID(ctx, &user.GetUserByIDRequest{Id: 1})
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	assert.Nil(t, resp)
+}
+
+func TestAssignRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRoleService := domain.NewMockRoleService(ctrl)
+	grpcService := NewUserService(Services{RoleService: mockRoleService})
+
+	mockRoleService.EXPECT().AssignRole(gomock.Any(), int64(2), domain.RoleAdmin).Return(nil)
+	mockRoleService.EXPECT().AssignRole(gomock.Any(), int64(2), "unknown").Return(domain.ErrRoleNotFound)
+
+	resp, err := grpcService.AssignRole(context.Background(), &user.AssignRoleRequest{UserId: 2, Role: domain.RoleAdmin})
+	assert.NoError(t, err)
+	assert.NotNil(t, resp)
+
+	_, err = grpcService.AssignRole(context.Background(), &user.AssignRoleRequest{UserId: 2, Role: "unknown"})
+	assert.ErrorIs(t, err, domain.ErrRoleNotFound)
+}
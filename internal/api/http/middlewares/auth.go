@@ -0,0 +1,125 @@
+package middlewares
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kerim-dauren/user-service/internal/domain"
+)
+
+const HeaderAuthorization = "Authorization"
+
+// AccessTokenVerifier validates a bearer token and returns its principal.
+type AccessTokenVerifier interface {
+	VerifyAccessToken(ctx context.Context, token string) (*domain.Principal, error)
+}
+
+// Auth requires a valid bearer token and stores the principal in the request context.
+func Auth(verifier AccessTokenVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := bearerToken(c.GetHeader(HeaderAuthorization))
+		if !ok {
+			unauthorized(c)
+			return
+		}
+
+		principal, err := verifier.VerifyAccessToken(c.Request.Context(), token)
+		if err != nil {
+			unauthorized(c)
+			return
+		}
+
+		ctx := domain.ContextWithPrincipal(c.Request.Context(), principal)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// RequireOwner allows the request only when the path parameter matches the authenticated user ID.
+// It must run after Auth.
+func RequireOwner(param string) gin.HandlerFunc {
+	return RequireOwnerOr(param, "")
+}
+
+// RequireOwnerOr is RequireOwner that also lets through users whose roles grant permission,
+// to act on the accounts of others. It must run after Auth.
+func RequireOwnerOr(param, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := domain.PrincipalFromContext(c.Request.Context())
+		if !ok {
+			unauthorized(c)
+			return
+		}
+		id, err := strconv.ParseInt(c.Param(param), 10, 64)
+		if err != nil {
+			_ = c.Error(domain.NewValidationError(domain.FieldViolation{Field: param, Description: "must be an integer"}))
+			c.Abort()
+			return
+		}
+		if principal.UserID != id && (permission == "" || !principal.HasPermission(permission)) {
+			_ = c.Error(domain.ErrForbidden)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequirePermission allows the request only when a role of the authenticated user grants permission.
+// It must run after Auth.
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := domain.PrincipalFromContext(c.Request.Context())
+		if !ok {
+			unauthorized(c)
+			return
+		}
+		if !principal.HasPermission(permission) {
+			_ = c.Error(domain.ErrForbidden)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireOrganizationRole allows the request only when the authenticated user holds one of
+// roles in their organization. It must run after Auth.
+func RequireOrganizationRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := domain.PrincipalFromContext(c.Request.Context())
+		if !ok {
+			unauthorized(c)
+			return
+		}
+		if !slices.Contains(roles, principal.OrganizationRole) {
+			_ = c.Error(domain.ErrForbidden)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func bearerToken(header string) (string, bool) {
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// unauthorized aborts the request; the response is rendered by Errors.
+func unauthorized(c *gin.Context) {
+	c.Header("WWW-Authenticate", `Bearer realm="user-service"`)
+	_ = c.Error(domain.ErrUnauthorized)
+	c.Abort()
+}
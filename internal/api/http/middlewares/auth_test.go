@@ -0,0 +1,130 @@
+package middlewares
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kerim-dauren/user-service/internal/api/apierr"
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubVerifier map[string]*domain.Principal
+
+func (v stubVerifier) VerifyAccessToken(_ context.Context, token string) (*domain.Principal, error) {
+	if p, ok := v[token]; ok {
+		return p, nil
+	}
+	return nil, domain.ErrUnauthorized
+}
+
+func TestAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	verifier := stubVerifier{"good": {UserID: 1, Username: "alice"}}
+
+	newRouter := func() *gin.Engine {
+		router := gin.New()
+		router.Use(Errors(slog.New(slog.NewTextHandler(io.Discard, nil))))
+		router.GET("/users/:id", Auth(verifier), RequireOwner("id"), func(c *gin.Context) {
+			p, _ := domain.PrincipalFromContext(c.Request.Context())
+			c.String(http.StatusOK, p.Username)
+		})
+		return router
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		header string
+		status int
+	}{
+		{name: "Owner", path: "/users/1", header: "Bearer good", status: http.StatusOK},
+		{name: "LowercaseScheme", path: "/users/1", header: "bearer good", status: http.StatusOK},
+		{name: "NotOwner", path: "/users/2", header: "Bearer good", status: http.StatusForbidden},
+		{name: "InvalidID", path: "/users/abc", header: "Bearer good", status: http.StatusBadRequest},
+		{name: "MissingHeader", path: "/users/1", header: "", status: http.StatusUnauthorized},
+		{name: "WrongScheme", path: "/users/1", header: "Basic good", status: http.StatusUnauthorized},
+		{name: "InvalidToken", path: "/users/1", header: "Bearer bad", status: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set(HeaderAuthorization, tt.header)
+			}
+			w := httptest.NewRecorder()
+			newRouter().ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusOK {
+				assert.Equal(t, "alice", w.Body.String())
+			}
+			if tt.status == http.StatusUnauthorized {
+				assert.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+			}
+			if tt.status != http.StatusOK {
+				assert.Equal(t, apierr.ContentTypeProblem, w.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	verifier := stubVerifier{
+		"admin": {UserID: 1, Username: "alice", Roles: []string{domain.RoleAdmin}, Permissions: []string{domain.PermissionUsersUnlock}},
+		"user":  {UserID: 2, Username: "bob"},
+	}
+	router := gin.New()
+	router.Use(Errors(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	router.POST("/admin", Auth(verifier), RequirePermission(domain.PermissionUsersUnlock), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	for token, status := range map[string]int{
+		"admin": http.StatusNoContent,
+		"user":  http.StatusForbidden,
+		"bad":   http.StatusUnauthorized,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/admin", nil)
+		req.Header.Set(HeaderAuthorization, "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, status, w.Code, token)
+	}
+}
+
+func TestRequireOwnerOr(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	verifier := stubVerifier{
+		"reader": {UserID: 1, Username: "alice", Permissions: []string{domain.PermissionUsersRead}},
+		"user":   {UserID: 2, Username: "bob"},
+	}
+	router := gin.New()
+	router.Use(Errors(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	router.GET("/users/:id", Auth(verifier), RequireOwnerOr("id", domain.PermissionUsersRead), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		token, path string
+		status      int
+	}{
+		{token: "reader", path: "/users/2", status: http.StatusOK},
+		{token: "user", path: "/users/2", status: http.StatusOK},
+		{token: "user", path: "/users/1", status: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		req.Header.Set(HeaderAuthorization, "Bearer "+tt.token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, tt.status, w.Code, tt.token+" "+tt.path)
+	}
+}
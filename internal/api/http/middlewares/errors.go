@@ -0,0 +1,51 @@
+package middlewares
+
+import (
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kerim-dauren/user-service/internal/api/apierr"
+	"github.com/kerim-dauren/user-service/pkg/tokenx"
+)
+
+// Errors renders the last error recorded with c.Error as an RFC 7807
+// application/problem+json response, unless a response was already written.
+// Errors without a registry entry are logged and reported as a generic 500.
+func Errors(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		err := c.Errors.Last().Err
+
+		traceID := traceIDFromRequest(c)
+		problem, ok := apierr.NewProblem(err, c.Request.URL.Path, traceID)
+		if !ok {
+			logger.ErrorContext(c.Request.Context(), "unhandled http error",
+				slog.String("method", c.Request.Method),
+				slog.String("path", c.FullPath()),
+				slog.String("trace_id", traceID),
+				slog.Any("error", err),
+			)
+		}
+
+		c.Header(HeaderTraceID, traceID)
+		c.Header("Content-Type", apierr.ContentTypeProblem)
+		c.JSON(problem.Status, problem)
+	}
+}
+
+// traceIDFromRequest returns the trace ID set by TraceID, the X-Trace-ID header,
+// or a freshly generated ID so that every problem can be correlated with logs.
+func traceIDFromRequest(c *gin.Context) string {
+	if traceID, ok := c.Request.Context().Value(ContextTraceIDKey).(string); ok && traceID != "" {
+		return traceID
+	}
+	if traceID := c.GetHeader(HeaderTraceID); traceID != "" {
+		return traceID
+	}
+	traceID, _ := tokenx.NewID()
+	return traceID
+}
@@ -0,0 +1,103 @@
+package middlewares
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kerim-dauren/user-service/internal/api/apierr"
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serve := func(err error, header string) (*httptest.ResponseRecorder, apierr.Problem) {
+		router := gin.New()
+		router.Use(Errors(slog.New(slog.NewTextHandler(io.Discard, nil))))
+		router.GET("/users/:id", func(c *gin.Context) {
+			_ = c.Error(err)
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+		if header != "" {
+			req.Header.Set(HeaderTraceID, header)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var problem apierr.Problem
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+		return w, problem
+	}
+
+	t.Run("DomainError", func(t *testing.T) {
+		w, problem := serve(fmt.Errorf("lookup: %w", domain.ErrUserNotFound), "trace-1")
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, apierr.ContentTypeProblem, w.Header().Get("Content-Type"))
+		assert.Equal(t, "trace-1", w.Header().Get(HeaderTraceID))
+		assert.Equal(t, "urn:user-service:problem:user-not-found", problem.Type)
+		assert.Equal(t, http.StatusNotFound, problem.Status)
+		assert.Equal(t, "USER_NOT_FOUND", problem.Code)
+		assert.Equal(t, "/users/1", problem.Instance)
+		assert.Equal(t, "trace-1", problem.TraceID)
+	})
+
+	t.Run("ValidationError", func(t *testing.T) {
+		w, problem := serve(domain.NewValidationError(domain.FieldViolation{Field: "email", Description: "is required"}), "")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, apierr.CodeValidationFailed, problem.Code)
+		assert.Equal(t, []domain.FieldViolation{{Field: "email", Description: "is required"}}, problem.Errors)
+		assert.NotEmpty(t, problem.TraceID)
+	})
+
+	t.Run("InternalErrorIsSanitized", func(t *testing.T) {
+		w, problem := serve(errors.New("pq: connection refused"), "")
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, apierr.CodeInternal, problem.Code)
+		assert.NotContains(t, w.Body.String(), "connection refused")
+	})
+
+	t.Run("TraceIDFromContext", func(t *testing.T) {
+		router := gin.New()
+		router.Use(TraceID(), Errors(slog.New(slog.NewTextHandler(io.Discard, nil))))
+		router.GET("/", func(c *gin.Context) {
+			_ = c.Error(context.DeadlineExceeded)
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(HeaderTraceID, "trace-2")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+		assert.Contains(t, w.Body.String(), `"trace_id":"trace-2"`)
+	})
+
+	t.Run("WrittenResponseIsKept", func(t *testing.T) {
+		router := gin.New()
+		router.Use(Errors(slog.New(slog.NewTextHandler(io.Discard, nil))))
+		router.GET("/", func(c *gin.Context) {
+			_ = c.Error(domain.ErrUserNotFound)
+			c.String(http.StatusOK, "partial")
+		})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "partial", w.Body.String())
+	})
+}
@@ -0,0 +1,34 @@
+package middlewares
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kerim-dauren/user-service/internal/domain"
+)
+
+const HeaderTenantID = "X-Tenant-ID"
+
+// Tenant stores the organization named by the X-Tenant-ID header in the request context.
+// Requests without the header act in the default organization, and authenticated requests
+// always act in the organization of their access token, whatever the header says.
+func Tenant() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader(HeaderTenantID)
+		if header == "" {
+			c.Next()
+			return
+		}
+		id, err := strconv.ParseInt(header, 10, 64)
+		if err != nil || id <= 0 {
+			_ = c.Error(domain.NewValidationError(domain.FieldViolation{Field: HeaderTenantID, Description: "must be a positive integer"}))
+			c.Abort()
+			return
+		}
+
+		ctx := domain.ContextWithTenant(c.Request.Context(), id)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
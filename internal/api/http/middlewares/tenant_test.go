@@ -0,0 +1,81 @@
+package middlewares
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenant(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	verifier := stubVerifier{"member": {UserID: 1, OrganizationID: 7}}
+	router := gin.New()
+	router.Use(Errors(slog.New(slog.NewTextHandler(io.Discard, nil))), Tenant())
+	handler := func(c *gin.Context) {
+		c.String(http.StatusOK, strconv.FormatInt(domain.TenantFromContext(c.Request.Context()), 10))
+	}
+	router.GET("/public", handler)
+	router.GET("/private", Auth(verifier), handler)
+
+	tests := []struct {
+		name, path, tenant, token string
+		status                    int
+		body                      string
+	}{
+		{name: "Default", path: "/public", status: http.StatusOK, body: "1"},
+		{name: "Header", path: "/public", tenant: "3", status: http.StatusOK, body: "3"},
+		{name: "TokenWins", path: "/private", tenant: "3", token: "member", status: http.StatusOK, body: "7"},
+		{name: "Invalid", path: "/public", tenant: "acme", status: http.StatusBadRequest},
+		{name: "NotPositive", path: "/public", tenant: "0", status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.tenant != "" {
+				req.Header.Set(HeaderTenantID, tt.tenant)
+			}
+			if tt.token != "" {
+				req.Header.Set(HeaderAuthorization, "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusOK {
+				assert.Equal(t, tt.body, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRequireOrganizationRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	verifier := stubVerifier{
+		"admin":  {UserID: 1, OrganizationRole: domain.OrganizationRoleAdmin},
+		"member": {UserID: 2, OrganizationRole: domain.OrganizationRoleMember},
+	}
+	router := gin.New()
+	router.Use(Errors(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	router.GET("/members", Auth(verifier), RequireOrganizationRole(domain.OrganizationRoleOwner, domain.OrganizationRoleAdmin), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for token, status := range map[string]int{
+		"admin":  http.StatusOK,
+		"member": http.StatusForbidden,
+		"bad":    http.StatusUnauthorized,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/members", nil)
+		req.Header.Set(HeaderAuthorization, "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, status, w.Code, token)
+	}
+}
@@ -0,0 +1,149 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kerim-dauren/user-service/internal/domain"
+)
+
+type AdminHandler struct {
+	lockoutService domain.LockoutService
+	roleService    domain.RoleService
+}
+
+func NewAdminHandler(lockoutService domain.LockoutService, roleService domain.RoleService) *AdminHandler {
+	return &AdminHandler{lockoutService: lockoutService, roleService: roleService}
+}
+
+// UnlockAccount godoc
+// @Summary Unlock an account
+// @Description Lift the lockout of a user after failed logins and forget the failures. Requires the users:unlock permission
+// @Tags admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 204 "No Content" "Account unlocked"
+// @Failure 400 {object} apierr.Problem "Invalid ID format"
+// @Failure 401 {object} apierr.Problem "Missing or invalid access token"
+// @Failure 403 {object} apierr.Problem "Missing permission"
+// @Failure 404 {object} apierr.Problem "User not found"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/admin/users/{id}/unlock [post]
+func (h *AdminHandler) UnlockAccount(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.lockoutService.UnlockAccount(c.Request.Context(), id); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListRoles godoc
+// @Summary List roles
+// @Description List the roles that can be assigned and the permissions they grant. Requires the roles:read permission
+// @Tags admin
+// @Produce json
+// @Success 200 {array} domain.Role "Roles"
+// @Failure 401 {object} apierr.Problem "Missing or invalid access token"
+// @Failure 403 {object} apierr.Problem "Missing permission"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/admin/roles [get]
+func (h *AdminHandler) ListRoles(c *gin.Context) {
+	roles, err := h.roleService.ListRoles(c.Request.Context())
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	if roles == nil {
+		roles = []*domain.Role{}
+	}
+	c.JSON(http.StatusOK, roles)
+}
+
+// ListUserRoles godoc
+// @Summary List the roles of a user
+// @Description List the roles assigned to a user. Requires the roles:read permission
+// @Tags admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {array} domain.Role "Roles of the user"
+// @Failure 400 {object} apierr.Problem "Invalid ID format"
+// @Failure 401 {object} apierr.Problem "Missing or invalid access token"
+// @Failure 403 {object} apierr.Problem "Missing permission"
+// @Failure 404 {object} apierr.Problem "User not found"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/admin/users/{id}/roles [get]
+func (h *AdminHandler) ListUserRoles(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	roles, err := h.roleService.ListUserRoles(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	if roles == nil {
+		roles = []*domain.Role{}
+	}
+	c.JSON(http.StatusOK, roles)
+}
+
+// AssignRole godoc
+// @Summary Assign a role
+// @Description Give a role to a user. It takes effect when the user's access token is next refreshed. Requires the roles:write permission
+// @Tags admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Param role path string true "Role name"
+// @Success 204 "No Content" "Role assigned"
+// @Failure 400 {object} apierr.Problem "Invalid ID format"
+// @Failure 401 {object} apierr.Problem "Missing or invalid access token"
+// @Failure 403 {object} apierr.Problem "Missing permission"
+// @Failure 404 {object} apierr.Problem "User or role not found"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/admin/users/{id}/roles/{role} [put]
+func (h *AdminHandler) AssignRole(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.roleService.AssignRole(c.Request.Context(), id, c.Param("role")); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RevokeRole godoc
+// @Summary Revoke a role
+// @Description Take a role from a user. It takes effect when the user's access token is next refreshed. Requires the roles:write permission
+// @Tags admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Param role path string true "Role name"
+// @Success 204 "No Content" "Role revoked"
+// @Failure 400 {object} apierr.Problem "Invalid ID format"
+// @Failure 401 {object} apierr.Problem "Missing or invalid access token"
+// @Failure 403 {object} apierr.Problem "Missing permission"
+// @Failure 404 {object} apierr.Problem "User or role not found"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/admin/users/{id}/roles/{role} [delete]
+func (h *AdminHandler) RevokeRole(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.roleService.RevokeRole(c.Request.Context(), id, c.Param("role")); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
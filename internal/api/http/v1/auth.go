@@ -0,0 +1,155 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kerim-dauren/user-service/internal/domain"
+)
+
+type AuthHandler struct {
+	authService domain.AuthService
+}
+
+func NewAuthHandler(authService domain.AuthService) *AuthHandler {
+	return &AuthHandler{authService: authService}
+}
+
+type LoginRequest struct {
+	Login    string `json:"login" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	Device   string `json:"device"`
+}
+
+// Login godoc
+// @Summary Authenticate a user
+// @Description Verify the password of a user identified by email or username and issue an access token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param credentials body LoginRequest true "User credentials"
+// @Success 200 {object} domain.AuthTokens "User authenticated successfully"
+// @Success 202 {object} MFARequiredResponse "Password accepted, a second factor is required"
+// @Failure 400 {object} apierr.Problem "Invalid request payload"
+// @Failure 401 {object} apierr.Problem "Invalid credentials"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/auth/login [post]
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req LoginRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.authService.Login(c.Request.Context(), req.Login, req.Password, clientInfo(c, req.Device))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	if result.MFAChallenge != nil {
+		c.JSON(http.StatusAccepted, MFARequiredResponse{MFARequired: true, MFAChallenge: *result.MFAChallenge})
+		return
+	}
+	c.JSON(http.StatusOK, result.Tokens)
+}
+
+// MFARequiredResponse is returned by a login that must be completed with VerifyMFA.
+type MFARequiredResponse struct {
+	MFARequired bool `json:"mfa_required"`
+	domain.MFAChallenge
+}
+
+type VerifyMFARequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"` // TOTP or recovery code
+	Device   string `json:"device"`
+}
+
+// VerifyMFA godoc
+// @Summary Complete a login with a second factor
+// @Description Exchange the MFA token of a login and a TOTP or recovery code for a token pair
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param challenge body VerifyMFARequest true "MFA token and code"
+// @Success 200 {object} domain.AuthTokens "User authenticated successfully"
+// @Failure 400 {object} apierr.Problem "Invalid request payload"
+// @Failure 401 {object} apierr.Problem "Invalid, expired or exhausted MFA token"
+// @Failure 403 {object} apierr.Problem "Invalid code"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/auth/mfa/verify [post]
+func (h *AuthHandler) VerifyMFA(c *gin.Context) {
+	var req VerifyMFARequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	tokens, err := h.authService.VerifyMFA(c.Request.Context(), req.MFAToken, req.Code, clientInfo(c, req.Device))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, tokens)
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// Refresh godoc
+// @Summary Refresh an access token
+// @Description Rotate a refresh token and issue a new token pair. Reusing a rotated token revokes the whole token family
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param token body RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} domain.AuthTokens "Tokens refreshed successfully"
+// @Failure 400 {object} apierr.Problem "Invalid request payload"
+// @Failure 401 {object} apierr.Problem "Invalid, expired or reused refresh token"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/auth/refresh [post]
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req RefreshTokenRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	tokens, err := h.authService.Refresh(c.Request.Context(), req.RefreshToken, clientInfo(c, ""))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Logout godoc
+// @Summary Log out
+// @Description End the session of a refresh token and revoke every token rotated from it
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param token body RefreshTokenRequest true "Refresh token"
+// @Success 204 "No Content" "Refresh token revoked"
+// @Failure 400 {object} apierr.Problem "Invalid request payload"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/auth/logout [post]
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req RefreshTokenRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := h.authService.Logout(c.Request.Context(), req.RefreshToken); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// clientInfo collects the session metadata of the calling client.
+func clientInfo(c *gin.Context, device string) domain.ClientInfo {
+	return domain.ClientInfo{
+		Device:    device,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
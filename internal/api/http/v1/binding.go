@@ -0,0 +1,64 @@
+package v1
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/kerim-dauren/user-service/internal/domain"
+)
+
+// bindJSON decodes the request body into obj. On failure it records a
+// ValidationError on the context and returns false.
+func bindJSON(c *gin.Context, obj any) bool {
+	if err := c.ShouldBindJSON(obj); err != nil {
+		_ = c.Error(bindError(obj, err))
+		return false
+	}
+	return true
+}
+
+// bindError converts a binding error into field violations named after the JSON keys of obj.
+func bindError(obj any, err error) error {
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return domain.NewValidationError(domain.FieldViolation{Field: "body", Description: "must be a valid JSON object"})
+	}
+
+	violations := make([]domain.FieldViolation, 0, len(fieldErrs))
+	for _, fe := range fieldErrs {
+		violations = append(violations, domain.FieldViolation{
+			Field:       jsonName(obj, fe.StructField()),
+			Description: ruleDescription(fe),
+		})
+	}
+	return domain.NewValidationError(violations...)
+}
+
+func jsonName(obj any, field string) string {
+	t := reflect.TypeOf(obj)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Struct {
+		if sf, ok := t.FieldByName(field); ok {
+			if name, _, _ := strings.Cut(sf.Tag.Get("json"), ","); name != "" && name != "-" {
+				return name
+			}
+		}
+	}
+	return field
+}
+
+func ruleDescription(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	default:
+		return "must satisfy the " + fe.Tag() + " rule"
+	}
+}
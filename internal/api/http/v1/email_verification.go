@@ -0,0 +1,72 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kerim-dauren/user-service/internal/domain"
+)
+
+type EmailVerificationHandler struct {
+	emailVerificationService domain.EmailVerificationService
+}
+
+func NewEmailVerificationHandler(emailVerificationService domain.EmailVerificationService) *EmailVerificationHandler {
+	return &EmailVerificationHandler{emailVerificationService: emailVerificationService}
+}
+
+type SendEmailVerificationRequest struct {
+	Email string `json:"email" binding:"required"`
+}
+
+// SendEmailVerification godoc
+// @Summary Resend an email verification link
+// @Description Send a new single-use verification link to the email. The response is the same whether or not the email is registered or already verified
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body SendEmailVerificationRequest true "Account email"
+// @Success 202 "Accepted" "Verification link sent if the email is registered and unverified"
+// @Failure 400 {object} apierr.Problem "Invalid request payload"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/auth/email-verification [post]
+func (h *EmailVerificationHandler) SendEmailVerification(c *gin.Context) {
+	var req SendEmailVerificationRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := h.emailVerificationService.SendEmailVerification(c.Request.Context(), req.Email); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
+
+type ConfirmEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ConfirmEmail godoc
+// @Summary Confirm an email address
+// @Description Mark the email as verified with the token from a verification link
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body ConfirmEmailRequest true "Verification token"
+// @Success 204 "No Content" "Email verified"
+// @Failure 400 {object} apierr.Problem "Invalid request payload or token"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/auth/email-verification/confirm [post]
+func (h *EmailVerificationHandler) ConfirmEmail(c *gin.Context) {
+	var req ConfirmEmailRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := h.emailVerificationService.ConfirmEmail(c.Request.Context(), req.Token); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
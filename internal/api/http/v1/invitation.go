@@ -0,0 +1,160 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kerim-dauren/user-service/internal/domain"
+)
+
+type InvitationHandler struct {
+	invitationService domain.InvitationService
+}
+
+func NewInvitationHandler(invitationService domain.InvitationService) *InvitationHandler {
+	return &InvitationHandler{invitationService: invitationService}
+}
+
+type CreateInvitationRequest struct {
+	Email string `json:"email" binding:"required"`
+	Role  string `json:"role" binding:"required"` // owner, admin or member
+}
+
+// CreateInvitation godoc
+// @Summary Invite someone to the organization
+// @Description Email a link to join the organization of the authenticated user with a role. Requires the owner or admin role there; only owners invite owners
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param request body CreateInvitationRequest true "Email and role"
+// @Success 201 {object} domain.Invitation "Invitation sent"
+// @Failure 400 {object} apierr.Problem "Invalid email or role"
+// @Failure 401 {object} apierr.Problem "Missing or invalid access token"
+// @Failure 403 {object} apierr.Problem "Not allowed to invite with this role"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/organization/invitations [post]
+func (h *InvitationHandler) CreateInvitation(c *gin.Context) {
+	var req CreateInvitationRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	invitation, err := h.invitationService.CreateInvitation(c.Request.Context(), req.Email, req.Role)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, invitation)
+}
+
+// ListInvitations godoc
+// @Summary List invitations
+// @Description List the invitations of the organization of the authenticated user, newest first. Requires the owner or admin role there
+// @Tags organizations
+// @Produce json
+// @Success 200 {array} domain.Invitation "Invitations"
+// @Failure 401 {object} apierr.Problem "Missing or invalid access token"
+// @Failure 403 {object} apierr.Problem "Not an owner or admin of the organization"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/organization/invitations [get]
+func (h *InvitationHandler) ListInvitations(c *gin.Context) {
+	invitations, err := h.invitationService.ListInvitations(c.Request.Context())
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	if invitations == nil {
+		invitations = []*domain.Invitation{}
+	}
+	c.JSON(http.StatusOK, invitations)
+}
+
+// RevokeInvitation godoc
+// @Summary Revoke an invitation
+// @Description Withdraw a pending invitation of the organization of the authenticated user; its link stops working. Requires the owner or admin role there
+// @Tags organizations
+// @Produce json
+// @Param id path int true "Invitation ID"
+// @Success 204 "No Content" "Invitation revoked"
+// @Failure 400 {object} apierr.Problem "Invalid ID format"
+// @Failure 401 {object} apierr.Problem "Missing or invalid access token"
+// @Failure 403 {object} apierr.Problem "Not an owner or admin of the organization"
+// @Failure 404 {object} apierr.Problem "No pending invitation with this ID"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/organization/invitations/{id} [delete]
+func (h *InvitationHandler) RevokeInvitation(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.invitationService.RevokeInvitation(c.Request.Context(), id); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Username string `json:"username"` // Required when the email has no account yet
+	Password string `json:"password"` // Required when the email has no account yet
+}
+
+// AcceptInvitation godoc
+// @Summary Accept an invitation
+// @Description Join the organization of an invitation with the token from its link. An existing account with the invited email is linked; otherwise an account is created with the given username and password. Either way the email is marked as verified
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Param request body AcceptInvitationRequest true "Token, and the new account's username and password"
+// @Success 200 {object} domain.UserResponse "Account in the organization"
+// @Failure 400 {object} apierr.Problem "Invalid payload, username or password, or an invalid, expired or answered invitation"
+// @Failure 409 {object} apierr.Problem "Username already exists"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/invitations/accept [post]
+func (h *InvitationHandler) AcceptInvitation(c *gin.Context) {
+	var req AcceptInvitationRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	user, err := h.invitationService.AcceptInvitation(c.Request.Context(), domain.AcceptInvitationRequest{
+		Token:    req.Token,
+		Username: req.Username,
+		Password: req.Password,
+	})
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+type DeclineInvitationRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// DeclineInvitation godoc
+// @Summary Decline an invitation
+// @Description Turn down an invitation with the token from its link; the link stops working
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Param request body DeclineInvitationRequest true "Token"
+// @Success 204 "No Content" "Invitation declined"
+// @Failure 400 {object} apierr.Problem "Invalid payload, or an invalid, expired or answered invitation"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/invitations/decline [post]
+func (h *InvitationHandler) DeclineInvitation(c *gin.Context) {
+	var req DeclineInvitationRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := h.invitationService.DeclineInvitation(c.Request.Context(), req.Token); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,81 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kerim-dauren/user-service/internal/domain"
+)
+
+type MagicLinkHandler struct {
+	magicLinkService domain.MagicLinkService
+	authService      domain.AuthService
+}
+
+func NewMagicLinkHandler(magicLinkService domain.MagicLinkService, authService domain.AuthService) *MagicLinkHandler {
+	return &MagicLinkHandler{magicLinkService: magicLinkService, authService: authService}
+}
+
+type MagicLinkRequest struct {
+	Email string `json:"email" binding:"required"`
+}
+
+// RequestMagicLink godoc
+// @Summary Request a login link
+// @Description Send a single-use login link to the email. The response is the same whether or not the email is registered or throttled
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body MagicLinkRequest true "Account email"
+// @Success 202 "Accepted" "Login link sent if the email is registered"
+// @Failure 400 {object} apierr.Problem "Invalid request payload"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/auth/magic-link [post]
+func (h *MagicLinkHandler) RequestMagicLink(c *gin.Context) {
+	var req MagicLinkRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := h.magicLinkService.RequestMagicLink(c.Request.Context(), req.Email); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
+
+type MagicLinkLoginRequest struct {
+	Token  string `json:"token" binding:"required"`
+	Device string `json:"device"`
+}
+
+// LoginWithMagicLink godoc
+// @Summary Log in with a login link
+// @Description Exchange the token from a login link for a token pair. The token works once
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body MagicLinkLoginRequest true "Login link token"
+// @Success 200 {object} domain.AuthTokens "User authenticated successfully"
+// @Success 202 {object} MFARequiredResponse "Link accepted, a second factor is required"
+// @Failure 400 {object} apierr.Problem "Invalid request payload"
+// @Failure 401 {object} apierr.Problem "Invalid, expired or used token"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/auth/magic-link/confirm [post]
+func (h *MagicLinkHandler) LoginWithMagicLink(c *gin.Context) {
+	var req MagicLinkLoginRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.authService.LoginWithMagicLink(c.Request.Context(), req.Token, clientInfo(c, req.Device))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	if result.MFAChallenge != nil {
+		c.JSON(http.StatusAccepted, MFARequiredResponse{MFARequired: true, MFAChallenge: *result.MFAChallenge})
+		return
+	}
+	c.JSON(http.StatusOK, result.Tokens)
+}
@@ -0,0 +1,65 @@
+package v1
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/kerim-dauren/user-service/internal/domain"
+)
+
+// UserMergePatch documents the members accepted by PATCH /users/{id}.
+type UserMergePatch struct {
+	Username string `json:"username,omitempty"`
+	Email    string `json:"email,omitempty"`
+}
+
+// parseMergePatch reads a JSON Merge Patch document into a UserPatch.
+// Absent members are left unchanged. Members cannot be removed with null
+// because every patchable field is required.
+func parseMergePatch(body io.Reader) (*domain.UserPatch, error) {
+	var doc map[string]json.RawMessage
+	if err := json.NewDecoder(body).Decode(&doc); err != nil || doc == nil {
+		return nil, domain.NewValidationError(domain.FieldViolation{Field: "body", Description: "must be a JSON object"})
+	}
+
+	patch := &domain.UserPatch{}
+	var violations []domain.FieldViolation
+	fields := make([]string, 0, len(doc))
+	for field := range doc {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		raw := doc[field]
+		var target **string
+		switch field {
+		case "username":
+			target = &patch.Username
+		case "email":
+			target = &patch.Email
+		case "password":
+			violations = append(violations, domain.FieldViolation{Field: field, Description: "must be changed with POST /users/{id}/password"})
+			continue
+		default:
+			violations = append(violations, domain.FieldViolation{Field: field, Description: "unknown or immutable field"})
+			continue
+		}
+
+		if string(raw) == "null" {
+			violations = append(violations, domain.FieldViolation{Field: field, Description: "cannot be removed"})
+			continue
+		}
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			violations = append(violations, domain.FieldViolation{Field: field, Description: "must be a string"})
+			continue
+		}
+		*target = &value
+	}
+	if len(violations) > 0 {
+		return nil, domain.NewValidationError(violations...)
+	}
+	return patch, nil
+}
@@ -0,0 +1,50 @@
+package v1
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMergePatch(t *testing.T) {
+	patch, err := parseMergePatch(strings.NewReader(`{"email":"new@example.com"}`))
+	require.NoError(t, err)
+	require.NotNil(t, patch.Email)
+	assert.Equal(t, "new@example.com", *patch.Email)
+	assert.Nil(t, patch.Username)
+
+	patch, err = parseMergePatch(strings.NewReader(`{}`))
+	require.NoError(t, err)
+	assert.True(t, patch.IsEmpty())
+}
+
+func TestParseMergePatch_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		fields []string
+	}{
+		{name: "NotAnObject", body: `[1]`, fields: []string{"body"}},
+		{name: "Null", body: `null`, fields: []string{"body"}},
+		{name: "Password", body: `{"password":"secret123"}`, fields: []string{"password"}},
+		{name: "Immutable", body: `{"id":2,"username":"bob"}`, fields: []string{"id"}},
+		{name: "RemoveRequired", body: `{"email":null,"username":7}`, fields: []string{"email", "username"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseMergePatch(strings.NewReader(tt.body))
+
+			var validationErr *domain.ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			fields := make([]string, 0, len(validationErr.Violations))
+			for _, v := range validationErr.Violations {
+				fields = append(fields, v.Field)
+			}
+			assert.Equal(t, tt.fields, fields)
+		})
+	}
+}
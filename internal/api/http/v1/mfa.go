@@ -0,0 +1,149 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kerim-dauren/user-service/internal/domain"
+)
+
+type MFAHandler struct {
+	mfaService domain.MFAService
+}
+
+func NewMFAHandler(mfaService domain.MFAService) *MFAHandler {
+	return &MFAHandler{mfaService: mfaService}
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"` // TOTP or, except for confirmation, recovery code
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// EnrollTOTP godoc
+// @Summary Start TOTP enrollment
+// @Description Generate a new TOTP secret with its otpauth URI and QR code. MFA stays off until the enrollment is confirmed
+// @Tags mfa
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} domain.TOTPEnrollment "Secret to add to an authenticator app"
+// @Failure 400 {object} apierr.Problem "Invalid ID format"
+// @Failure 401 {object} apierr.Problem "Missing or invalid access token"
+// @Failure 403 {object} apierr.Problem "Not the owner of the account"
+// @Failure 409 {object} apierr.Problem "MFA is already enabled"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/users/{id}/mfa/totp [post]
+func (h *MFAHandler) EnrollTOTP(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	enrollment, err := h.mfaService.EnrollTOTP(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// ConfirmTOTP godoc
+// @Summary Confirm TOTP enrollment
+// @Description Turn MFA on with a code from the authenticator app and return recovery codes, which are shown only once
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param code body MFACodeRequest true "TOTP code"
+// @Success 200 {object} RecoveryCodesResponse "MFA enabled"
+// @Failure 400 {object} apierr.Problem "Invalid request payload"
+// @Failure 401 {object} apierr.Problem "Missing or invalid access token"
+// @Failure 403 {object} apierr.Problem "Not the owner of the account or invalid code"
+// @Failure 409 {object} apierr.Problem "No pending enrollment or MFA is already enabled"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/users/{id}/mfa/totp/confirm [post]
+func (h *MFAHandler) ConfirmTOTP(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	var req MFACodeRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	recoveryCodes, err := h.mfaService.ConfirmTOTP(c.Request.Context(), id, req.Code)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, RecoveryCodesResponse{RecoveryCodes: recoveryCodes})
+}
+
+// DisableMFA godoc
+// @Summary Disable MFA
+// @Description Turn MFA off and delete the TOTP secret and recovery codes
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param code body MFACodeRequest true "TOTP or recovery code"
+// @Success 204 "No Content" "MFA disabled"
+// @Failure 400 {object} apierr.Problem "Invalid request payload"
+// @Failure 401 {object} apierr.Problem "Missing or invalid access token"
+// @Failure 403 {object} apierr.Problem "Not the owner of the account or invalid code"
+// @Failure 409 {object} apierr.Problem "MFA is not enabled"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/users/{id}/mfa/disable [post]
+func (h *MFAHandler) DisableMFA(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	var req MFACodeRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := h.mfaService.DisableMFA(c.Request.Context(), id, req.Code); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Regenerate recovery codes
+// @Description Replace every recovery code of a user, invalidating the old ones
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param code body MFACodeRequest true "TOTP or recovery code"
+// @Success 200 {object} RecoveryCodesResponse "New recovery codes"
+// @Failure 400 {object} apierr.Problem "Invalid request payload"
+// @Failure 401 {object} apierr.Problem "Missing or invalid access token"
+// @Failure 403 {object} apierr.Problem "Not the owner of the account or invalid code"
+// @Failure 409 {object} apierr.Problem "MFA is not enabled"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/users/{id}/mfa/recovery-codes [post]
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	var req MFACodeRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	recoveryCodes, err := h.mfaService.RegenerateRecoveryCodes(c.Request.Context(), id, req.Code)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, RecoveryCodesResponse{RecoveryCodes: recoveryCodes})
+}
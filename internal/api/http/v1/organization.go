@@ -0,0 +1,150 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kerim-dauren/user-service/internal/domain"
+)
+
+type OrganizationHandler struct {
+	organizationService domain.OrganizationService
+}
+
+func NewOrganizationHandler(organizationService domain.OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{organizationService: organizationService}
+}
+
+type CreateOrganizationRequest struct {
+	Slug string `json:"slug" binding:"required"`
+	Name string `json:"name" binding:"required"`
+}
+
+// CreateOrganization godoc
+// @Summary Create an organization
+// @Description Create an organization owned by the calling account. Requires the organizations:write permission
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param request body CreateOrganizationRequest true "Slug and name"
+// @Success 201 {object} domain.Organization "Organization created"
+// @Failure 400 {object} apierr.Problem "Invalid request payload"
+// @Failure 401 {object} apierr.Problem "Missing or invalid access token"
+// @Failure 403 {object} apierr.Problem "Missing permission"
+// @Failure 409 {object} apierr.Problem "Slug already taken"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/organizations [post]
+func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	var req CreateOrganizationRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	org := &domain.Organization{Slug: req.Slug, Name: req.Name}
+	id, err := h.organizationService.CreateOrganization(c.Request.Context(), org)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	org.ID = id
+	c.JSON(http.StatusCreated, org)
+}
+
+// ListOrganizations godoc
+// @Summary List organizations
+// @Description List every organization. Requires the organizations:read permission
+// @Tags organizations
+// @Produce json
+// @Success 200 {array} domain.Organization "Organizations"
+// @Failure 401 {object} apierr.Problem "Missing or invalid access token"
+// @Failure 403 {object} apierr.Problem "Missing permission"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/organizations [get]
+func (h *OrganizationHandler) ListOrganizations(c *gin.Context) {
+	orgs, err := h.organizationService.ListOrganizations(c.Request.Context())
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	if orgs == nil {
+		orgs = []*domain.Organization{}
+	}
+	c.JSON(http.StatusOK, orgs)
+}
+
+// GetOrganization godoc
+// @Summary Get the current organization
+// @Description Retrieve the organization of the authenticated user
+// @Tags organizations
+// @Produce json
+// @Success 200 {object} domain.Organization "Organization"
+// @Failure 401 {object} apierr.Problem "Missing or invalid access token"
+// @Failure 404 {object} apierr.Problem "Organization not found"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/organization [get]
+func (h *OrganizationHandler) GetOrganization(c *gin.Context) {
+	org, err := h.organizationService.GetOrganization(c.Request.Context())
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, org)
+}
+
+// ListMembers godoc
+// @Summary List members
+// @Description List the members of the organization of the authenticated user with their roles. Requires the owner or admin role there
+// @Tags organizations
+// @Produce json
+// @Success 200 {array} domain.Member "Members"
+// @Failure 401 {object} apierr.Problem "Missing or invalid access token"
+// @Failure 403 {object} apierr.Problem "Not an owner or admin of the organization"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/organization/members [get]
+func (h *OrganizationHandler) ListMembers(c *gin.Context) {
+	members, err := h.organizationService.ListMembers(c.Request.Context())
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	if members == nil {
+		members = []*domain.Member{}
+	}
+	c.JSON(http.StatusOK, members)
+}
+
+type SetMemberRoleRequest struct {
+	Role string `json:"role" binding:"required"` // owner, admin or member
+}
+
+// SetMemberRole godoc
+// @Summary Change the role of a member
+// @Description Change the role of a member of the organization of the authenticated user. Requires the owner or admin role there; only owners make or unmake owners, and nobody changes their own role. It takes effect when the member's access token is next refreshed
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param request body SetMemberRoleRequest true "New role"
+// @Success 204 "No Content" "Role changed"
+// @Failure 400 {object} apierr.Problem "Invalid ID format or role"
+// @Failure 401 {object} apierr.Problem "Missing or invalid access token"
+// @Failure 403 {object} apierr.Problem "Not allowed to change this role"
+// @Failure 404 {object} apierr.Problem "User not found in the organization"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/organization/members/{id} [put]
+func (h *OrganizationHandler) SetMemberRole(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	var req SetMemberRoleRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := h.organizationService.SetMemberRole(c.Request.Context(), id, req.Role); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,195 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kerim-dauren/user-service/internal/domain"
+)
+
+type PasskeyHandler struct {
+	passkeyService domain.PasskeyService
+	authService    domain.AuthService
+}
+
+func NewPasskeyHandler(passkeyService domain.PasskeyService, authService domain.AuthService) *PasskeyHandler {
+	return &PasskeyHandler{passkeyService: passkeyService, authService: authService}
+}
+
+type FinishPasskeyRegistrationRequest struct {
+	SessionID  string          `json:"session_id" binding:"required"`
+	Name       string          `json:"name"`                                               // Defaults to "Passkey N"
+	Credential json.RawMessage `json:"credential" binding:"required" swaggertype:"object"` // PublicKeyCredential from navigator.credentials.create()
+}
+
+type PasskeyLoginRequest struct {
+	SessionID  string          `json:"session_id" binding:"required"`
+	Credential json.RawMessage `json:"credential" binding:"required" swaggertype:"object"` // PublicKeyCredential from navigator.credentials.get()
+	Device     string          `json:"device"`
+}
+
+// BeginRegistration godoc
+// @Summary Begin passkey registration
+// @Description Return the options for navigator.credentials.create() and the ID of the registration session
+// @Tags passkeys
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} domain.PasskeyCeremony "Registration options"
+// @Failure 400 {object} apierr.Problem "Invalid ID format"
+// @Failure 401 {object} apierr.Problem "Missing or invalid access token"
+// @Failure 403 {object} apierr.Problem "Not the owner of the account"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/users/{id}/passkeys/options [post]
+func (h *PasskeyHandler) BeginRegistration(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	ceremony, err := h.passkeyService.BeginRegistration(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, ceremony)
+}
+
+// FinishRegistration godoc
+// @Summary Register a passkey
+// @Description Verify the credential created by the authenticator and add it to the passkeys of the user
+// @Tags passkeys
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param credential body FinishPasskeyRegistrationRequest true "Registration session and credential"
+// @Success 201 {object} domain.Passkey "Passkey registered"
+// @Failure 400 {object} apierr.Problem "Invalid request payload, session or attestation"
+// @Failure 401 {object} apierr.Problem "Missing or invalid access token"
+// @Failure 403 {object} apierr.Problem "Not the owner of the account"
+// @Failure 409 {object} apierr.Problem "Passkey already registered"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/users/{id}/passkeys [post]
+func (h *PasskeyHandler) FinishRegistration(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	var req FinishPasskeyRegistrationRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	passkey, err := h.passkeyService.FinishRegistration(c.Request.Context(), id, req.SessionID, req.Name, req.Credential)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, passkey)
+}
+
+// ListPasskeys godoc
+// @Summary List passkeys
+// @Description List the passkeys of a user with their sign counter and last use
+// @Tags passkeys
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {array} domain.Passkey "Passkeys"
+// @Failure 400 {object} apierr.Problem "Invalid ID format"
+// @Failure 401 {object} apierr.Problem "Missing or invalid access token"
+// @Failure 403 {object} apierr.Problem "Not the owner of the account"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/users/{id}/passkeys [get]
+func (h *PasskeyHandler) ListPasskeys(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	passkeys, err := h.passkeyService.ListPasskeys(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	if passkeys == nil {
+		passkeys = []*domain.Passkey{}
+	}
+	c.JSON(http.StatusOK, passkeys)
+}
+
+// DeletePasskey godoc
+// @Summary Delete a passkey
+// @Description Remove a passkey so that it can no longer be used to log in
+// @Tags passkeys
+// @Produce json
+// @Param id path int true "User ID"
+// @Param passkey_id path int true "Passkey ID"
+// @Success 204 "No Content" "Passkey deleted"
+// @Failure 400 {object} apierr.Problem "Invalid ID format"
+// @Failure 401 {object} apierr.Problem "Missing or invalid access token"
+// @Failure 403 {object} apierr.Problem "Not the owner of the account"
+// @Failure 404 {object} apierr.Problem "Passkey not found"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/users/{id}/passkeys/{passkey_id} [delete]
+func (h *PasskeyHandler) DeletePasskey(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	passkeyID, err := strconv.ParseInt(c.Param("passkey_id"), 10, 64)
+	if err != nil {
+		_ = c.Error(domain.NewValidationError(domain.FieldViolation{Field: "passkey_id", Description: "must be an integer"}))
+		return
+	}
+
+	if err := h.passkeyService.DeletePasskey(c.Request.Context(), id, passkeyID); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// BeginLogin godoc
+// @Summary Begin a passkey login
+// @Description Return the options for navigator.credentials.get() and the ID of the login session
+// @Tags auth
+// @Produce json
+// @Success 200 {object} domain.PasskeyCeremony "Login options"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/auth/passkey/options [post]
+func (h *PasskeyHandler) BeginLogin(c *gin.Context) {
+	ceremony, err := h.passkeyService.BeginLogin(c.Request.Context())
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, ceremony)
+}
+
+// FinishLogin godoc
+// @Summary Log in with a passkey
+// @Description Verify the assertion of a passkey and issue a token pair. No MFA challenge follows
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param credential body PasskeyLoginRequest true "Login session and assertion"
+// @Success 200 {object} domain.AuthTokens "User authenticated successfully"
+// @Failure 400 {object} apierr.Problem "Invalid request payload or session"
+// @Failure 401 {object} apierr.Problem "Passkey verification failed"
+// @Failure 403 {object} apierr.Problem "Email not verified"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/auth/passkey [post]
+func (h *PasskeyHandler) FinishLogin(c *gin.Context) {
+	var req PasskeyLoginRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	tokens, err := h.authService.LoginWithPasskey(c.Request.Context(), req.SessionID, req.Credential, clientInfo(c, req.Device))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, tokens)
+}
@@ -0,0 +1,73 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kerim-dauren/user-service/internal/domain"
+)
+
+type PasswordResetHandler struct {
+	passwordResetService domain.PasswordResetService
+}
+
+func NewPasswordResetHandler(passwordResetService domain.PasswordResetService) *PasswordResetHandler {
+	return &PasswordResetHandler{passwordResetService: passwordResetService}
+}
+
+type PasswordResetRequest struct {
+	Email string `json:"email" binding:"required"`
+}
+
+// RequestPasswordReset godoc
+// @Summary Request a password reset
+// @Description Send a single-use password reset link to the email. The response is the same whether or not the email is registered
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body PasswordResetRequest true "Account email"
+// @Success 202 "Accepted" "Reset link sent if the email is registered"
+// @Failure 400 {object} apierr.Problem "Invalid request payload"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/auth/password-reset [post]
+func (h *PasswordResetHandler) RequestPasswordReset(c *gin.Context) {
+	var req PasswordResetRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := h.passwordResetService.RequestPasswordReset(c.Request.Context(), req.Email); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
+
+type ConfirmPasswordResetRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// ConfirmPasswordReset godoc
+// @Summary Confirm a password reset
+// @Description Set a new password with the token from a reset link. Every session of the user is signed out
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body ConfirmPasswordResetRequest true "Reset token and new password"
+// @Success 204 "No Content" "Password reset"
+// @Failure 400 {object} apierr.Problem "Invalid request payload, new password or token"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/auth/password-reset/confirm [post]
+func (h *PasswordResetHandler) ConfirmPasswordReset(c *gin.Context) {
+	var req ConfirmPasswordResetRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := h.passwordResetService.ConfirmPasswordReset(c.Request.Context(), req.Token, req.NewPassword); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
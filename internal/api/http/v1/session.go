@@ -0,0 +1,106 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kerim-dauren/user-service/internal/domain"
+)
+
+type SessionHandler struct {
+	sessionService domain.SessionService
+}
+
+func NewSessionHandler(sessionService domain.SessionService) *SessionHandler {
+	return &SessionHandler{sessionService: sessionService}
+}
+
+// ListSessions godoc
+// @Summary List active sessions
+// @Description List the active sessions of a user with device, IP, user agent and last activity
+// @Tags sessions
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {array} domain.Session "Active sessions"
+// @Failure 400 {object} apierr.Problem "Invalid ID format"
+// @Failure 401 {object} apierr.Problem "Missing or invalid access token"
+// @Failure 403 {object} apierr.Problem "Not the owner of the account"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/users/{id}/sessions [get]
+func (h *SessionHandler) ListSessions(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.sessionService.ListSessions(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	if principal, ok := domain.PrincipalFromContext(c.Request.Context()); ok {
+		for _, s := range sessions {
+			s.Current = s.ID == principal.SessionID
+		}
+	}
+	if sessions == nil {
+		sessions = []*domain.Session{}
+	}
+	c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeSession godoc
+// @Summary Sign out a session
+// @Description Terminate one session of a user and revoke its refresh tokens
+// @Tags sessions
+// @Produce json
+// @Param id path int true "User ID"
+// @Param session_id path string true "Session ID"
+// @Success 204 "No Content" "Session revoked"
+// @Failure 400 {object} apierr.Problem "Invalid ID format"
+// @Failure 401 {object} apierr.Problem "Missing or invalid access token"
+// @Failure 403 {object} apierr.Problem "Not the owner of the account"
+// @Failure 404 {object} apierr.Problem "Session not found"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/users/{id}/sessions/{session_id} [delete]
+func (h *SessionHandler) RevokeSession(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.sessionService.RevokeSession(c.Request.Context(), id, c.Param("session_id")); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RevokeOtherSessions godoc
+// @Summary Sign out other sessions
+// @Description Terminate every session of a user except the one making the request
+// @Tags sessions
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 204 "No Content" "Sessions revoked"
+// @Failure 400 {object} apierr.Problem "Invalid ID format"
+// @Failure 401 {object} apierr.Problem "Missing or invalid access token"
+// @Failure 403 {object} apierr.Problem "Not the owner of the account"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/users/{id}/sessions [delete]
+func (h *SessionHandler) RevokeOtherSessions(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	var current string
+	if principal, ok := domain.PrincipalFromContext(c.Request.Context()); ok {
+		current = principal.SessionID
+	}
+	if err := h.sessionService.RevokeOtherSessions(c.Request.Context(), id, current); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
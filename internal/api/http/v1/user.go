@@ -1,28 +1,28 @@
 package v1
 
 import (
-	"errors"
-	"fmt"
 	"github.com/kerim-dauren/user-service/internal/domain"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 type UserHandler struct {
-	userService domain.UserService
+	userService              domain.UserService
+	emailVerificationService domain.EmailVerificationService
 }
 
-func NewUserHandler(userService domain.UserService) *UserHandler {
-	return &UserHandler{userService: userService}
+func NewUserHandler(userService domain.UserService, emailVerificationService domain.EmailVerificationService) *UserHandler {
+	return &UserHandler{userService: userService, emailVerificationService: emailVerificationService}
 }
 
 // parseID - helper function to extract the identifier from the URL.
 func parseID(c *gin.Context) (int64, bool) {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		_ = c.Error(domain.NewValidationError(domain.FieldViolation{Field: "id", Description: "must be an integer"}))
 		return 0, false
 	}
 	return id, true
@@ -30,32 +30,30 @@ func parseID(c *gin.Context) (int64, bool) {
 
 // CreateUser godoc
 // @Summary Create a new user
-// @Description Create a new user with the provided details
+// @Description Create a new user with the provided details and send a link to verify the email
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param user body domain.User true "User object"
 // @Success 201 {object} domain.User "User created successfully"
-// @Failure 400 {object} map[string]string "Invalid request payload or email already exists"
-// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 400 {object} apierr.Problem "Invalid request payload"
+// @Failure 409 {object} apierr.Problem "Email or username already exists"
+// @Failure 500 {object} apierr.Problem "Internal server error"
 // @Router /api/v1/users [post]
 func (h *UserHandler) CreateUser(c *gin.Context) {
 	var user domain.User
-	if err := c.ShouldBindJSON(&user); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindJSON(c, &user) {
 		return
 	}
 
 	id, err := h.userService.CreateUser(c.Request.Context(), &user)
 	if err != nil {
-		if errors.Is(err, domain.ErrUserMailAlreadyExists) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 	user.ID = id
+	// The account exists either way; failures are logged and a new link can be requested.
+	_ = h.emailVerificationService.SendEmailVerification(c.Request.Context(), user.Email)
 
 	c.JSON(http.StatusCreated, user)
 }
@@ -68,8 +66,9 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 // @Produce json
 // @Param id path int true "User ID"
 // @Success 200 {object} domain.User "User retrieved successfully"
-// @Failure 400 {object} map[string]string "Invalid ID format"
-// @Failure 404 {object} map[string]string "User not found"
+// @Failure 400 {object} apierr.Problem "Invalid ID format"
+// @Failure 404 {object} apierr.Problem "User not found"
+// @Failure 500 {object} apierr.Problem "Internal server error"
 // @Router /api/v1/users/{id} [get]
 func (h *UserHandler) GetUser(c *gin.Context) {
 	id, ok := parseID(c)
@@ -79,28 +78,81 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 
 	user, err := h.userService.GetUserByID(c.Request.Context(), id)
 	if err != nil {
-		if errors.Is(err, domain.ErrUserNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("user not found: %d", id)})
-			return
-		}
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, user)
 }
 
+// ListUsersRequest holds the query parameters of ListUsers.
+type ListUsersRequest struct {
+	PageSize         int       `form:"page_size"`
+	PageToken        string    `form:"page_token"`
+	EmailPrefix      string    `form:"email_prefix"`
+	UsernameContains string    `form:"username_contains"`
+	CreatedAfter     time.Time `form:"created_after" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedBefore    time.Time `form:"created_before" time_format:"2006-01-02T15:04:05Z07:00"`
+	SortBy           string    `form:"sort_by"`
+	SortOrder        string    `form:"sort_order"`
+}
+
+// ListUsers godoc
+// @Summary List users
+// @Description List users page by page. Pass next_page_token as page_token with the same filters and sort to get the next page
+// @Tags users
+// @Produce json
+// @Param page_size query int false "Page size, 20 by default and at most 100"
+// @Param page_token query string false "Token of the next page"
+// @Param email_prefix query string false "Only emails starting with this prefix"
+// @Param username_contains query string false "Only usernames containing this text, case-insensitive"
+// @Param created_after query string false "Only users created at or after this RFC 3339 time"
+// @Param created_before query string false "Only users created before this RFC 3339 time"
+// @Param sort_by query string false "id, created_at, username or email" default(id)
+// @Param sort_order query string false "asc or desc" default(asc)
+// @Success 200 {object} domain.UserPage "Users retrieved successfully"
+// @Failure 400 {object} apierr.Problem "Invalid query parameters or page token"
+// @Failure 401 {object} apierr.Problem "Missing or invalid access token"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/users [get]
+func (h *UserHandler) ListUsers(c *gin.Context) {
+	var req ListUsersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		_ = c.Error(domain.NewValidationError(domain.FieldViolation{Field: "query", Description: err.Error()}))
+		return
+	}
+
+	page, err := h.userService.ListUsers(c.Request.Context(), domain.ListUsersParams{
+		Filter: domain.UserFilter{
+			EmailPrefix:      req.EmailPrefix,
+			UsernameContains: req.UsernameContains,
+			CreatedAfter:     req.CreatedAfter,
+			CreatedBefore:    req.CreatedBefore,
+		},
+		SortBy:    req.SortBy,
+		SortOrder: req.SortOrder,
+		PageSize:  req.PageSize,
+		PageToken: req.PageToken,
+	})
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
 // UpdateUser godoc
 // @Summary Update a user
-// @Description Update an existing user's information
+// @Description Replace an existing user's information. An empty password keeps the current one
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param id path int true "User ID"
 // @Param user body domain.User true "Updated user object"
 // @Success 200 {object} domain.User "User updated successfully"
-// @Failure 400 {object} map[string]string "Invalid request payload, ID, or email already exists"
-// @Failure 404 {object} map[string]string "User not found"
-// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 400 {object} apierr.Problem "Invalid request payload or ID"
+// @Failure 404 {object} apierr.Problem "User not found"
+// @Failure 409 {object} apierr.Problem "Email or username already exists"
+// @Failure 500 {object} apierr.Problem "Internal server error"
 // @Router /api/v1/users/{id} [put]
 func (h *UserHandler) UpdateUser(c *gin.Context) {
 	id, ok := parseID(c)
@@ -109,23 +161,93 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 	}
 
 	var user domain.User
-	if err := c.ShouldBindJSON(&user); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindJSON(c, &user) {
 		return
 	}
 	user.ID = id
 
 	if err := h.userService.UpdateUser(c.Request.Context(), &user); err != nil {
-		if errors.Is(err, domain.ErrUserMailAlreadyExists) {
-			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// PatchUser godoc
+// @Summary Partially update a user
+// @Description Change only the fields present in a JSON Merge Patch (RFC 7396) document. Username and email can be patched; the password cannot
+// @Tags users
+// @Accept json
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param patch body UserMergePatch true "Fields to change"
+// @Success 200 {object} domain.UserResponse "User updated successfully"
+// @Failure 400 {object} apierr.Problem "Invalid patch or ID"
+// @Failure 401 {object} apierr.Problem "Missing or invalid access token"
+// @Failure 403 {object} apierr.Problem "Not the owner of the account"
+// @Failure 404 {object} apierr.Problem "User not found"
+// @Failure 409 {object} apierr.Problem "Email or username already exists"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/users/{id} [patch]
+func (h *UserHandler) PatchUser(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	patch, err := parseMergePatch(c.Request.Body)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	user, err := h.userService.PatchUser(c.Request.Context(), id, patch)
+	if err != nil {
+		_ = c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, user)
 }
 
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+// ChangePassword godoc
+// @Summary Change the password
+// @Description Replace the password after verifying the current one. Every other session of the user is signed out
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param passwords body ChangePasswordRequest true "Current and new password"
+// @Success 204 "No Content" "Password changed"
+// @Failure 400 {object} apierr.Problem "Invalid request payload or new password"
+// @Failure 401 {object} apierr.Problem "Missing or invalid access token"
+// @Failure 403 {object} apierr.Problem "Not the owner of the account or incorrect current password"
+// @Failure 404 {object} apierr.Problem "User not found"
+// @Failure 500 {object} apierr.Problem "Internal server error"
+// @Router /api/v1/users/{id}/password [post]
+func (h *UserHandler) ChangePassword(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	var req ChangePasswordRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := h.userService.ChangePassword(c.Request.Context(), id, req.CurrentPassword, req.NewPassword); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
 // DeleteUser godoc
 // @Summary Delete a user
 // @Description Delete a user by their unique ID
@@ -134,9 +256,9 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 // @Produce json
 // @Param id path int true "User ID"
 // @Success 204 "No Content" "User deleted successfully"
-// @Failure 400 {object} map[string]string "Invalid ID format"
-// @Failure 404 {object} map[string]string "User not found"
-// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 400 {object} apierr.Problem "Invalid ID format"
+// @Failure 404 {object} apierr.Problem "User not found"
+// @Failure 500 {object} apierr.Problem "Internal server error"
 // @Router /api/v1/users/{id} [delete]
 func (h *UserHandler) DeleteUser(c *gin.Context) {
 	id, ok := parseID(c)
@@ -144,7 +266,7 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 		return
 	}
 	if err := h.userService.DeleteUser(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 	c.Status(http.StatusNoContent)
@@ -2,15 +2,18 @@ package api
 
 import (
 	"embed"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	_ "github.com/kerim-dauren/user-service/internal/api/docs"
 	"github.com/kerim-dauren/user-service/internal/api/http/middlewares"
 	"github.com/kerim-dauren/user-service/internal/api/http/v1"
 	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/kerim-dauren/user-service/pkg/tokenx"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"log/slog"
 	"net/http"
 )
 
@@ -33,16 +36,36 @@ func init() {
 }
 
 type RouterDeps struct {
-	UserService domain.UserService
+	Logger                   *slog.Logger
+	UserService              domain.UserService
+	AuthService              domain.AuthService
+	SessionService           domain.SessionService
+	PasswordResetService     domain.PasswordResetService
+	EmailVerificationService domain.EmailVerificationService
+	MFAService               domain.MFAService
+	PasskeyService           domain.PasskeyService
+	MagicLinkService         domain.MagicLinkService
+	LockoutService           domain.LockoutService
+	RoleService              domain.RoleService
+	OrganizationService      domain.OrganizationService
+	InvitationService        domain.InvitationService
+	JWKS                     func() tokenx.JWKSet
+	// TrustedProxies lists the addresses or CIDRs of the proxies whose X-Forwarded-For
+	// header is believed. Without any, client IPs are the peer addresses.
+	TrustedProxies []string
 }
 
-func NewHttpRouter(deps *RouterDeps) *gin.Engine {
+func NewHttpRouter(deps *RouterDeps) (*gin.Engine, error) {
 	router := gin.New()
+	// Client IPs feed the login lockout, so a forwarded one is only taken from a known proxy.
+	if err := router.SetTrustedProxies(deps.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("trusted proxies: %w", err)
+	}
 
 	if gin.Mode() == gin.DebugMode {
 		router.Use(gin.Logger())
 	}
-	router.Use(gin.Recovery())
+	router.Use(gin.Recovery(), middlewares.Errors(deps.Logger))
 
 	// Swagger
 	if gin.Mode() != gin.ReleaseMode {
@@ -63,23 +86,92 @@ func NewHttpRouter(deps *RouterDeps) *gin.Engine {
 	// Prometheus
 	router.GET("/metrics", metricsHandler())
 
+	// Public keys for offline verification of access tokens
+	router.GET("/.well-known/jwks.json", func(c *gin.Context) {
+		c.JSON(http.StatusOK, deps.JWKS())
+	})
+
 	// Routers
 	apiV1 := router.Group("/api/v1")
 	{
 		apiV1.Use(
 			middlewares.PrometheusMiddleware(requestDuration),
+			middlewares.Tenant(),
 			//middlewares.TraceID(), //TODO for tracing requests
 		)
 
-		userHandler := v1.NewUserHandler(deps.UserService)
+		userHandler := v1.NewUserHandler(deps.UserService, deps.EmailVerificationService)
+		authHandler := v1.NewAuthHandler(deps.AuthService)
+		sessionHandler := v1.NewSessionHandler(deps.SessionService)
+		passwordResetHandler := v1.NewPasswordResetHandler(deps.PasswordResetService)
+		emailVerificationHandler := v1.NewEmailVerificationHandler(deps.EmailVerificationService)
+		mfaHandler := v1.NewMFAHandler(deps.MFAService)
+		passkeyHandler := v1.NewPasskeyHandler(deps.PasskeyService, deps.AuthService)
+		magicLinkHandler := v1.NewMagicLinkHandler(deps.MagicLinkService, deps.AuthService)
+		adminHandler := v1.NewAdminHandler(deps.LockoutService, deps.RoleService)
+		organizationHandler := v1.NewOrganizationHandler(deps.OrganizationService)
+		invitationHandler := v1.NewInvitationHandler(deps.InvitationService)
+		authenticated := middlewares.Auth(deps.AuthService)
+		owner := middlewares.RequireOwner("id")
+		// The owner of an account may act on it; permissions extend an action to other accounts.
+		ownerOr := func(permission string) gin.HandlerFunc { return middlewares.RequireOwnerOr("id", permission) }
+		can := middlewares.RequirePermission
+		orgAdmin := middlewares.RequireOrganizationRole(domain.OrganizationRoleOwner, domain.OrganizationRoleAdmin)
+
+		apiV1.POST("/auth/login", authHandler.Login)
+		apiV1.POST("/auth/mfa/verify", authHandler.VerifyMFA)
+		apiV1.POST("/auth/passkey/options", passkeyHandler.BeginLogin)
+		apiV1.POST("/auth/passkey", passkeyHandler.FinishLogin)
+		apiV1.POST("/auth/magic-link", magicLinkHandler.RequestMagicLink)
+		apiV1.POST("/auth/magic-link/confirm", magicLinkHandler.LoginWithMagicLink)
+		apiV1.POST("/auth/refresh", authHandler.Refresh)
+		apiV1.POST("/auth/logout", authHandler.Logout)
+		apiV1.POST("/auth/password-reset", passwordResetHandler.RequestPasswordReset)
+		apiV1.POST("/auth/password-reset/confirm", passwordResetHandler.ConfirmPasswordReset)
+		apiV1.POST("/auth/email-verification", emailVerificationHandler.SendEmailVerification)
+		apiV1.POST("/auth/email-verification/confirm", emailVerificationHandler.ConfirmEmail)
 
 		apiV1.POST("/users", userHandler.CreateUser)
-		apiV1.GET("/users/:id", userHandler.GetUser)
-		apiV1.PUT("/users/:id", userHandler.UpdateUser)
-		apiV1.DELETE("/users/:id", userHandler.DeleteUser)
+		apiV1.GET("/users", authenticated, can(domain.PermissionUsersRead), userHandler.ListUsers)
+		apiV1.GET("/users/:id", authenticated, ownerOr(domain.PermissionUsersRead), userHandler.GetUser)
+		apiV1.PUT("/users/:id", authenticated, ownerOr(domain.PermissionUsersWrite), userHandler.UpdateUser)
+		apiV1.PATCH("/users/:id", authenticated, ownerOr(domain.PermissionUsersWrite), userHandler.PatchUser)
+		apiV1.DELETE("/users/:id", authenticated, ownerOr(domain.PermissionUsersDelete), userHandler.DeleteUser)
+		apiV1.POST("/users/:id/password", authenticated, owner, userHandler.ChangePassword)
+
+		apiV1.POST("/users/:id/mfa/totp", authenticated, owner, mfaHandler.EnrollTOTP)
+		apiV1.POST("/users/:id/mfa/totp/confirm", authenticated, owner, mfaHandler.ConfirmTOTP)
+		apiV1.POST("/users/:id/mfa/disable", authenticated, owner, mfaHandler.DisableMFA)
+		apiV1.POST("/users/:id/mfa/recovery-codes", authenticated, owner, mfaHandler.RegenerateRecoveryCodes)
+
+		apiV1.POST("/users/:id/passkeys/options", authenticated, owner, passkeyHandler.BeginRegistration)
+		apiV1.POST("/users/:id/passkeys", authenticated, owner, passkeyHandler.FinishRegistration)
+		apiV1.GET("/users/:id/passkeys", authenticated, owner, passkeyHandler.ListPasskeys)
+		apiV1.DELETE("/users/:id/passkeys/:passkey_id", authenticated, owner, passkeyHandler.DeletePasskey)
+
+		apiV1.GET("/users/:id/sessions", authenticated, owner, sessionHandler.ListSessions)
+		apiV1.DELETE("/users/:id/sessions", authenticated, owner, sessionHandler.RevokeOtherSessions)
+		apiV1.DELETE("/users/:id/sessions/:session_id", authenticated, owner, sessionHandler.RevokeSession)
+
+		apiV1.POST("/admin/users/:id/unlock", authenticated, can(domain.PermissionUsersUnlock), adminHandler.UnlockAccount)
+		apiV1.GET("/admin/roles", authenticated, can(domain.PermissionRolesRead), adminHandler.ListRoles)
+		apiV1.GET("/admin/users/:id/roles", authenticated, can(domain.PermissionRolesRead), adminHandler.ListUserRoles)
+		apiV1.PUT("/admin/users/:id/roles/:role", authenticated, can(domain.PermissionRolesWrite), adminHandler.AssignRole)
+		apiV1.DELETE("/admin/users/:id/roles/:role", authenticated, can(domain.PermissionRolesWrite), adminHandler.RevokeRole)
+
+		apiV1.POST("/organizations", authenticated, can(domain.PermissionOrganizationsWrite), organizationHandler.CreateOrganization)
+		apiV1.GET("/organizations", authenticated, can(domain.PermissionOrganizationsRead), organizationHandler.ListOrganizations)
+		apiV1.GET("/organization", authenticated, organizationHandler.GetOrganization)
+		apiV1.GET("/organization/members", authenticated, orgAdmin, organizationHandler.ListMembers)
+		apiV1.PUT("/organization/members/:id", authenticated, orgAdmin, organizationHandler.SetMemberRole)
+		apiV1.POST("/organization/invitations", authenticated, orgAdmin, invitationHandler.CreateInvitation)
+		apiV1.GET("/organization/invitations", authenticated, orgAdmin, invitationHandler.ListInvitations)
+		apiV1.DELETE("/organization/invitations/:id", authenticated, orgAdmin, invitationHandler.RevokeInvitation)
+		apiV1.POST("/invitations/accept", invitationHandler.AcceptInvitation)
+		apiV1.POST("/invitations/decline", invitationHandler.DeclineInvitation)
 	}
 
-	return router
+	return router, nil
 }
 
 func metricsHandler() gin.HandlerFunc {
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHttpRouter_ClientIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		want           string
+	}{
+		{name: "SpoofedHeader", want: "192.0.2.1"},
+		{name: "TrustedProxy", trustedProxies: []string{"192.0.2.0/24"}, want: "203.0.113.9"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authService := domain.NewMockAuthService(gomock.NewController(t))
+			router, err := NewHttpRouter(&RouterDeps{
+				Logger:         slog.Default(),
+				AuthService:    authService,
+				TrustedProxies: tt.trustedProxies,
+			})
+			require.NoError(t, err)
+
+			// The IP handed to the login is the one the lockout counts failures against.
+			var got string
+			authService.EXPECT().Login(gomock.Any(), "alice", "password123", gomock.Any()).
+				DoAndReturn(func(_ any, _, _ string, client domain.ClientInfo) (*domain.LoginResult, error) {
+					got = client.IP
+					return nil, domain.ErrInvalidCredentials
+				})
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login",
+				strings.NewReader(`{"login":"alice","password":"password123"}`))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Forwarded-For", "203.0.113.9")
+			req.RemoteAddr = "192.0.2.1:40000"
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewHttpRouter_InvalidTrustedProxy(t *testing.T) {
+	_, err := NewHttpRouter(&RouterDeps{Logger: slog.Default(), TrustedProxies: []string{"not-an-ip"}})
+	assert.Error(t, err)
+}
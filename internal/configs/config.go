@@ -3,15 +3,29 @@ package configs
 import (
 	"github.com/ilyakaznacheev/cleanenv"
 	"os"
+	"time"
 )
 
+// AppEnvDev is the APP_ENV of local development, where missing secrets fall back to ephemeral ones.
+const AppEnvDev = "dev"
+
 type Config struct {
-	AppName  string    `env:"APP_NAME" env-default:"user-service"`
-	AppEnv   string    `env:"APP_ENV" env-default:"dev"`
-	HttpPort int       `env:"HTTP_PORT" env-default:"8080"`
-	GRPCPort int       `env:"GRPC_PORT" env-default:"8081"`
-	DbUrl    string    `env:"DB_URL"`
-	Log      LogConfig `env-prefix:"LOG_" env-default:"info"`
+	AppName            string           `env:"APP_NAME" env-default:"user-service"`
+	AppEnv             string           `env:"APP_ENV" env-default:"dev"`
+	HttpPort           int              `env:"HTTP_PORT" env-default:"8080"`
+	HttpTrustedProxies []string         `env:"HTTP_TRUSTED_PROXIES"` // Comma-separated; none by default
+	GRPCPort           int              `env:"GRPC_PORT" env-default:"8081"`
+	DbUrl              string           `env:"DB_URL"`
+	Log                LogConfig        `env-prefix:"LOG_" env-default:"info"`
+	JWT                JWTConfig        `env-prefix:"JWT_"`
+	Auth               AuthConfig       `env-prefix:"AUTH_"`
+	Validation         ValidationConfig `env-prefix:"VALIDATION_"`
+	Pagination         PaginationConfig `env-prefix:"PAGINATION_"`
+	Mail               MailConfig       `env-prefix:"MAIL_"`
+	MFA                MFAConfig        `env-prefix:"MFA_"`
+	WebAuthn           WebAuthnConfig   `env-prefix:"WEBAUTHN_"`
+	Lockout            LockoutConfig    `env-prefix:"LOCKOUT_"`
+	Hash               HashConfig       `env-prefix:"PASSWORD_HASH_"`
 }
 
 type LogConfig struct {
@@ -20,6 +34,114 @@ type LogConfig struct {
 	Writer  string `env:"WRITER" env-default:"stdout"`
 }
 
+type JWTConfig struct {
+	Algorithm      string        `env:"ALGORITHM" env-default:"EdDSA"` // EdDSA, RS256 or HS256
+	Secret         string        `env:"SECRET"`                        // HS256 only
+	PrivateKeyFile string        `env:"PRIVATE_KEY_FILE"`              // PEM key for EdDSA/RS256
+	KeyID          string        `env:"KEY_ID"`
+	Issuer         string        `env:"ISSUER" env-default:"user-service"`
+	AccessTokenTTL time.Duration `env:"ACCESS_TOKEN_TTL" env-default:"15m"`
+}
+
+type AuthConfig struct {
+	RefreshTokenTTL              time.Duration `env:"REFRESH_TOKEN_TTL" env-default:"720h"`
+	PasswordResetTTL             time.Duration `env:"PASSWORD_RESET_TTL" env-default:"30m"`
+	PasswordResetURL             string        `env:"PASSWORD_RESET_URL" env-default:"http://localhost:3000/reset-password"`
+	PasswordResetLimit           int           `env:"PASSWORD_RESET_LIMIT" env-default:"3"` // Reset links sent to one account per PASSWORD_RESET_LIMIT_WINDOW
+	PasswordResetLimitWindow     time.Duration `env:"PASSWORD_RESET_LIMIT_WINDOW" env-default:"1h"`
+	EmailVerificationTTL         time.Duration `env:"EMAIL_VERIFICATION_TTL" env-default:"24h"`
+	EmailVerificationURL         string        `env:"EMAIL_VERIFICATION_URL" env-default:"http://localhost:3000/verify-email"`
+	EmailVerificationLimit       int           `env:"EMAIL_VERIFICATION_LIMIT" env-default:"3"` // Verification links sent to one email per EMAIL_VERIFICATION_LIMIT_WINDOW
+	EmailVerificationLimitWindow time.Duration `env:"EMAIL_VERIFICATION_LIMIT_WINDOW" env-default:"1h"`
+	MagicLinkTTL                 time.Duration `env:"MAGIC_LINK_TTL" env-default:"10m"`
+	MagicLinkURL                 string        `env:"MAGIC_LINK_URL" env-default:"http://localhost:3000/magic-link"`
+	MagicLinkLimit               int           `env:"MAGIC_LINK_LIMIT" env-default:"3"` // Links sent to one email per MAGIC_LINK_LIMIT_WINDOW
+	MagicLinkLimitWindow         time.Duration `env:"MAGIC_LINK_LIMIT_WINDOW" env-default:"1h"`
+	InvitationTTL                time.Duration `env:"INVITATION_TTL" env-default:"168h"`
+	InvitationURL                string        `env:"INVITATION_URL" env-default:"http://localhost:3000/invitation"`
+	RequireVerifiedEmail         bool          `env:"REQUIRE_VERIFIED_EMAIL" env-default:"false"` // Refuse logins until the email is verified
+	AdminUserIDs                 []int64       `env:"ADMIN_USER_IDS"`                             // Users granted the admin role and ownership of the default organization at startup
+}
+
+type ValidationConfig struct {
+	UsernameMinLength int    `env:"USERNAME_MIN_LENGTH" env-default:"3"`
+	UsernameMaxLength int    `env:"USERNAME_MAX_LENGTH" env-default:"32"`
+	UsernamePattern   string `env:"USERNAME_PATTERN" env-default:"^[a-zA-Z0-9._-]+$"`
+	EmailMaxLength    int    `env:"EMAIL_MAX_LENGTH" env-default:"100"`
+	PasswordMinLength int    `env:"PASSWORD_MIN_LENGTH" env-default:"8"`
+	PasswordMaxLength int    `env:"PASSWORD_MAX_LENGTH" env-default:"72"`
+
+	PasswordRequireLower   bool   `env:"PASSWORD_REQUIRE_LOWER" env-default:"false"`
+	PasswordRequireUpper   bool   `env:"PASSWORD_REQUIRE_UPPER" env-default:"false"`
+	PasswordRequireDigit   bool   `env:"PASSWORD_REQUIRE_DIGIT" env-default:"false"`
+	PasswordRequireSymbol  bool   `env:"PASSWORD_REQUIRE_SYMBOL" env-default:"false"`
+	PasswordRejectIdentity bool   `env:"PASSWORD_REJECT_IDENTITY" env-default:"true"` // No username or email inside the password
+	BreachedPasswordsFile  string `env:"BREACHED_PASSWORDS_FILE"`                     // SHA-1 digests, one per line; disabled when empty
+
+	PasswordHistorySize      int           `env:"PASSWORD_HISTORY_SIZE" env-default:"5"`          // Recent passwords, the current one included, that cannot be reused
+	PasswordHistoryRetention time.Duration `env:"PASSWORD_HISTORY_RETENTION" env-default:"8760h"` // 0 keeps replaced passwords until they fall out of the size
+}
+
+type PaginationConfig struct {
+	TokenSecret string `env:"TOKEN_SECRET"` // HMAC key signing page tokens, at least 32 bytes
+}
+
+type MailConfig struct {
+	Driver       string        `env:"DRIVER" env-default:"log"` // smtp, file or log
+	From         string        `env:"FROM" env-default:"User Service <no-reply@localhost>"`
+	Locale       string        `env:"LOCALE" env-default:"en"`
+	TemplatesDir string        `env:"TEMPLATES_DIR"`              // Replaces the built-in templates
+	Dir          string        `env:"DIR" env-default:"tmp/mail"` // Output directory of the file driver
+	SMTPHost     string        `env:"SMTP_HOST"`
+	SMTPPort     int           `env:"SMTP_PORT" env-default:"587"`
+	SMTPUsername string        `env:"SMTP_USERNAME"`
+	SMTPPassword string        `env:"SMTP_PASSWORD"`
+	SMTPTLSMode  string        `env:"SMTP_TLS_MODE" env-default:"starttls"` // starttls, tls or none
+	SMTPTimeout  time.Duration `env:"SMTP_TIMEOUT" env-default:"10s"`
+}
+
+type MFAConfig struct {
+	Issuer            string        `env:"ISSUER" env-default:"User Service"` // Shown in authenticator apps
+	EncryptionKey     string        `env:"ENCRYPTION_KEY"`                    // Base64 32-byte key encrypting TOTP secrets
+	ChallengeTTL      time.Duration `env:"CHALLENGE_TTL" env-default:"5m"`
+	MaxAttempts       int           `env:"MAX_ATTEMPTS" env-default:"5"` // Wrong codes allowed per login
+	RecoveryCodeCount int           `env:"RECOVERY_CODE_COUNT" env-default:"10"`
+}
+
+type WebAuthnConfig struct {
+	RPID          string        `env:"RP_ID" env-default:"localhost"` // Domain passkeys are bound to
+	RPDisplayName string        `env:"RP_DISPLAY_NAME" env-default:"User Service"`
+	RPOrigins     []string      `env:"RP_ORIGINS" env-default:"http://localhost:3000"` // Comma-separated
+	CeremonyTTL   time.Duration `env:"CEREMONY_TTL" env-default:"5m"`
+}
+
+type LockoutConfig struct {
+	AccountBackoffAfter int           `env:"ACCOUNT_BACKOFF_AFTER" env-default:"3"` // Failed logins before each further one delays the next
+	AccountLockAfter    int           `env:"ACCOUNT_LOCK_AFTER" env-default:"10"`
+	IPBackoffAfter      int           `env:"IP_BACKOFF_AFTER" env-default:"20"`
+	IPLockAfter         int           `env:"IP_LOCK_AFTER" env-default:"100"`
+	BackoffBase         time.Duration `env:"BACKOFF_BASE" env-default:"1s"` // Doubles with every failure
+	BackoffMax          time.Duration `env:"BACKOFF_MAX" env-default:"1m"`
+	Duration            time.Duration `env:"DURATION" env-default:"15m"`
+	ResetAfter          time.Duration `env:"RESET_AFTER" env-default:"1h"` // Failures are forgotten after this long without one
+}
+
+type HashConfig struct {
+	Algorithm         string `env:"ALGORITHM" env-default:"argon2id"`  // argon2id, bcrypt or scrypt; all three are always accepted at login
+	Argon2Memory      uint32 `env:"ARGON2_MEMORY" env-default:"32768"` // KiB
+	Argon2Iterations  uint32 `env:"ARGON2_ITERATIONS" env-default:"1"`
+	Argon2Parallelism uint8  `env:"ARGON2_PARALLELISM" env-default:"0"` // 0 uses one thread per CPU, at most 8
+	BcryptCost        int    `env:"BCRYPT_COST" env-default:"12"`
+	ScryptLogN        uint8  `env:"SCRYPT_LOG_N" env-default:"15"`
+	ScryptR           int    `env:"SCRYPT_R" env-default:"8"`
+	ScryptP           int    `env:"SCRYPT_P" env-default:"1"`
+	Workers           int    `env:"WORKERS" env-default:"4"`     // Hashes computed at once
+	QueueSize         int    `env:"QUEUE_SIZE" env-default:"32"` // Requests waiting for a worker before 429s
+	PepperKeys        string `env:"PEPPER_KEYS"`                 // Comma-separated id:base64key entries; no pepper when empty
+	PepperKeysFile    string `env:"PEPPER_KEYS_FILE"`            // Secrets file with one id:base64key per line, instead of PEPPER_KEYS
+	PepperCurrentKey  string `env:"PEPPER_CURRENT_KEY"`          // Key ID new hashes use; optional with a single key
+}
+
 // LoadConfig reads configuration from a .env file (if it exists) and environment variables.
 func LoadConfig() (Config, error) {
 	var cfg Config
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"context"
+	"slices"
+)
+
+// Principal is the authenticated caller extracted from an access token.
+type Principal struct {
+	UserID           int64
+	Username         string
+	OrganizationID   int64  // Zero in tokens issued before organizations, which means the default one
+	OrganizationRole string // Role within the organization, one of the OrganizationRole* constants
+	Roles            []string
+	Permissions      []string
+	SessionID        string
+}
+
+// HasPermission reports whether any role of the principal grants permission.
+func (p *Principal) HasPermission(permission string) bool {
+	return slices.Contains(p.Permissions, permission)
+}
+
+type principalKey struct{}
+
+// ContextWithPrincipal returns a copy of ctx carrying the principal.
+func ContextWithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the principal stored by the auth middleware, if any.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
+
+type AuthTokens struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+type AuthService interface {
+	// Login authenticates the user, starts a session and issues an access and a refresh token.
+	// Users with MFA get a challenge instead, to be completed with VerifyMFA.
+	Login(ctx context.Context, login, password string, client ClientInfo) (*LoginResult, error)
+	// VerifyMFA completes a login challenge with a TOTP or recovery code and starts the session.
+	VerifyMFA(ctx context.Context, mfaToken, code string, client ClientInfo) (*AuthTokens, error)
+	// LoginWithPasskey finishes a passkey login begun with PasskeyService.BeginLogin and starts the session.
+	LoginWithPasskey(ctx context.Context, sessionID string, response []byte, client ClientInfo) (*AuthTokens, error)
+	// LoginWithMagicLink redeems a login link and starts the session. Users with MFA get a challenge
+	// instead, as with Login.
+	LoginWithMagicLink(ctx context.Context, token string, client ClientInfo) (*LoginResult, error)
+	// Refresh rotates a refresh token. Presenting an already used token revokes its whole family.
+	Refresh(ctx context.Context, refreshToken string, client ClientInfo) (*AuthTokens, error)
+	// Logout revokes the session of the refresh token.
+	Logout(ctx context.Context, refreshToken string) error
+	// VerifyAccessToken validates a bearer token and its session and returns the principal.
+	VerifyAccessToken(ctx context.Context, token string) (*Principal, error)
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// EmailVerificationToken is a persisted, single-use email verification token.
+// It verifies Email only, so changing the address of the user invalidates it.
+type EmailVerificationToken struct {
+	ID        int64
+	UserID    int64
+	Email     string
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+type EmailVerificationService interface {
+	// SendEmailVerification sends a verification link to the owner of email unless the
+	// address is unknown or already verified. It succeeds in every one of those cases.
+	SendEmailVerification(ctx context.Context, email string) error
+	// ConfirmEmail marks the email as verified using a token from a verification link.
+	ConfirmEmail(ctx context.Context, token string) error
+}
+
+type EmailVerificationStorage interface {
+	// CreateEmailVerificationToken stores token unless limit tokens were issued for its email
+	// since the given time, and returns ErrEmailThrottled then. The count and the insert are atomic.
+	CreateEmailVerificationToken(ctx context.Context, token *EmailVerificationToken, limit int, since time.Time) (int64, error)
+	// ConsumeEmailVerificationToken atomically marks an unused, unexpired token as used and
+	// returns it, or ErrInvalidEmailVerificationToken.
+	ConsumeEmailVerificationToken(ctx context.Context, tokenHash string, now time.Time) (*EmailVerificationToken, error)
+	// DeleteUserEmailVerificationTokens invalidates every outstanding token of a user.
+	DeleteUserEmailVerificationTokens(ctx context.Context, userID int64) error
+}
@@ -1,9 +1,97 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"strings"
+)
 
 var (
 	// ErrUserNotFound will throw if the requested user is not exists
 	ErrUserNotFound          = errors.New("user not found")
 	ErrUserMailAlreadyExists = errors.New("user mail already exists")
+	ErrUsernameAlreadyExists = errors.New("username already exists")
+	ErrInvalidCredentials    = errors.New("invalid credentials")
+	ErrUnauthorized          = errors.New("unauthorized")
+	ErrForbidden             = errors.New("forbidden")
+	ErrRefreshTokenNotFound  = errors.New("refresh token not found")
+	ErrInvalidRefreshToken   = errors.New("invalid refresh token")
+	ErrRefreshTokenReused    = errors.New("refresh token reused")
+	ErrSessionNotFound       = errors.New("session not found")
+	ErrInvalidPageToken      = errors.New("invalid page token")
+	ErrIncorrectPassword     = errors.New("current password is incorrect")
+	// ErrInvalidPasswordResetToken covers unknown, expired and already used reset tokens alike.
+	ErrInvalidPasswordResetToken = errors.New("invalid password reset token")
+	// ErrInvalidEmailVerificationToken covers unknown, expired and already used verification
+	// tokens, as well as tokens issued for an email the user no longer has.
+	ErrInvalidEmailVerificationToken = errors.New("invalid email verification token")
+	ErrEmailNotVerified              = errors.New("email is not verified")
+	ErrMFAAlreadyEnabled             = errors.New("mfa is already enabled")
+	ErrMFANotEnabled                 = errors.New("mfa is not enabled")
+	ErrInvalidMFACode                = errors.New("invalid mfa code")
+	// ErrInvalidMFAChallenge covers unknown, expired and exhausted login challenges alike.
+	ErrInvalidMFAChallenge = errors.New("invalid mfa challenge")
+	ErrPasskeyNotFound     = errors.New("passkey not found")
+	ErrPasskeyExists       = errors.New("passkey is already registered")
+	// ErrInvalidPasskeySession covers unknown, expired and already finished passkey ceremonies alike.
+	ErrInvalidPasskeySession = errors.New("invalid passkey session")
+	// ErrInvalidPasskeyAttestation is returned when a new passkey fails verification.
+	ErrInvalidPasskeyAttestation = errors.New("invalid passkey attestation")
+	// ErrInvalidPasskey is returned when a passkey login fails verification.
+	ErrInvalidPasskey = errors.New("passkey verification failed")
+	// ErrInvalidMagicLinkToken covers unknown, expired and already used login links, as well as
+	// links sent to an email the user no longer has.
+	ErrInvalidMagicLinkToken = errors.New("invalid magic link token")
+	// ErrLoginLocked is returned while an account or client is locked out after failed logins,
+	// whether or not the password is right.
+	ErrLoginLocked = errors.New("too many failed login attempts, try again later")
+	// ErrRoleNotFound is returned for a role name that is not defined.
+	ErrRoleNotFound = errors.New("role not found")
+	// ErrOrganizationNotFound is returned for an unknown organization, including one named by a tenant header.
+	ErrOrganizationNotFound = errors.New("organization not found")
+	ErrOrganizationExists   = errors.New("organization already exists")
+	// ErrInvalidInvitationToken covers unknown, expired, revoked and already answered invitations alike.
+	ErrInvalidInvitationToken = errors.New("invalid invitation token")
+	ErrInvitationNotFound     = errors.New("invitation not found")
+	// ErrEmailThrottled is returned by storages when an email was sent as many mails of a kind
+	// as allowed within the window. Services drop such requests silently.
+	ErrEmailThrottled = errors.New("too many mails sent to this email")
+	// ErrServiceBusy is returned when too many requests are waiting for password hashing.
+	ErrServiceBusy = errors.New("service is busy, try again later")
 )
+
+// InvalidPasswordError is returned when the account exists but the password is wrong. It
+// matches ErrInvalidCredentials and reads the same, so clients cannot tell the two cases apart.
+type InvalidPasswordError struct {
+	UserID int64
+}
+
+func (e *InvalidPasswordError) Error() string {
+	return ErrInvalidCredentials.Error()
+}
+
+func (e *InvalidPasswordError) Is(target error) bool {
+	return target == ErrInvalidCredentials
+}
+
+// FieldViolation describes why a single request field is invalid.
+type FieldViolation struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
+
+// ValidationError is returned when one or more request fields are invalid.
+type ValidationError struct {
+	Violations []FieldViolation
+}
+
+func NewValidationError(violations ...FieldViolation) *ValidationError {
+	return &ValidationError{Violations: violations}
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		parts = append(parts, v.Field+": "+v.Description)
+	}
+	return "validation failed: " + strings.Join(parts, "; ")
+}
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Invitation statuses. Pending invitations past ExpiresAt can no longer be answered.
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationDeclined = "declined"
+	InvitationRevoked  = "revoked"
+)
+
+// Invitation asks the owner of an email to join an organization with a role.
+// Only the hash of the token sent to the invitee is stored.
+type Invitation struct {
+	ID             int64      `json:"id"`
+	OrganizationID int64      `json:"organization_id"`
+	Email          string     `json:"email"`
+	Role           string     `json:"role"`
+	InviterID      int64      `json:"inviter_id,omitempty"` // Zero once the inviter is deleted
+	Status         string     `json:"status"`
+	TokenHash      string     `json:"-"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	RespondedAt    *time.Time `json:"responded_at,omitempty"` // Set when accepted, declined or revoked
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// AcceptInvitationRequest answers an invitation. Username and Password are only used, and then
+// required, when the invited email has no account yet.
+type AcceptInvitationRequest struct {
+	Token    string
+	Username string
+	Password string
+}
+
+type InvitationService interface {
+	// CreateInvitation invites email to the organization the request acts in and sends them a link.
+	// Only owners may invite owners.
+	CreateInvitation(ctx context.Context, email, role string) (*Invitation, error)
+	// ListInvitations lists the invitations of the organization the request acts in, newest first.
+	ListInvitations(ctx context.Context) ([]*Invitation, error)
+	// RevokeInvitation withdraws a pending invitation of the organization the request acts in.
+	RevokeInvitation(ctx context.Context, id int64) error
+	// AcceptInvitation joins the invitee to the organization with the invited role. An existing
+	// account with the invited email is linked; otherwise a new account is created for it.
+	// Either way the email counts as verified, since the link was delivered to it.
+	AcceptInvitation(ctx context.Context, req AcceptInvitationRequest) (*UserResponse, error)
+	DeclineInvitation(ctx context.Context, token string) error
+}
+
+type InvitationStorage interface {
+	CreateInvitation(ctx context.Context, invitation *Invitation) (int64, error)
+	ListInvitations(ctx context.Context, orgID int64) ([]*Invitation, error)
+	// GetPendingInvitation returns a pending, unexpired invitation without answering it,
+	// or ErrInvalidInvitationToken.
+	GetPendingInvitation(ctx context.Context, tokenHash string, now time.Time) (*Invitation, error)
+	// RespondToInvitation atomically moves a pending, unexpired invitation to status and
+	// returns it, or ErrInvalidInvitationToken.
+	RespondToInvitation(ctx context.Context, tokenHash, status string, now time.Time) (*Invitation, error)
+	// RevokeInvitation returns ErrInvitationNotFound unless the invitation is pending in the organization.
+	RevokeInvitation(ctx context.Context, orgID, id int64, now time.Time) error
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// LoginScope is what failed login attempts are counted against.
+type LoginScope string
+
+const (
+	LoginScopeAccount LoginScope = "account"
+	LoginScopeIP      LoginScope = "ip"
+)
+
+// LoginSubject is an account or a client address whose failed logins are tracked.
+type LoginSubject struct {
+	Scope LoginScope
+	Key   string
+}
+
+func AccountSubject(userID int64) LoginSubject {
+	return LoginSubject{Scope: LoginScopeAccount, Key: strconv.FormatInt(userID, 10)}
+}
+
+func IPSubject(ip string) LoginSubject {
+	return LoginSubject{Scope: LoginScopeIP, Key: ip}
+}
+
+// LoginFailures is the persisted count of recent failed logins of a subject.
+type LoginFailures struct {
+	Subject       LoginSubject
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   *time.Time // Logins are refused until then
+}
+
+type LockoutService interface {
+	// Check returns ErrLoginLocked while any of the subjects is locked.
+	Check(ctx context.Context, subjects ...LoginSubject) error
+	// RecordFailure counts a failed login against every subject and locks those over their threshold.
+	RecordFailure(ctx context.Context, subjects ...LoginSubject) error
+	// RecordSuccess forgets the failed logins of a subject.
+	RecordSuccess(ctx context.Context, subject LoginSubject) error
+	// UnlockAccount lifts the lock of a user and forgets their failed logins.
+	UnlockAccount(ctx context.Context, userID int64) error
+}
+
+type LockoutStorage interface {
+	// GetLoginFailures returns the failures of those subjects that have any.
+	GetLoginFailures(ctx context.Context, subjects []LoginSubject) ([]*LoginFailures, error)
+	// RecordLoginFailure atomically counts a failure at now and returns the new count. Failures
+	// are counted from one again when the previous one happened before resetBefore.
+	RecordLoginFailure(ctx context.Context, subject LoginSubject, now, resetBefore time.Time) (int, error)
+	// LockLoginSubject refuses logins of the subject until the given time.
+	LockLoginSubject(ctx context.Context, subject LoginSubject, until time.Time) error
+	// DeleteLoginFailures forgets the failures and lock of a subject.
+	DeleteLoginFailures(ctx context.Context, subject LoginSubject) error
+}
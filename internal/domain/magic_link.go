@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// MagicLinkToken is a persisted, single-use login token sent by email.
+// It is bound to Email, so changing the address of the user invalidates it.
+type MagicLinkToken struct {
+	ID        int64
+	UserID    int64
+	Email     string
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+type MagicLinkService interface {
+	// RequestMagicLink sends a login link to the owner of email, if any. It succeeds whether
+	// or not the email is registered and also when the email is throttled.
+	RequestMagicLink(ctx context.Context, email string) error
+	// ConsumeMagicLink redeems the token from a login link and returns its user. Following the
+	// link proves the user owns the email, so an unverified email becomes verified.
+	ConsumeMagicLink(ctx context.Context, token string) (*UserResponse, error)
+}
+
+type MagicLinkStorage interface {
+	// CreateMagicLinkToken stores token unless limit tokens were issued for its email since the
+	// given time, and returns ErrEmailThrottled then. The count and the insert are atomic.
+	CreateMagicLinkToken(ctx context.Context, token *MagicLinkToken, limit int, since time.Time) (int64, error)
+	// ConsumeMagicLinkToken atomically marks an unused, unexpired token as used and
+	// returns it, or ErrInvalidMagicLinkToken.
+	ConsumeMagicLinkToken(ctx context.Context, tokenHash string, now time.Time) (*MagicLinkToken, error)
+	// InvalidateUserMagicLinkTokens marks every outstanding token of a user as used at now.
+	// Tokens stay until prunedBefore, their creation time, since they count towards the send limit.
+	InvalidateUserMagicLinkTokens(ctx context.Context, userID int64, now, prunedBefore time.Time) error
+}
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// TOTPEnrollment is what a user needs to add the service to an authenticator app.
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`      // Base32 secret for manual entry
+	URI    string `json:"otpauth_uri"` // otpauth:// key URI
+	QRCode []byte `json:"qr_code_png"` // PNG image of URI, base64-encoded in JSON
+}
+
+// MFAChallenge is returned by a login that needs a second factor. Its token is
+// exchanged for access tokens together with a TOTP or recovery code.
+type MFAChallenge struct {
+	Token     string `json:"mfa_token"`
+	ExpiresIn int64  `json:"expires_in"`
+}
+
+// LoginResult holds either the tokens of a new session or, for users with MFA, a challenge.
+type LoginResult struct {
+	Tokens       *AuthTokens
+	MFAChallenge *MFAChallenge
+}
+
+// TOTPCredential is the stored TOTP secret of a user. It is pending until EnabledAt is set.
+type TOTPCredential struct {
+	UserID          int64
+	SecretEncrypted string
+	EnabledAt       *time.Time
+	LastUsedStep    int64 // Time step of the last accepted code, which cannot be used again
+	CreatedAt       time.Time
+}
+
+// MFAChallengeRecord is a persisted login challenge; only the hash of its token is stored.
+type MFAChallengeRecord struct {
+	ID        int64
+	UserID    int64
+	TokenHash string
+	ExpiresAt time.Time
+	Attempts  int
+}
+
+type MFAService interface {
+	// EnrollTOTP starts or restarts TOTP enrollment with a new secret. MFA stays off
+	// until ConfirmTOTP; ErrMFAAlreadyEnabled is returned if it is on already.
+	EnrollTOTP(ctx context.Context, userID int64) (*TOTPEnrollment, error)
+	// ConfirmTOTP turns MFA on once the user proves the app works and returns the recovery codes.
+	// They are shown only this once.
+	ConfirmTOTP(ctx context.Context, userID int64, code string) ([]string, error)
+	// DisableMFA turns MFA off given a current TOTP or recovery code.
+	DisableMFA(ctx context.Context, userID int64, code string) error
+	// RegenerateRecoveryCodes replaces every recovery code given a current TOTP or recovery code.
+	RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) ([]string, error)
+	// StartChallenge returns a login challenge if the user has MFA on, or nil otherwise.
+	StartChallenge(ctx context.Context, userID int64) (*MFAChallenge, error)
+	// CompleteChallenge checks a TOTP or recovery code for the challenge, consumes it
+	// and returns the ID of its user.
+	CompleteChallenge(ctx context.Context, token, code string) (int64, error)
+}
+
+type MFAStorage interface {
+	// GetTOTPCredential returns ErrMFANotEnabled if the user never enrolled.
+	GetTOTPCredential(ctx context.Context, userID int64) (*TOTPCredential, error)
+	// SavePendingTOTPCredential creates or replaces a pending credential and
+	// returns ErrMFAAlreadyEnabled if an enabled one exists.
+	SavePendingTOTPCredential(ctx context.Context, credential *TOTPCredential) error
+	// EnableTOTPCredential enables a pending credential, records the step of the confirming
+	// code and replaces the recovery codes, atomically.
+	EnableTOTPCredential(ctx context.Context, userID int64, step int64, recoveryCodeHashes []string, now time.Time) error
+	// UseTOTPStep records an accepted code and returns ErrInvalidMFACode if its step
+	// is not newer than the last one used.
+	UseTOTPStep(ctx context.Context, userID int64, step int64) error
+	// DeleteMFA removes the credential and recovery codes of a user.
+	DeleteMFA(ctx context.Context, userID int64) error
+
+	ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error
+	// UseRecoveryCode marks an unused code as used and returns ErrInvalidMFACode otherwise.
+	UseRecoveryCode(ctx context.Context, userID int64, codeHash string, now time.Time) error
+
+	CreateMFAChallenge(ctx context.Context, challenge *MFAChallengeRecord) (int64, error)
+	// GetMFAChallenge returns ErrInvalidMFAChallenge for unknown tokens.
+	GetMFAChallenge(ctx context.Context, tokenHash string) (*MFAChallengeRecord, error)
+	// CountMFAChallengeAttempt records an attempt at a challenge unless maxAttempts were made
+	// already, in one step so that concurrent attempts cannot exceed it, and returns
+	// ErrInvalidMFAChallenge then or if the challenge is gone.
+	CountMFAChallengeAttempt(ctx context.Context, id int64, maxAttempts int) error
+	// DeleteMFAChallenge consumes a challenge and returns ErrInvalidMFAChallenge if it is already gone.
+	DeleteMFAChallenge(ctx context.Context, id int64) error
+}
@@ -0,0 +1,131 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/email_verification.go
+
+// Package domain is a generated GoMock package.
+package domain
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockEmailVerificationService is a mock of EmailVerificationService interface.
+type MockEmailVerificationService struct {
+	ctrl     *gomock.Controller
+	recorder *MockEmailVerificationServiceMockRecorder
+}
+
+// MockEmailVerificationServiceMockRecorder is the mock recorder for MockEmailVerificationService.
+type MockEmailVerificationServiceMockRecorder struct {
+	mock *MockEmailVerificationService
+}
+
+// NewMockEmailVerificationService creates a new mock instance.
+func NewMockEmailVerificationService(ctrl *gomock.Controller) *MockEmailVerificationService {
+	mock := &MockEmailVerificationService{ctrl: ctrl}
+	mock.recorder = &MockEmailVerificationServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEmailVerificationService) EXPECT() *MockEmailVerificationServiceMockRecorder {
+	return m.recorder
+}
+
+// ConfirmEmail mocks base method.
+func (m *MockEmailVerificationService) ConfirmEmail(ctx context.Context, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmEmail", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfirmEmail indicates an expected call of ConfirmEmail.
+func (mr *MockEmailVerificationServiceMockRecorder) ConfirmEmail(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmEmail", reflect.TypeOf((*MockEmailVerificationService)(nil).ConfirmEmail), ctx, token)
+}
+
+// SendEmailVerification mocks base method.
+func (m *MockEmailVerificationService) SendEmailVerification(ctx context.Context, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendEmailVerification", ctx, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendEmailVerification indicates an expected call of SendEmailVerification.
+func (mr *MockEmailVerificationServiceMockRecorder) SendEmailVerification(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendEmailVerification", reflect.TypeOf((*MockEmailVerificationService)(nil).SendEmailVerification), ctx, email)
+}
+
+// MockEmailVerificationStorage is a mock of EmailVerificationStorage interface.
+type MockEmailVerificationStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockEmailVerificationStorageMockRecorder
+}
+
+// MockEmailVerificationStorageMockRecorder is the mock recorder for MockEmailVerificationStorage.
+type MockEmailVerificationStorageMockRecorder struct {
+	mock *MockEmailVerificationStorage
+}
+
+// NewMockEmailVerificationStorage creates a new mock instance.
+func NewMockEmailVerificationStorage(ctrl *gomock.Controller) *MockEmailVerificationStorage {
+	mock := &MockEmailVerificationStorage{ctrl: ctrl}
+	mock.recorder = &MockEmailVerificationStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEmailVerificationStorage) EXPECT() *MockEmailVerificationStorageMockRecorder {
+	return m.recorder
+}
+
+// ConsumeEmailVerificationToken mocks base method.
+func (m *MockEmailVerificationStorage) ConsumeEmailVerificationToken(ctx context.Context, tokenHash string, now time.Time) (*EmailVerificationToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeEmailVerificationToken", ctx, tokenHash, now)
+	ret0, _ := ret[0].(*EmailVerificationToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeEmailVerificationToken indicates an expected call of ConsumeEmailVerificationToken.
+func (mr *MockEmailVerificationStorageMockRecorder) ConsumeEmailVerificationToken(ctx, tokenHash, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeEmailVerificationToken", reflect.TypeOf((*MockEmailVerificationStorage)(nil).ConsumeEmailVerificationToken), ctx, tokenHash, now)
+}
+
+// CreateEmailVerificationToken mocks base method.
+func (m *MockEmailVerificationStorage) CreateEmailVerificationToken(ctx context.Context, token *EmailVerificationToken, limit int, since time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEmailVerificationToken", ctx, token, limit, since)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateEmailVerificationToken indicates an expected call of CreateEmailVerificationToken.
+func (mr *MockEmailVerificationStorageMockRecorder) CreateEmailVerificationToken(ctx, token, limit, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEmailVerificationToken", reflect.TypeOf((*MockEmailVerificationStorage)(nil).CreateEmailVerificationToken), ctx, token, limit, since)
+}
+
+// DeleteUserEmailVerificationTokens mocks base method.
+func (m *MockEmailVerificationStorage) DeleteUserEmailVerificationTokens(ctx context.Context, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserEmailVerificationTokens", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserEmailVerificationTokens indicates an expected call of DeleteUserEmailVerificationTokens.
+func (mr *MockEmailVerificationStorageMockRecorder) DeleteUserEmailVerificationTokens(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserEmailVerificationTokens", reflect.TypeOf((*MockEmailVerificationStorage)(nil).DeleteUserEmailVerificationTokens), ctx, userID)
+}
@@ -0,0 +1,206 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/invitation.go
+
+// Package domain is a generated GoMock package.
+package domain
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockInvitationService is a mock of InvitationService interface.
+type MockInvitationService struct {
+	ctrl     *gomock.Controller
+	recorder *MockInvitationServiceMockRecorder
+}
+
+// MockInvitationServiceMockRecorder is the mock recorder for MockInvitationService.
+type MockInvitationServiceMockRecorder struct {
+	mock *MockInvitationService
+}
+
+// NewMockInvitationService creates a new mock instance.
+func NewMockInvitationService(ctrl *gomock.Controller) *MockInvitationService {
+	mock := &MockInvitationService{ctrl: ctrl}
+	mock.recorder = &MockInvitationServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInvitationService) EXPECT() *MockInvitationServiceMockRecorder {
+	return m.recorder
+}
+
+// AcceptInvitation mocks base method.
+func (m *MockInvitationService) AcceptInvitation(ctx context.Context, req AcceptInvitationRequest) (*UserResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptInvitation", ctx, req)
+	ret0, _ := ret[0].(*UserResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptInvitation indicates an expected call of AcceptInvitation.
+func (mr *MockInvitationServiceMockRecorder) AcceptInvitation(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptInvitation", reflect.TypeOf((*MockInvitationService)(nil).AcceptInvitation), ctx, req)
+}
+
+// CreateInvitation mocks base method.
+func (m *MockInvitationService) CreateInvitation(ctx context.Context, email, role string) (*Invitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateInvitation", ctx, email, role)
+	ret0, _ := ret[0].(*Invitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateInvitation indicates an expected call of CreateInvitation.
+func (mr *MockInvitationServiceMockRecorder) CreateInvitation(ctx, email, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInvitation", reflect.TypeOf((*MockInvitationService)(nil).CreateInvitation), ctx, email, role)
+}
+
+// DeclineInvitation mocks base method.
+func (m *MockInvitationService) DeclineInvitation(ctx context.Context, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeclineInvitation", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeclineInvitation indicates an expected call of DeclineInvitation.
+func (mr *MockInvitationServiceMockRecorder) DeclineInvitation(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeclineInvitation", reflect.TypeOf((*MockInvitationService)(nil).DeclineInvitation), ctx, token)
+}
+
+// ListInvitations mocks base method.
+func (m *MockInvitationService) ListInvitations(ctx context.Context) ([]*Invitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInvitations", ctx)
+	ret0, _ := ret[0].([]*Invitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInvitations indicates an expected call of ListInvitations.
+func (mr *MockInvitationServiceMockRecorder) ListInvitations(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInvitations", reflect.TypeOf((*MockInvitationService)(nil).ListInvitations), ctx)
+}
+
+// RevokeInvitation mocks base method.
+func (m *MockInvitationService) RevokeInvitation(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeInvitation", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeInvitation indicates an expected call of RevokeInvitation.
+func (mr *MockInvitationServiceMockRecorder) RevokeInvitation(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeInvitation", reflect.TypeOf((*MockInvitationService)(nil).RevokeInvitation), ctx, id)
+}
+
+// MockInvitationStorage is a mock of InvitationStorage interface.
+type MockInvitationStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockInvitationStorageMockRecorder
+}
+
+// MockInvitationStorageMockRecorder is the mock recorder for MockInvitationStorage.
+type MockInvitationStorageMockRecorder struct {
+	mock *MockInvitationStorage
+}
+
+// NewMockInvitationStorage creates a new mock instance.
+func NewMockInvitationStorage(ctrl *gomock.Controller) *MockInvitationStorage {
+	mock := &MockInvitationStorage{ctrl: ctrl}
+	mock.recorder = &MockInvitationStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInvitationStorage) EXPECT() *MockInvitationStorageMockRecorder {
+	return m.recorder
+}
+
+// CreateInvitation mocks base method.
+func (m *MockInvitationStorage) CreateInvitation(ctx context.Context, invitation *Invitation) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateInvitation", ctx, invitation)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateInvitation indicates an expected call of CreateInvitation.
+func (mr *MockInvitationStorageMockRecorder) CreateInvitation(ctx, invitation interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInvitation", reflect.TypeOf((*MockInvitationStorage)(nil).CreateInvitation), ctx, invitation)
+}
+
+// GetPendingInvitation mocks base method.
+func (m *MockInvitationStorage) GetPendingInvitation(ctx context.Context, tokenHash string, now time.Time) (*Invitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingInvitation", ctx, tokenHash, now)
+	ret0, _ := ret[0].(*Invitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingInvitation indicates an expected call of GetPendingInvitation.
+func (mr *MockInvitationStorageMockRecorder) GetPendingInvitation(ctx, tokenHash, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingInvitation", reflect.TypeOf((*MockInvitationStorage)(nil).GetPendingInvitation), ctx, tokenHash, now)
+}
+
+// ListInvitations mocks base method.
+func (m *MockInvitationStorage) ListInvitations(ctx context.Context, orgID int64) ([]*Invitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInvitations", ctx, orgID)
+	ret0, _ := ret[0].([]*Invitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInvitations indicates an expected call of ListInvitations.
+func (mr *MockInvitationStorageMockRecorder) ListInvitations(ctx, orgID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInvitations", reflect.TypeOf((*MockInvitationStorage)(nil).ListInvitations), ctx, orgID)
+}
+
+// RespondToInvitation mocks base method.
+func (m *MockInvitationStorage) RespondToInvitation(ctx context.Context, tokenHash, status string, now time.Time) (*Invitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RespondToInvitation", ctx, tokenHash, status, now)
+	ret0, _ := ret[0].(*Invitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RespondToInvitation indicates an expected call of RespondToInvitation.
+func (mr *MockInvitationStorageMockRecorder) RespondToInvitation(ctx, tokenHash, status, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RespondToInvitation", reflect.TypeOf((*MockInvitationStorage)(nil).RespondToInvitation), ctx, tokenHash, status, now)
+}
+
+// RevokeInvitation mocks base method.
+func (m *MockInvitationStorage) RevokeInvitation(ctx context.Context, orgID, id int64, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeInvitation", ctx, orgID, id, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeInvitation indicates an expected call of RevokeInvitation.
+func (mr *MockInvitationStorageMockRecorder) RevokeInvitation(ctx, orgID, id, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeInvitation", reflect.TypeOf((*MockInvitationStorage)(nil).RevokeInvitation), ctx, orgID, id, now)
+}
@@ -0,0 +1,183 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/lockout.go
+
+// Package domain is a generated GoMock package.
+package domain
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockLockoutService is a mock of LockoutService interface.
+type MockLockoutService struct {
+	ctrl     *gomock.Controller
+	recorder *MockLockoutServiceMockRecorder
+}
+
+// MockLockoutServiceMockRecorder is the mock recorder for MockLockoutService.
+type MockLockoutServiceMockRecorder struct {
+	mock *MockLockoutService
+}
+
+// NewMockLockoutService creates a new mock instance.
+func NewMockLockoutService(ctrl *gomock.Controller) *MockLockoutService {
+	mock := &MockLockoutService{ctrl: ctrl}
+	mock.recorder = &MockLockoutServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLockoutService) EXPECT() *MockLockoutServiceMockRecorder {
+	return m.recorder
+}
+
+// Check mocks base method.
+func (m *MockLockoutService) Check(ctx context.Context, subjects ...LoginSubject) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range subjects {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Check", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Check indicates an expected call of Check.
+func (mr *MockLockoutServiceMockRecorder) Check(ctx interface{}, subjects ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, subjects...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockLockoutService)(nil).Check), varargs...)
+}
+
+// RecordFailure mocks base method.
+func (m *MockLockoutService) RecordFailure(ctx context.Context, subjects ...LoginSubject) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range subjects {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RecordFailure", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordFailure indicates an expected call of RecordFailure.
+func (mr *MockLockoutServiceMockRecorder) RecordFailure(ctx interface{}, subjects ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, subjects...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordFailure", reflect.TypeOf((*MockLockoutService)(nil).RecordFailure), varargs...)
+}
+
+// RecordSuccess mocks base method.
+func (m *MockLockoutService) RecordSuccess(ctx context.Context, subject LoginSubject) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordSuccess", ctx, subject)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordSuccess indicates an expected call of RecordSuccess.
+func (mr *MockLockoutServiceMockRecorder) RecordSuccess(ctx, subject interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordSuccess", reflect.TypeOf((*MockLockoutService)(nil).RecordSuccess), ctx, subject)
+}
+
+// UnlockAccount mocks base method.
+func (m *MockLockoutService) UnlockAccount(ctx context.Context, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnlockAccount", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnlockAccount indicates an expected call of UnlockAccount.
+func (mr *MockLockoutServiceMockRecorder) UnlockAccount(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnlockAccount", reflect.TypeOf((*MockLockoutService)(nil).UnlockAccount), ctx, userID)
+}
+
+// MockLockoutStorage is a mock of LockoutStorage interface.
+type MockLockoutStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockLockoutStorageMockRecorder
+}
+
+// MockLockoutStorageMockRecorder is the mock recorder for MockLockoutStorage.
+type MockLockoutStorageMockRecorder struct {
+	mock *MockLockoutStorage
+}
+
+// NewMockLockoutStorage creates a new mock instance.
+func NewMockLockoutStorage(ctrl *gomock.Controller) *MockLockoutStorage {
+	mock := &MockLockoutStorage{ctrl: ctrl}
+	mock.recorder = &MockLockoutStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLockoutStorage) EXPECT() *MockLockoutStorageMockRecorder {
+	return m.recorder
+}
+
+// DeleteLoginFailures mocks base method.
+func (m *MockLockoutStorage) DeleteLoginFailures(ctx context.Context, subject LoginSubject) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteLoginFailures", ctx, subject)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteLoginFailures indicates an expected call of DeleteLoginFailures.
+func (mr *MockLockoutStorageMockRecorder) DeleteLoginFailures(ctx, subject interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLoginFailures", reflect.TypeOf((*MockLockoutStorage)(nil).DeleteLoginFailures), ctx, subject)
+}
+
+// GetLoginFailures mocks base method.
+func (m *MockLockoutStorage) GetLoginFailures(ctx context.Context, subjects []LoginSubject) ([]*LoginFailures, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginFailures", ctx, subjects)
+	ret0, _ := ret[0].([]*LoginFailures)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginFailures indicates an expected call of GetLoginFailures.
+func (mr *MockLockoutStorageMockRecorder) GetLoginFailures(ctx, subjects interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginFailures", reflect.TypeOf((*MockLockoutStorage)(nil).GetLoginFailures), ctx, subjects)
+}
+
+// LockLoginSubject mocks base method.
+func (m *MockLockoutStorage) LockLoginSubject(ctx context.Context, subject LoginSubject, until time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockLoginSubject", ctx, subject, until)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockLoginSubject indicates an expected call of LockLoginSubject.
+func (mr *MockLockoutStorageMockRecorder) LockLoginSubject(ctx, subject, until interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockLoginSubject", reflect.TypeOf((*MockLockoutStorage)(nil).LockLoginSubject), ctx, subject, until)
+}
+
+// RecordLoginFailure mocks base method.
+func (m *MockLockoutStorage) RecordLoginFailure(ctx context.Context, subject LoginSubject, now, resetBefore time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordLoginFailure", ctx, subject, now, resetBefore)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordLoginFailure indicates an expected call of RecordLoginFailure.
+func (mr *MockLockoutStorageMockRecorder) RecordLoginFailure(ctx, subject, now, resetBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordLoginFailure", reflect.TypeOf((*MockLockoutStorage)(nil).RecordLoginFailure), ctx, subject, now, resetBefore)
+}
@@ -0,0 +1,132 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/magic_link.go
+
+// Package domain is a generated GoMock package.
+package domain
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockMagicLinkService is a mock of MagicLinkService interface.
+type MockMagicLinkService struct {
+	ctrl     *gomock.Controller
+	recorder *MockMagicLinkServiceMockRecorder
+}
+
+// MockMagicLinkServiceMockRecorder is the mock recorder for MockMagicLinkService.
+type MockMagicLinkServiceMockRecorder struct {
+	mock *MockMagicLinkService
+}
+
+// NewMockMagicLinkService creates a new mock instance.
+func NewMockMagicLinkService(ctrl *gomock.Controller) *MockMagicLinkService {
+	mock := &MockMagicLinkService{ctrl: ctrl}
+	mock.recorder = &MockMagicLinkServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMagicLinkService) EXPECT() *MockMagicLinkServiceMockRecorder {
+	return m.recorder
+}
+
+// ConsumeMagicLink mocks base method.
+func (m *MockMagicLinkService) ConsumeMagicLink(ctx context.Context, token string) (*UserResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeMagicLink", ctx, token)
+	ret0, _ := ret[0].(*UserResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeMagicLink indicates an expected call of ConsumeMagicLink.
+func (mr *MockMagicLinkServiceMockRecorder) ConsumeMagicLink(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeMagicLink", reflect.TypeOf((*MockMagicLinkService)(nil).ConsumeMagicLink), ctx, token)
+}
+
+// RequestMagicLink mocks base method.
+func (m *MockMagicLinkService) RequestMagicLink(ctx context.Context, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestMagicLink", ctx, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestMagicLink indicates an expected call of RequestMagicLink.
+func (mr *MockMagicLinkServiceMockRecorder) RequestMagicLink(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestMagicLink", reflect.TypeOf((*MockMagicLinkService)(nil).RequestMagicLink), ctx, email)
+}
+
+// MockMagicLinkStorage is a mock of MagicLinkStorage interface.
+type MockMagicLinkStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockMagicLinkStorageMockRecorder
+}
+
+// MockMagicLinkStorageMockRecorder is the mock recorder for MockMagicLinkStorage.
+type MockMagicLinkStorageMockRecorder struct {
+	mock *MockMagicLinkStorage
+}
+
+// NewMockMagicLinkStorage creates a new mock instance.
+func NewMockMagicLinkStorage(ctrl *gomock.Controller) *MockMagicLinkStorage {
+	mock := &MockMagicLinkStorage{ctrl: ctrl}
+	mock.recorder = &MockMagicLinkStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMagicLinkStorage) EXPECT() *MockMagicLinkStorageMockRecorder {
+	return m.recorder
+}
+
+// ConsumeMagicLinkToken mocks base method.
+func (m *MockMagicLinkStorage) ConsumeMagicLinkToken(ctx context.Context, tokenHash string, now time.Time) (*MagicLinkToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeMagicLinkToken", ctx, tokenHash, now)
+	ret0, _ := ret[0].(*MagicLinkToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeMagicLinkToken indicates an expected call of ConsumeMagicLinkToken.
+func (mr *MockMagicLinkStorageMockRecorder) ConsumeMagicLinkToken(ctx, tokenHash, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeMagicLinkToken", reflect.TypeOf((*MockMagicLinkStorage)(nil).ConsumeMagicLinkToken), ctx, tokenHash, now)
+}
+
+// CreateMagicLinkToken mocks base method.
+func (m *MockMagicLinkStorage) CreateMagicLinkToken(ctx context.Context, token *MagicLinkToken, limit int, since time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMagicLinkToken", ctx, token, limit, since)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateMagicLinkToken indicates an expected call of CreateMagicLinkToken.
+func (mr *MockMagicLinkStorageMockRecorder) CreateMagicLinkToken(ctx, token, limit, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMagicLinkToken", reflect.TypeOf((*MockMagicLinkStorage)(nil).CreateMagicLinkToken), ctx, token, limit, since)
+}
+
+// InvalidateUserMagicLinkTokens mocks base method.
+func (m *MockMagicLinkStorage) InvalidateUserMagicLinkTokens(ctx context.Context, userID int64, now, prunedBefore time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InvalidateUserMagicLinkTokens", ctx, userID, now, prunedBefore)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InvalidateUserMagicLinkTokens indicates an expected call of InvalidateUserMagicLinkTokens.
+func (mr *MockMagicLinkStorageMockRecorder) InvalidateUserMagicLinkTokens(ctx, userID, now, prunedBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateUserMagicLinkTokens", reflect.TypeOf((*MockMagicLinkStorage)(nil).InvalidateUserMagicLinkTokens), ctx, userID, now, prunedBefore)
+}
@@ -0,0 +1,305 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/mfa.go
+
+// Package domain is a generated GoMock package.
+package domain
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockMFAService is a mock of MFAService interface.
+type MockMFAService struct {
+	ctrl     *gomock.Controller
+	recorder *MockMFAServiceMockRecorder
+}
+
+// MockMFAServiceMockRecorder is the mock recorder for MockMFAService.
+type MockMFAServiceMockRecorder struct {
+	mock *MockMFAService
+}
+
+// NewMockMFAService creates a new mock instance.
+func NewMockMFAService(ctrl *gomock.Controller) *MockMFAService {
+	mock := &MockMFAService{ctrl: ctrl}
+	mock.recorder = &MockMFAServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMFAService) EXPECT() *MockMFAServiceMockRecorder {
+	return m.recorder
+}
+
+// CompleteChallenge mocks base method.
+func (m *MockMFAService) CompleteChallenge(ctx context.Context, token, code string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteChallenge", ctx, token, code)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteChallenge indicates an expected call of CompleteChallenge.
+func (mr *MockMFAServiceMockRecorder) CompleteChallenge(ctx, token, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteChallenge", reflect.TypeOf((*MockMFAService)(nil).CompleteChallenge), ctx, token, code)
+}
+
+// ConfirmTOTP mocks base method.
+func (m *MockMFAService) ConfirmTOTP(ctx context.Context, userID int64, code string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmTOTP", ctx, userID, code)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmTOTP indicates an expected call of ConfirmTOTP.
+func (mr *MockMFAServiceMockRecorder) ConfirmTOTP(ctx, userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTOTP", reflect.TypeOf((*MockMFAService)(nil).ConfirmTOTP), ctx, userID, code)
+}
+
+// DisableMFA mocks base method.
+func (m *MockMFAService) DisableMFA(ctx context.Context, userID int64, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableMFA", ctx, userID, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableMFA indicates an expected call of DisableMFA.
+func (mr *MockMFAServiceMockRecorder) DisableMFA(ctx, userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableMFA", reflect.TypeOf((*MockMFAService)(nil).DisableMFA), ctx, userID, code)
+}
+
+// EnrollTOTP mocks base method.
+func (m *MockMFAService) EnrollTOTP(ctx context.Context, userID int64) (*TOTPEnrollment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnrollTOTP", ctx, userID)
+	ret0, _ := ret[0].(*TOTPEnrollment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnrollTOTP indicates an expected call of EnrollTOTP.
+func (mr *MockMFAServiceMockRecorder) EnrollTOTP(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnrollTOTP", reflect.TypeOf((*MockMFAService)(nil).EnrollTOTP), ctx, userID)
+}
+
+// RegenerateRecoveryCodes mocks base method.
+func (m *MockMFAService) RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegenerateRecoveryCodes", ctx, userID, code)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RegenerateRecoveryCodes indicates an expected call of RegenerateRecoveryCodes.
+func (mr *MockMFAServiceMockRecorder) RegenerateRecoveryCodes(ctx, userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegenerateRecoveryCodes", reflect.TypeOf((*MockMFAService)(nil).RegenerateRecoveryCodes), ctx, userID, code)
+}
+
+// StartChallenge mocks base method.
+func (m *MockMFAService) StartChallenge(ctx context.Context, userID int64) (*MFAChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartChallenge", ctx, userID)
+	ret0, _ := ret[0].(*MFAChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartChallenge indicates an expected call of StartChallenge.
+func (mr *MockMFAServiceMockRecorder) StartChallenge(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartChallenge", reflect.TypeOf((*MockMFAService)(nil).StartChallenge), ctx, userID)
+}
+
+// MockMFAStorage is a mock of MFAStorage interface.
+type MockMFAStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockMFAStorageMockRecorder
+}
+
+// MockMFAStorageMockRecorder is the mock recorder for MockMFAStorage.
+type MockMFAStorageMockRecorder struct {
+	mock *MockMFAStorage
+}
+
+// NewMockMFAStorage creates a new mock instance.
+func NewMockMFAStorage(ctrl *gomock.Controller) *MockMFAStorage {
+	mock := &MockMFAStorage{ctrl: ctrl}
+	mock.recorder = &MockMFAStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMFAStorage) EXPECT() *MockMFAStorageMockRecorder {
+	return m.recorder
+}
+
+// CountMFAChallengeAttempt mocks base method.
+func (m *MockMFAStorage) CountMFAChallengeAttempt(ctx context.Context, id int64, maxAttempts int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountMFAChallengeAttempt", ctx, id, maxAttempts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CountMFAChallengeAttempt indicates an expected call of CountMFAChallengeAttempt.
+func (mr *MockMFAStorageMockRecorder) CountMFAChallengeAttempt(ctx, id, maxAttempts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountMFAChallengeAttempt", reflect.TypeOf((*MockMFAStorage)(nil).CountMFAChallengeAttempt), ctx, id, maxAttempts)
+}
+
+// CreateMFAChallenge mocks base method.
+func (m *MockMFAStorage) CreateMFAChallenge(ctx context.Context, challenge *MFAChallengeRecord) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMFAChallenge", ctx, challenge)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateMFAChallenge indicates an expected call of CreateMFAChallenge.
+func (mr *MockMFAStorageMockRecorder) CreateMFAChallenge(ctx, challenge interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMFAChallenge", reflect.TypeOf((*MockMFAStorage)(nil).CreateMFAChallenge), ctx, challenge)
+}
+
+// DeleteMFA mocks base method.
+func (m *MockMFAStorage) DeleteMFA(ctx context.Context, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMFA", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteMFA indicates an expected call of DeleteMFA.
+func (mr *MockMFAStorageMockRecorder) DeleteMFA(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMFA", reflect.TypeOf((*MockMFAStorage)(nil).DeleteMFA), ctx, userID)
+}
+
+// DeleteMFAChallenge mocks base method.
+func (m *MockMFAStorage) DeleteMFAChallenge(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMFAChallenge", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteMFAChallenge indicates an expected call of DeleteMFAChallenge.
+func (mr *MockMFAStorageMockRecorder) DeleteMFAChallenge(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMFAChallenge", reflect.TypeOf((*MockMFAStorage)(nil).DeleteMFAChallenge), ctx, id)
+}
+
+// EnableTOTPCredential mocks base method.
+func (m *MockMFAStorage) EnableTOTPCredential(ctx context.Context, userID, step int64, recoveryCodeHashes []string, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableTOTPCredential", ctx, userID, step, recoveryCodeHashes, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnableTOTPCredential indicates an expected call of EnableTOTPCredential.
+func (mr *MockMFAStorageMockRecorder) EnableTOTPCredential(ctx, userID, step, recoveryCodeHashes, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableTOTPCredential", reflect.TypeOf((*MockMFAStorage)(nil).EnableTOTPCredential), ctx, userID, step, recoveryCodeHashes, now)
+}
+
+// GetMFAChallenge mocks base method.
+func (m *MockMFAStorage) GetMFAChallenge(ctx context.Context, tokenHash string) (*MFAChallengeRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMFAChallenge", ctx, tokenHash)
+	ret0, _ := ret[0].(*MFAChallengeRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMFAChallenge indicates an expected call of GetMFAChallenge.
+func (mr *MockMFAStorageMockRecorder) GetMFAChallenge(ctx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMFAChallenge", reflect.TypeOf((*MockMFAStorage)(nil).GetMFAChallenge), ctx, tokenHash)
+}
+
+// GetTOTPCredential mocks base method.
+func (m *MockMFAStorage) GetTOTPCredential(ctx context.Context, userID int64) (*TOTPCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTOTPCredential", ctx, userID)
+	ret0, _ := ret[0].(*TOTPCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTOTPCredential indicates an expected call of GetTOTPCredential.
+func (mr *MockMFAStorageMockRecorder) GetTOTPCredential(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTOTPCredential", reflect.TypeOf((*MockMFAStorage)(nil).GetTOTPCredential), ctx, userID)
+}
+
+// ReplaceRecoveryCodes mocks base method.
+func (m *MockMFAStorage) ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceRecoveryCodes", ctx, userID, codeHashes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceRecoveryCodes indicates an expected call of ReplaceRecoveryCodes.
+func (mr *MockMFAStorageMockRecorder) ReplaceRecoveryCodes(ctx, userID, codeHashes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceRecoveryCodes", reflect.TypeOf((*MockMFAStorage)(nil).ReplaceRecoveryCodes), ctx, userID, codeHashes)
+}
+
+// SavePendingTOTPCredential mocks base method.
+func (m *MockMFAStorage) SavePendingTOTPCredential(ctx context.Context, credential *TOTPCredential) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePendingTOTPCredential", ctx, credential)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SavePendingTOTPCredential indicates an expected call of SavePendingTOTPCredential.
+func (mr *MockMFAStorageMockRecorder) SavePendingTOTPCredential(ctx, credential interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePendingTOTPCredential", reflect.TypeOf((*MockMFAStorage)(nil).SavePendingTOTPCredential), ctx, credential)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockMFAStorage) UseRecoveryCode(ctx context.Context, userID int64, codeHash string, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", ctx, userID, codeHash, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockMFAStorageMockRecorder) UseRecoveryCode(ctx, userID, codeHash, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockMFAStorage)(nil).UseRecoveryCode), ctx, userID, codeHash, now)
+}
+
+// UseTOTPStep mocks base method.
+func (m *MockMFAStorage) UseTOTPStep(ctx context.Context, userID, step int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTOTPStep", ctx, userID, step)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseTOTPStep indicates an expected call of UseTOTPStep.
+func (mr *MockMFAStorageMockRecorder) UseTOTPStep(ctx, userID, step interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTOTPStep", reflect.TypeOf((*MockMFAStorage)(nil).UseTOTPStep), ctx, userID, step)
+}
@@ -0,0 +1,250 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/passkey.go
+
+// Package domain is a generated GoMock package.
+package domain
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPasskeyService is a mock of PasskeyService interface.
+type MockPasskeyService struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasskeyServiceMockRecorder
+}
+
+// MockPasskeyServiceMockRecorder is the mock recorder for MockPasskeyService.
+type MockPasskeyServiceMockRecorder struct {
+	mock *MockPasskeyService
+}
+
+// NewMockPasskeyService creates a new mock instance.
+func NewMockPasskeyService(ctrl *gomock.Controller) *MockPasskeyService {
+	mock := &MockPasskeyService{ctrl: ctrl}
+	mock.recorder = &MockPasskeyServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPasskeyService) EXPECT() *MockPasskeyServiceMockRecorder {
+	return m.recorder
+}
+
+// BeginLogin mocks base method.
+func (m *MockPasskeyService) BeginLogin(ctx context.Context) (*PasskeyCeremony, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginLogin", ctx)
+	ret0, _ := ret[0].(*PasskeyCeremony)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BeginLogin indicates an expected call of BeginLogin.
+func (mr *MockPasskeyServiceMockRecorder) BeginLogin(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginLogin", reflect.TypeOf((*MockPasskeyService)(nil).BeginLogin), ctx)
+}
+
+// BeginRegistration mocks base method.
+func (m *MockPasskeyService) BeginRegistration(ctx context.Context, userID int64) (*PasskeyCeremony, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginRegistration", ctx, userID)
+	ret0, _ := ret[0].(*PasskeyCeremony)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BeginRegistration indicates an expected call of BeginRegistration.
+func (mr *MockPasskeyServiceMockRecorder) BeginRegistration(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginRegistration", reflect.TypeOf((*MockPasskeyService)(nil).BeginRegistration), ctx, userID)
+}
+
+// DeletePasskey mocks base method.
+func (m *MockPasskeyService) DeletePasskey(ctx context.Context, userID, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePasskey", ctx, userID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePasskey indicates an expected call of DeletePasskey.
+func (mr *MockPasskeyServiceMockRecorder) DeletePasskey(ctx, userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePasskey", reflect.TypeOf((*MockPasskeyService)(nil).DeletePasskey), ctx, userID, id)
+}
+
+// FinishLogin mocks base method.
+func (m *MockPasskeyService) FinishLogin(ctx context.Context, sessionID string, response []byte) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishLogin", ctx, sessionID, response)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FinishLogin indicates an expected call of FinishLogin.
+func (mr *MockPasskeyServiceMockRecorder) FinishLogin(ctx, sessionID, response interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishLogin", reflect.TypeOf((*MockPasskeyService)(nil).FinishLogin), ctx, sessionID, response)
+}
+
+// FinishRegistration mocks base method.
+func (m *MockPasskeyService) FinishRegistration(ctx context.Context, userID int64, sessionID, name string, response []byte) (*Passkey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishRegistration", ctx, userID, sessionID, name, response)
+	ret0, _ := ret[0].(*Passkey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FinishRegistration indicates an expected call of FinishRegistration.
+func (mr *MockPasskeyServiceMockRecorder) FinishRegistration(ctx, userID, sessionID, name, response interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishRegistration", reflect.TypeOf((*MockPasskeyService)(nil).FinishRegistration), ctx, userID, sessionID, name, response)
+}
+
+// ListPasskeys mocks base method.
+func (m *MockPasskeyService) ListPasskeys(ctx context.Context, userID int64) ([]*Passkey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPasskeys", ctx, userID)
+	ret0, _ := ret[0].([]*Passkey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPasskeys indicates an expected call of ListPasskeys.
+func (mr *MockPasskeyServiceMockRecorder) ListPasskeys(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPasskeys", reflect.TypeOf((*MockPasskeyService)(nil).ListPasskeys), ctx, userID)
+}
+
+// MockPasskeyStorage is a mock of PasskeyStorage interface.
+type MockPasskeyStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasskeyStorageMockRecorder
+}
+
+// MockPasskeyStorageMockRecorder is the mock recorder for MockPasskeyStorage.
+type MockPasskeyStorageMockRecorder struct {
+	mock *MockPasskeyStorage
+}
+
+// NewMockPasskeyStorage creates a new mock instance.
+func NewMockPasskeyStorage(ctrl *gomock.Controller) *MockPasskeyStorage {
+	mock := &MockPasskeyStorage{ctrl: ctrl}
+	mock.recorder = &MockPasskeyStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPasskeyStorage) EXPECT() *MockPasskeyStorageMockRecorder {
+	return m.recorder
+}
+
+// ConsumePasskeyCeremony mocks base method.
+func (m *MockPasskeyStorage) ConsumePasskeyCeremony(ctx context.Context, tokenHash string, kind PasskeyCeremonyKind, now time.Time) (*PasskeyCeremonyRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumePasskeyCeremony", ctx, tokenHash, kind, now)
+	ret0, _ := ret[0].(*PasskeyCeremonyRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumePasskeyCeremony indicates an expected call of ConsumePasskeyCeremony.
+func (mr *MockPasskeyStorageMockRecorder) ConsumePasskeyCeremony(ctx, tokenHash, kind, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumePasskeyCeremony", reflect.TypeOf((*MockPasskeyStorage)(nil).ConsumePasskeyCeremony), ctx, tokenHash, kind, now)
+}
+
+// CreatePasskey mocks base method.
+func (m *MockPasskeyStorage) CreatePasskey(ctx context.Context, passkey *Passkey) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePasskey", ctx, passkey)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePasskey indicates an expected call of CreatePasskey.
+func (mr *MockPasskeyStorageMockRecorder) CreatePasskey(ctx, passkey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePasskey", reflect.TypeOf((*MockPasskeyStorage)(nil).CreatePasskey), ctx, passkey)
+}
+
+// CreatePasskeyCeremony mocks base method.
+func (m *MockPasskeyStorage) CreatePasskeyCeremony(ctx context.Context, ceremony *PasskeyCeremonyRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePasskeyCeremony", ctx, ceremony)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePasskeyCeremony indicates an expected call of CreatePasskeyCeremony.
+func (mr *MockPasskeyStorageMockRecorder) CreatePasskeyCeremony(ctx, ceremony interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePasskeyCeremony", reflect.TypeOf((*MockPasskeyStorage)(nil).CreatePasskeyCeremony), ctx, ceremony)
+}
+
+// DeletePasskey mocks base method.
+func (m *MockPasskeyStorage) DeletePasskey(ctx context.Context, userID, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePasskey", ctx, userID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePasskey indicates an expected call of DeletePasskey.
+func (mr *MockPasskeyStorageMockRecorder) DeletePasskey(ctx, userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePasskey", reflect.TypeOf((*MockPasskeyStorage)(nil).DeletePasskey), ctx, userID, id)
+}
+
+// GetPasskeyByCredentialID mocks base method.
+func (m *MockPasskeyStorage) GetPasskeyByCredentialID(ctx context.Context, credentialID []byte) (*Passkey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPasskeyByCredentialID", ctx, credentialID)
+	ret0, _ := ret[0].(*Passkey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPasskeyByCredentialID indicates an expected call of GetPasskeyByCredentialID.
+func (mr *MockPasskeyStorageMockRecorder) GetPasskeyByCredentialID(ctx, credentialID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPasskeyByCredentialID", reflect.TypeOf((*MockPasskeyStorage)(nil).GetPasskeyByCredentialID), ctx, credentialID)
+}
+
+// ListPasskeys mocks base method.
+func (m *MockPasskeyStorage) ListPasskeys(ctx context.Context, userID int64) ([]*Passkey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPasskeys", ctx, userID)
+	ret0, _ := ret[0].([]*Passkey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPasskeys indicates an expected call of ListPasskeys.
+func (mr *MockPasskeyStorageMockRecorder) ListPasskeys(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPasskeys", reflect.TypeOf((*MockPasskeyStorage)(nil).ListPasskeys), ctx, userID)
+}
+
+// UpdatePasskeyUsage mocks base method.
+func (m *MockPasskeyStorage) UpdatePasskeyUsage(ctx context.Context, id int64, signCount uint32, backupState bool, usedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePasskeyUsage", ctx, id, signCount, backupState, usedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePasskeyUsage indicates an expected call of UpdatePasskeyUsage.
+func (mr *MockPasskeyStorageMockRecorder) UpdatePasskeyUsage(ctx, id, signCount, backupState, usedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePasskeyUsage", reflect.TypeOf((*MockPasskeyStorage)(nil).UpdatePasskeyUsage), ctx, id, signCount, backupState, usedAt)
+}
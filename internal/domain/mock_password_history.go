@@ -0,0 +1,79 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/password_history.go
+
+// Package domain is a generated GoMock package.
+package domain
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPasswordHistoryStorage is a mock of PasswordHistoryStorage interface.
+type MockPasswordHistoryStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasswordHistoryStorageMockRecorder
+}
+
+// MockPasswordHistoryStorageMockRecorder is the mock recorder for MockPasswordHistoryStorage.
+type MockPasswordHistoryStorageMockRecorder struct {
+	mock *MockPasswordHistoryStorage
+}
+
+// NewMockPasswordHistoryStorage creates a new mock instance.
+func NewMockPasswordHistoryStorage(ctrl *gomock.Controller) *MockPasswordHistoryStorage {
+	mock := &MockPasswordHistoryStorage{ctrl: ctrl}
+	mock.recorder = &MockPasswordHistoryStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPasswordHistoryStorage) EXPECT() *MockPasswordHistoryStorageMockRecorder {
+	return m.recorder
+}
+
+// AddPasswordHistory mocks base method.
+func (m *MockPasswordHistoryStorage) AddPasswordHistory(ctx context.Context, userID int64, passwordHash string, createdAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPasswordHistory", ctx, userID, passwordHash, createdAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddPasswordHistory indicates an expected call of AddPasswordHistory.
+func (mr *MockPasswordHistoryStorageMockRecorder) AddPasswordHistory(ctx, userID, passwordHash, createdAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPasswordHistory", reflect.TypeOf((*MockPasswordHistoryStorage)(nil).AddPasswordHistory), ctx, userID, passwordHash, createdAt)
+}
+
+// ListPasswordHistory mocks base method.
+func (m *MockPasswordHistoryStorage) ListPasswordHistory(ctx context.Context, userID int64, since time.Time, limit int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPasswordHistory", ctx, userID, since, limit)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPasswordHistory indicates an expected call of ListPasswordHistory.
+func (mr *MockPasswordHistoryStorageMockRecorder) ListPasswordHistory(ctx, userID, since, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPasswordHistory", reflect.TypeOf((*MockPasswordHistoryStorage)(nil).ListPasswordHistory), ctx, userID, since, limit)
+}
+
+// PrunePasswordHistory mocks base method.
+func (m *MockPasswordHistoryStorage) PrunePasswordHistory(ctx context.Context, userID int64, keep int, before time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PrunePasswordHistory", ctx, userID, keep, before)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PrunePasswordHistory indicates an expected call of PrunePasswordHistory.
+func (mr *MockPasswordHistoryStorageMockRecorder) PrunePasswordHistory(ctx, userID, keep, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrunePasswordHistory", reflect.TypeOf((*MockPasswordHistoryStorage)(nil).PrunePasswordHistory), ctx, userID, keep, before)
+}
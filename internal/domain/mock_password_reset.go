@@ -0,0 +1,146 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/password_reset.go
+
+// Package domain is a generated GoMock package.
+package domain
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPasswordResetService is a mock of PasswordResetService interface.
+type MockPasswordResetService struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasswordResetServiceMockRecorder
+}
+
+// MockPasswordResetServiceMockRecorder is the mock recorder for MockPasswordResetService.
+type MockPasswordResetServiceMockRecorder struct {
+	mock *MockPasswordResetService
+}
+
+// NewMockPasswordResetService creates a new mock instance.
+func NewMockPasswordResetService(ctrl *gomock.Controller) *MockPasswordResetService {
+	mock := &MockPasswordResetService{ctrl: ctrl}
+	mock.recorder = &MockPasswordResetServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPasswordResetService) EXPECT() *MockPasswordResetServiceMockRecorder {
+	return m.recorder
+}
+
+// ConfirmPasswordReset mocks base method.
+func (m *MockPasswordResetService) ConfirmPasswordReset(ctx context.Context, token, newPassword string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmPasswordReset", ctx, token, newPassword)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfirmPasswordReset indicates an expected call of ConfirmPasswordReset.
+func (mr *MockPasswordResetServiceMockRecorder) ConfirmPasswordReset(ctx, token, newPassword interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmPasswordReset", reflect.TypeOf((*MockPasswordResetService)(nil).ConfirmPasswordReset), ctx, token, newPassword)
+}
+
+// RequestPasswordReset mocks base method.
+func (m *MockPasswordResetService) RequestPasswordReset(ctx context.Context, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestPasswordReset", ctx, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestPasswordReset indicates an expected call of RequestPasswordReset.
+func (mr *MockPasswordResetServiceMockRecorder) RequestPasswordReset(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestPasswordReset", reflect.TypeOf((*MockPasswordResetService)(nil).RequestPasswordReset), ctx, email)
+}
+
+// MockPasswordResetStorage is a mock of PasswordResetStorage interface.
+type MockPasswordResetStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasswordResetStorageMockRecorder
+}
+
+// MockPasswordResetStorageMockRecorder is the mock recorder for MockPasswordResetStorage.
+type MockPasswordResetStorageMockRecorder struct {
+	mock *MockPasswordResetStorage
+}
+
+// NewMockPasswordResetStorage creates a new mock instance.
+func NewMockPasswordResetStorage(ctrl *gomock.Controller) *MockPasswordResetStorage {
+	mock := &MockPasswordResetStorage{ctrl: ctrl}
+	mock.recorder = &MockPasswordResetStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPasswordResetStorage) EXPECT() *MockPasswordResetStorageMockRecorder {
+	return m.recorder
+}
+
+// ConsumePasswordResetToken mocks base method.
+func (m *MockPasswordResetStorage) ConsumePasswordResetToken(ctx context.Context, tokenHash string, now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumePasswordResetToken", ctx, tokenHash, now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumePasswordResetToken indicates an expected call of ConsumePasswordResetToken.
+func (mr *MockPasswordResetStorageMockRecorder) ConsumePasswordResetToken(ctx, tokenHash, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumePasswordResetToken", reflect.TypeOf((*MockPasswordResetStorage)(nil).ConsumePasswordResetToken), ctx, tokenHash, now)
+}
+
+// CreatePasswordResetToken mocks base method.
+func (m *MockPasswordResetStorage) CreatePasswordResetToken(ctx context.Context, token *PasswordResetToken, limit int, since time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePasswordResetToken", ctx, token, limit, since)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePasswordResetToken indicates an expected call of CreatePasswordResetToken.
+func (mr *MockPasswordResetStorageMockRecorder) CreatePasswordResetToken(ctx, token, limit, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePasswordResetToken", reflect.TypeOf((*MockPasswordResetStorage)(nil).CreatePasswordResetToken), ctx, token, limit, since)
+}
+
+// DeleteUserPasswordResetTokens mocks base method.
+func (m *MockPasswordResetStorage) DeleteUserPasswordResetTokens(ctx context.Context, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserPasswordResetTokens", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserPasswordResetTokens indicates an expected call of DeleteUserPasswordResetTokens.
+func (mr *MockPasswordResetStorageMockRecorder) DeleteUserPasswordResetTokens(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserPasswordResetTokens", reflect.TypeOf((*MockPasswordResetStorage)(nil).DeleteUserPasswordResetTokens), ctx, userID)
+}
+
+// GetPasswordResetTokenUser mocks base method.
+func (m *MockPasswordResetStorage) GetPasswordResetTokenUser(ctx context.Context, tokenHash string, now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPasswordResetTokenUser", ctx, tokenHash, now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPasswordResetTokenUser indicates an expected call of GetPasswordResetTokenUser.
+func (mr *MockPasswordResetStorageMockRecorder) GetPasswordResetTokenUser(ctx, tokenHash, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPasswordResetTokenUser", reflect.TypeOf((*MockPasswordResetStorage)(nil).GetPasswordResetTokenUser), ctx, tokenHash, now)
+}
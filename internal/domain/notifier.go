@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Notification kinds.
+const (
+	NotificationPasswordReset     = "password_reset"
+	NotificationEmailVerification = "email_verification"
+	NotificationMagicLink         = "magic_link"
+	NotificationInvitation        = "invitation"
+)
+
+// Notification is a message delivered to a user out of band, e.g. by email.
+type Notification struct {
+	Kind      string
+	UserID    int64
+	Email     string
+	Username  string
+	Link      string    // Action link, if any
+	ExpiresAt time.Time // Expiry of Link
+	// Organization and Inviter name the organization and the user behind an invitation,
+	// which may be sent to an email without an account.
+	Organization string
+	Inviter      string
+}
+
+// Notifier delivers notifications to users.
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
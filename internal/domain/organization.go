@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultOrganizationID is the organization seeded by the migrations. Requests that name no
+// tenant act in it, and sign-ups and the accounts created before organizations joined it.
+const DefaultOrganizationID int64 = 1
+
+// Roles of a member within their organization.
+const (
+	OrganizationRoleOwner  = "owner"
+	OrganizationRoleAdmin  = "admin"
+	OrganizationRoleMember = "member"
+)
+
+// Organization is a tenant. Accounts are global and join organizations through memberships,
+// each with its own role.
+type Organization struct {
+	ID        int64     `json:"id"`
+	Slug      string    `json:"slug"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"` // Set by the storage, never accepted from clients
+}
+
+// Member is an account that joined an organization, together with its role there.
+type Member struct {
+	UserID   int64     `json:"user_id"`
+	Username string    `json:"username"`
+	Email    string    `json:"email"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+type tenantKey struct{}
+
+// ContextWithTenant returns a copy of ctx acting in the organization id. The organization of
+// an authenticated principal takes precedence over it.
+func ContextWithTenant(ctx context.Context, id int64) context.Context {
+	return context.WithValue(ctx, tenantKey{}, id)
+}
+
+// TenantFromContext returns the organization a request acts in: the one of the authenticated
+// principal, then the one set by ContextWithTenant, then DefaultOrganizationID.
+func TenantFromContext(ctx context.Context) int64 {
+	if p, ok := PrincipalFromContext(ctx); ok && p.OrganizationID > 0 {
+		return p.OrganizationID
+	}
+	if id, ok := ctx.Value(tenantKey{}).(int64); ok && id > 0 {
+		return id
+	}
+	return DefaultOrganizationID
+}
+
+type OrganizationService interface {
+	// CreateOrganization creates an organization owned by the authenticated user.
+	CreateOrganization(ctx context.Context, org *Organization) (int64, error)
+	ListOrganizations(ctx context.Context) ([]*Organization, error)
+	// GetOrganization returns the organization the request acts in.
+	GetOrganization(ctx context.Context) (*Organization, error)
+	// ListMembers lists the members of the organization the request acts in.
+	ListMembers(ctx context.Context) ([]*Member, error)
+	// SetMemberRole changes the role of a member of the organization the request acts in.
+	// Only owners may make or unmake owners, and nobody may change their own role.
+	SetMemberRole(ctx context.Context, userID int64, role string) error
+}
+
+type OrganizationStorage interface {
+	// CreateOrganization creates org with ownerID as its owner, and returns ErrOrganizationExists
+	// when the slug is taken.
+	CreateOrganization(ctx context.Context, org *Organization, ownerID int64) (int64, error)
+	ListOrganizations(ctx context.Context) ([]*Organization, error)
+	// GetOrganization returns ErrOrganizationNotFound for an unknown ID.
+	GetOrganization(ctx context.Context, id int64) (*Organization, error)
+	ListMembers(ctx context.Context, orgID int64) ([]*Member, error)
+	// GetMember returns ErrUserNotFound when the user is not a member of the organization.
+	GetMember(ctx context.Context, orgID, userID int64) (*Member, error)
+	SetMemberRole(ctx context.Context, orgID, userID int64, role string) error
+	// AddMember makes an existing account a member of the organization with role; an account
+	// that is already a member keeps its role.
+	AddMember(ctx context.Context, orgID, userID int64, role string) error
+}
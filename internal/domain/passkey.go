@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Passkey is a WebAuthn credential registered by a user. A user may have several.
+type Passkey struct {
+	ID              int64      `json:"id"`
+	UserID          int64      `json:"-"`
+	CredentialID    []byte     `json:"credential_id"` // Base64-encoded in JSON
+	Name            string     `json:"name"`
+	PublicKey       []byte     `json:"-"` // COSE-encoded
+	AttestationType string     `json:"-"`
+	Transports      []string   `json:"transports"`
+	AAGUID          []byte     `json:"aaguid"`     // Identifies the authenticator model
+	SignCount       uint32     `json:"sign_count"` // Last signature counter seen; a lower one hints at a cloned authenticator
+	BackupEligible  bool       `json:"backup_eligible"`
+	BackupState     bool       `json:"backed_up"`
+	CreatedAt       time.Time  `json:"created_at"`
+	LastUsedAt      *time.Time `json:"last_used_at,omitempty"`
+}
+
+// PasskeyCeremony is the first half of a registration or login. Options are passed to
+// navigator.credentials.create() or get(), and the result is sent back with SessionID.
+type PasskeyCeremony struct {
+	SessionID string          `json:"session_id"`
+	Options   json.RawMessage `json:"options"`
+}
+
+type PasskeyCeremonyKind string
+
+const (
+	PasskeyRegistration PasskeyCeremonyKind = "registration"
+	PasskeyLogin        PasskeyCeremonyKind = "login"
+)
+
+// PasskeyCeremonyRecord is the persisted server state of a ceremony in progress;
+// only the hash of its session ID is stored.
+type PasskeyCeremonyRecord struct {
+	ID        int64
+	UserID    *int64 // Nil for logins, where the user is not known until the assertion
+	Kind      PasskeyCeremonyKind
+	TokenHash string
+	Data      []byte
+	ExpiresAt time.Time
+}
+
+type PasskeyService interface {
+	// BeginRegistration returns the options for creating a new passkey for the user.
+	BeginRegistration(ctx context.Context, userID int64) (*PasskeyCeremony, error)
+	// FinishRegistration verifies the attestation returned by the authenticator and stores the passkey.
+	FinishRegistration(ctx context.Context, userID int64, sessionID, name string, response []byte) (*Passkey, error)
+	ListPasskeys(ctx context.Context, userID int64) ([]*Passkey, error)
+	DeletePasskey(ctx context.Context, userID, id int64) error
+	// BeginLogin returns the options for a login with any passkey of the relying party.
+	BeginLogin(ctx context.Context) (*PasskeyCeremony, error)
+	// FinishLogin verifies the assertion returned by the authenticator, records its
+	// signature counter and returns the ID of the user owning the passkey.
+	FinishLogin(ctx context.Context, sessionID string, response []byte) (int64, error)
+}
+
+type PasskeyStorage interface {
+	// CreatePasskey returns ErrPasskeyExists if the credential is registered already.
+	CreatePasskey(ctx context.Context, passkey *Passkey) (int64, error)
+	ListPasskeys(ctx context.Context, userID int64) ([]*Passkey, error)
+	// GetPasskeyByCredentialID returns ErrPasskeyNotFound for unknown credentials.
+	GetPasskeyByCredentialID(ctx context.Context, credentialID []byte) (*Passkey, error)
+	// UpdatePasskeyUsage records the signature counter and backup state of a successful login.
+	UpdatePasskeyUsage(ctx context.Context, id int64, signCount uint32, backupState bool, usedAt time.Time) error
+	// DeletePasskey returns ErrPasskeyNotFound unless the user owns the passkey.
+	DeletePasskey(ctx context.Context, userID, id int64) error
+
+	CreatePasskeyCeremony(ctx context.Context, ceremony *PasskeyCeremonyRecord) error
+	// ConsumePasskeyCeremony deletes and returns an unexpired ceremony of the given kind,
+	// or returns ErrInvalidPasskeySession.
+	ConsumePasskeyCeremony(ctx context.Context, tokenHash string, kind PasskeyCeremonyKind, now time.Time) (*PasskeyCeremonyRecord, error)
+}
@@ -0,0 +1,15 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// PasswordHistoryStorage keeps the hashes of passwords a user has replaced.
+type PasswordHistoryStorage interface {
+	AddPasswordHistory(ctx context.Context, userID int64, passwordHash string, createdAt time.Time) error
+	// ListPasswordHistory returns up to limit hashes created after since, newest first.
+	ListPasswordHistory(ctx context.Context, userID int64, since time.Time, limit int) ([]string, error)
+	// PrunePasswordHistory deletes every hash of a user but the newest keep, and those created before before.
+	PrunePasswordHistory(ctx context.Context, userID int64, keep int, before time.Time) error
+}
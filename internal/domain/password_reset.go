@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// PasswordResetToken is a persisted, single-use password reset token.
+// Only the hash of the token sent to the user is stored.
+type PasswordResetToken struct {
+	ID        int64
+	UserID    int64
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+type PasswordResetService interface {
+	// RequestPasswordReset sends a reset link to the owner of email, if any.
+	// It succeeds whether or not the email is registered.
+	RequestPasswordReset(ctx context.Context, email string) error
+	// ConfirmPasswordReset sets a new password using a token from a reset link
+	// and signs out every session of the user.
+	ConfirmPasswordReset(ctx context.Context, token, newPassword string) error
+}
+
+type PasswordResetStorage interface {
+	// CreatePasswordResetToken stores token unless limit tokens were issued for its user since
+	// the given time, and returns ErrEmailThrottled then. The count and the insert are atomic.
+	CreatePasswordResetToken(ctx context.Context, token *PasswordResetToken, limit int, since time.Time) (int64, error)
+	// GetPasswordResetTokenUser returns the ID of the user of an unused, unexpired token without
+	// consuming it, or ErrInvalidPasswordResetToken.
+	GetPasswordResetTokenUser(ctx context.Context, tokenHash string, now time.Time) (int64, error)
+	// ConsumePasswordResetToken atomically marks an unused, unexpired token as used and
+	// returns the ID of its user, or ErrInvalidPasswordResetToken.
+	ConsumePasswordResetToken(ctx context.Context, tokenHash string, now time.Time) (int64, error)
+	// DeleteUserPasswordResetTokens invalidates every outstanding token of a user.
+	DeleteUserPasswordResetTokens(ctx context.Context, userID int64) error
+}
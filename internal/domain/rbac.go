@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// RoleAdmin is the role seeded with every permission.
+const RoleAdmin = "admin"
+
+// Permissions checked by the API. Users may always act on their own account; the
+// permissions extend an action to the accounts of others.
+const (
+	PermissionUsersRead   = "users:read"
+	PermissionUsersWrite  = "users:write"
+	PermissionUsersDelete = "users:delete"
+	PermissionUsersUnlock = "users:unlock"
+	PermissionRolesRead   = "roles:read"
+	PermissionRolesWrite  = "roles:write"
+	// Organizations are managed across tenants, so these are only granted to platform roles.
+	PermissionOrganizationsRead  = "organizations:read"
+	PermissionOrganizationsWrite = "organizations:write"
+)
+
+// Role is a named set of permissions assigned to users.
+type Role struct {
+	ID          int64     `json:"-"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Access is what a user may do: the names of their roles and the permissions those grant.
+type Access struct {
+	Roles       []string
+	Permissions []string
+}
+
+type RoleService interface {
+	ListRoles(ctx context.Context) ([]*Role, error)
+	ListUserRoles(ctx context.Context, userID int64) ([]*Role, error)
+	// AssignRole gives a role to a user; assigning a role the user has is a no-op.
+	AssignRole(ctx context.Context, userID int64, role string) error
+	// RevokeRole takes a role from a user; revoking a role the user does not have is a no-op.
+	// Changes reach access tokens when they are next refreshed.
+	RevokeRole(ctx context.Context, userID int64, role string) error
+	// UserAccess returns the roles and permissions put into the access tokens of a user.
+	UserAccess(ctx context.Context, userID int64) (*Access, error)
+}
+
+type RoleStorage interface {
+	ListRoles(ctx context.Context) ([]*Role, error)
+	// GetRoleByName returns ErrRoleNotFound for an unknown name.
+	GetRoleByName(ctx context.Context, name string) (*Role, error)
+	ListUserRoles(ctx context.Context, userID int64) ([]*Role, error)
+	AssignRole(ctx context.Context, userID, roleID int64, assignedAt time.Time) error
+	RevokeRole(ctx context.Context, userID, roleID int64) error
+}
@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// RefreshToken is a persisted, single-use refresh token.
+// Tokens issued by rotating one another share a FamilyID.
+type RefreshToken struct {
+	ID        int64
+	UserID    int64
+	FamilyID  string
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
+
+type RefreshTokenStorage interface {
+	CreateRefreshToken(ctx context.Context, token *RefreshToken) (int64, error)
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*RefreshToken, error)
+	// MarkRefreshTokenUsed atomically flags an active token as used.
+	// It returns ErrRefreshTokenReused if the token was already used or revoked.
+	MarkRefreshTokenUsed(ctx context.Context, id int64) error
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) error
+	RevokeUserRefreshTokens(ctx context.Context, userID int64) error
+}
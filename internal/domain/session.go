@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Session is a signed-in device. Its ID is shared with the refresh token family
+// and carried in access tokens as the "sid" claim.
+type Session struct {
+	ID             string     `json:"id"`
+	UserID         int64      `json:"user_id"`
+	OrganizationID int64      `json:"organization_id"` // Organization the session was started in, which its tokens act in
+	Device         string     `json:"device"`
+	IP             string     `json:"ip"`
+	UserAgent      string     `json:"user_agent"`
+	CreatedAt      time.Time  `json:"created_at"`
+	LastSeenAt     time.Time  `json:"last_seen_at"`
+	RevokedAt      *time.Time `json:"-"`
+	Current        bool       `json:"current"`
+}
+
+// ClientInfo describes the client that starts or refreshes a session.
+type ClientInfo struct {
+	Device    string
+	IP        string
+	UserAgent string
+}
+
+type SessionService interface {
+	ListSessions(ctx context.Context, userID int64) ([]*Session, error)
+	RevokeSession(ctx context.Context, userID int64, sessionID string) error
+	// RevokeOtherSessions signs the user out everywhere except keepSessionID (which may be empty).
+	RevokeOtherSessions(ctx context.Context, userID int64, keepSessionID string) error
+}
+
+type SessionStorage interface {
+	CreateSession(ctx context.Context, session *Session) error
+	GetSession(ctx context.Context, id string) (*Session, error)
+	// ListUserSessions returns the active sessions of a user, most recently used first.
+	ListUserSessions(ctx context.Context, userID int64) ([]*Session, error)
+	TouchSession(ctx context.Context, id string, ip string, lastSeenAt time.Time) error
+	// RevokeSession returns ErrSessionNotFound unless an active session with the ID belongs to the user.
+	RevokeSession(ctx context.Context, userID int64, id string) error
+	// RevokeUserSessions revokes every active session of the user except exceptID and returns the revoked IDs.
+	RevokeUserSessions(ctx context.Context, userID int64, exceptID string) ([]string, error)
+}
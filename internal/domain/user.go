@@ -1,30 +1,171 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type User struct {
-	ID       int64  `json:"id"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	ID              int64      `json:"id"`
+	Username        string     `json:"username"`
+	Email           string     `json:"email"`
+	Password        string     `json:"password"`
+	EmailVerifiedAt *time.Time `json:"-"` // Set by the storage, never accepted from clients
+	CreatedAt       time.Time  `json:"-"` // Set by the storage, never accepted from clients
+	// OrganizationID and OrganizationRole are the membership the user is read through, and the
+	// one CreateUser adds.
+	OrganizationID   int64  `json:"-"`
+	OrganizationRole string `json:"-"`
 }
 
 type UserResponse struct {
-	ID       int64  `json:"id"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
+	ID               int64      `json:"id"`
+	Username         string     `json:"username"`
+	Email            string     `json:"email"`
+	EmailVerifiedAt  *time.Time `json:"email_verified_at,omitempty"` // Nil until the current email is verified
+	CreatedAt        time.Time  `json:"created_at"`
+	OrganizationID   int64      `json:"organization_id"`
+	OrganizationRole string     `json:"organization_role"`
+}
+
+// UserPatch lists the profile fields to change; nil fields are left untouched.
+// Passwords are not part of it and are changed through a dedicated operation.
+type UserPatch struct {
+	Username *string
+	Email    *string
+}
+
+// IsEmpty reports whether the patch changes nothing.
+func (p *UserPatch) IsEmpty() bool {
+	return p.Username == nil && p.Email == nil
+}
+
+// Sort fields accepted by ListUsers.
+const (
+	UserSortByID        = "id"
+	UserSortByCreatedAt = "created_at"
+	UserSortByUsername  = "username"
+	UserSortByEmail     = "email"
+)
+
+// Sort orders accepted by ListUsers.
+const (
+	SortOrderAsc  = "asc"
+	SortOrderDesc = "desc"
+)
+
+// UserFilter narrows a user listing. Zero values do not filter.
+type UserFilter struct {
+	EmailPrefix      string
+	UsernameContains string
+	CreatedAfter     time.Time // Inclusive
+	CreatedBefore    time.Time // Exclusive
+}
+
+// ListUsersParams is a request for one page of users.
+type ListUsersParams struct {
+	Filter    UserFilter
+	SortBy    string // One of the UserSortBy* constants, UserSortByID by default
+	SortOrder string // SortOrderAsc (default) or SortOrderDesc
+	PageSize  int
+	PageToken string // NextPageToken of the previous page; must be used with the same filter and sort
+}
+
+// UserPage is one page of a user listing.
+type UserPage struct {
+	Users         []*UserResponse `json:"users"`
+	NextPageToken string          `json:"next_page_token,omitempty"`
+}
+
+// UserCursor is the keyset position after which a listing continues.
+type UserCursor struct {
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	Username  string    `json:"username,omitempty"`
+	Email     string    `json:"email,omitempty"`
+}
+
+// UserQuery is a validated listing query passed to the storage.
+type UserQuery struct {
+	Filter UserFilter
+	SortBy string
+	Desc   bool
+	After  *UserCursor // Nil for the first page
+	Limit  int
 }
 
 type UserService interface {
+	// CreateUser creates an account that joins user.OrganizationID with user.OrganizationRole,
+	// by default as a member of DefaultOrganizationID. The tenant of ctx plays no part, so that
+	// sign-ups cannot pick their organization; other ones are joined through invitations.
 	CreateUser(ctx context.Context, user *User) (int64, error)
 	GetUserByID(ctx context.Context, id int64) (*UserResponse, error)
+	// UpdateUser replaces the profile of a user. An empty password keeps the current one.
 	UpdateUser(ctx context.Context, user *User) error
+	// PatchUser changes only the fields set in patch and returns the updated user.
+	PatchUser(ctx context.Context, id int64, patch *UserPatch) (*UserResponse, error)
+	// DeleteUser removes the user from the organization of ctx and deletes the account once it
+	// belongs to no organization.
 	DeleteUser(ctx context.Context, id int64) error
+	// Authenticate verifies the password of the user identified by email or username.
+	Authenticate(ctx context.Context, login, password string) (*UserResponse, error)
+	// ListUsers returns one page of users using keyset pagination.
+	ListUsers(ctx context.Context, params ListUsersParams) (*UserPage, error)
+	// ChangePassword replaces the password after verifying the current one and
+	// signs out every other session of the user.
+	ChangePassword(ctx context.Context, id int64, currentPassword, newPassword string) error
+	// ResetPassword replaces the password without the current one and signs out every
+	// session of the user. Callers must have proven ownership of the account otherwise.
+	// claim, unless nil, runs once newPassword passed every check, right before it is
+	// stored; an error from it aborts the reset.
+	ResetPassword(ctx context.Context, id int64, newPassword string, claim func(ctx context.Context) error) error
+	// GetUserByEmail looks an account up by exact email in any organization, like
+	// UserStorage.GetUserByEmail. It serves the flows that start from an email address.
+	GetUserByEmail(ctx context.Context, email string) (*UserResponse, error)
+	// VerifyEmail marks email as verified if it is still the email of the user,
+	// and returns ErrUserNotFound otherwise.
+	VerifyEmail(ctx context.Context, id int64, email string) error
+	// WithUserTenant returns ctx scoped to the tenant ctx names if the user is a member there,
+	// and to the organization the user joined first otherwise. Flows that identify the user by
+	// a token rather than by a login use it, since the tenant of their unauthenticated requests
+	// is only a hint.
+	WithUserTenant(ctx context.Context, id int64) (context.Context, error)
 }
 
+// UserStorage reads and changes users through their membership in the organization of
+// TenantFromContext, so users of other organizations are never returned or changed. Accounts
+// themselves are global: usernames and emails are unique across organizations.
 type UserStorage interface {
+	// CreateUser creates the account together with its membership in user.OrganizationID
+	// with user.OrganizationRole, whatever the tenant of ctx. It returns ErrOrganizationNotFound
+	// for an unknown organization.
 	CreateUser(ctx context.Context, user *User) (int64, error)
 	GetUserByID(ctx context.Context, id int64) (*User, error)
+	// GetUserByLogin looks an account up by email or username in any organization. The user is
+	// read through its membership in the tenant if there is one, and through the organization
+	// it joined first otherwise; callers must not expose it before the login succeeded.
+	GetUserByLogin(ctx context.Context, login string) (*User, error)
+	// GetUserByEmail looks an account up by email like GetUserByLogin.
+	GetUserByEmail(ctx context.Context, email string) (*User, error)
+	// UpdateUser replaces the username and email of a user; the password is left untouched.
+	// Changing the email clears its verification.
 	UpdateUser(ctx context.Context, user *User) error
+	// PatchUser updates only the fields set in patch. Changing the email clears its verification.
+	PatchUser(ctx context.Context, id int64, patch *UserPatch) error
+	// MarkEmailVerified sets the email verification time if email is still the email
+	// of the user, and returns ErrUserNotFound otherwise.
+	MarkEmailVerified(ctx context.Context, id int64, email string, verifiedAt time.Time) error
+	// UpdatePassword stores a new password hash.
+	UpdatePassword(ctx context.Context, id int64, passwordHash string) error
+	// ReplacePasswordHash stores newHash only if the stored hash is still oldHash, and
+	// returns ErrUserNotFound otherwise.
+	ReplacePasswordHash(ctx context.Context, id int64, oldHash, newHash string) error
+	// DeleteUser removes the user from the organization and deletes the account once it
+	// belongs to no organization.
 	DeleteUser(ctx context.Context, id int64) error
+	// ListUsers returns up to query.Limit users ordered by query.SortBy and then by ID.
+	ListUsers(ctx context.Context, query UserQuery) ([]*User, error)
+	// GetUserOrganizationID returns the tenant if the user is a member there, and the
+	// organization the user joined first otherwise.
+	GetUserOrganizationID(ctx context.Context, id int64) (int64, error)
 }
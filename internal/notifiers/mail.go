@@ -0,0 +1,52 @@
+// Package notifiers contains the domain.Notifier implementations.
+package notifiers
+
+import (
+	"context"
+	"embed"
+	"io/fs"
+	"os"
+
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/kerim-dauren/user-service/pkg/mailx"
+)
+
+// FallbackLocale is used for locales without a template; every template set must include it.
+const FallbackLocale = "en"
+
+//go:embed templates
+var templates embed.FS
+
+// LoadTemplates parses the mail templates from dir, or the built-in ones when dir is empty.
+// Templates are named after the notification kind, e.g. en/password_reset.subject.txt.
+func LoadTemplates(dir string) (*mailx.Templates, error) {
+	var fsys fs.FS
+	if dir != "" {
+		fsys = os.DirFS(dir)
+	} else {
+		fsys, _ = fs.Sub(templates, "templates")
+	}
+	return mailx.NewTemplates(fsys, FallbackLocale)
+}
+
+type mailNotifier struct {
+	sender    mailx.Sender
+	templates *mailx.Templates
+	from      string
+	locale    string
+}
+
+// NewMailNotifier renders notifications in locale and emails them from the from address.
+func NewMailNotifier(sender mailx.Sender, templates *mailx.Templates, from, locale string) domain.Notifier {
+	return &mailNotifier{sender: sender, templates: templates, from: from, locale: locale}
+}
+
+func (n *mailNotifier) Notify(ctx context.Context, notification domain.Notification) error {
+	msg, err := n.templates.Render(notification.Kind, n.locale, notification)
+	if err != nil {
+		return err
+	}
+	msg.From = n.from
+	msg.To = []string{notification.Email}
+	return n.sender.Send(ctx, msg)
+}
@@ -0,0 +1,54 @@
+package notifiers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/kerim-dauren/user-service/pkg/mailx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingSender struct {
+	sent []*mailx.Message
+}
+
+func (s *recordingSender) Send(_ context.Context, msg *mailx.Message) error {
+	if err := msg.Validate(); err != nil {
+		return err
+	}
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+func TestMailNotifier_BuiltInTemplates(t *testing.T) {
+	templates, err := LoadTemplates("")
+	require.NoError(t, err)
+
+	for _, kind := range []string{domain.NotificationPasswordReset, domain.NotificationEmailVerification, domain.NotificationMagicLink, domain.NotificationInvitation} {
+		for _, locale := range []string{"en", "ru", "kk"} {
+			sender := &recordingSender{}
+			notifier := NewMailNotifier(sender, templates, "User Service <no-reply@example.com>", locale)
+
+			err := notifier.Notify(context.Background(), domain.Notification{
+				Kind:      kind,
+				UserID:    1,
+				Email:     "alice@example.com",
+				Username:  "alice",
+				Link:      "https://example.com/action?token=abc&x=1",
+				ExpiresAt: time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC),
+			})
+			require.NoError(t, err, kind, locale)
+			require.Len(t, sender.sent, 1)
+
+			msg := sender.sent[0]
+			assert.Equal(t, []string{"alice@example.com"}, msg.To)
+			assert.NotEmpty(t, msg.Subject)
+			assert.Contains(t, msg.Text, "https://example.com/action?token=abc&x=1", kind, locale)
+			assert.Contains(t, msg.Text, "2024-05-01 12:30 UTC", kind, locale)
+			assert.Contains(t, msg.HTML, `href="https://example.com/action?token=abc&amp;x=1"`, kind, locale)
+		}
+	}
+}
@@ -0,0 +1,398 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/kerim-dauren/user-service/pkg/tokenx"
+)
+
+const tokenTypeBearer = "Bearer"
+
+type AccessTokenManager interface {
+	tokenx.Issuer
+	tokenx.Verifier
+	TTL() time.Duration
+}
+
+// AccessResolver returns the roles and permissions put into the access tokens of a user.
+type AccessResolver interface {
+	UserAccess(ctx context.Context, userID int64) (*domain.Access, error)
+}
+
+// LoginPolicy lists the account requirements checked on login, after the password.
+type LoginPolicy struct {
+	RequireVerifiedEmail bool
+}
+
+type authService struct {
+	logger          *slog.Logger
+	userService     domain.UserService
+	tokens          AccessTokenManager
+	refreshTokens   domain.RefreshTokenStorage
+	sessions        domain.SessionStorage
+	mfa             domain.MFAService
+	passkeys        domain.PasskeyService
+	magicLinks      domain.MagicLinkService
+	lockout         domain.LockoutService
+	access          AccessResolver
+	refreshTokenTTL time.Duration
+	policy          LoginPolicy
+	now             func() time.Time
+}
+
+func NewAuthService(
+	logger *slog.Logger,
+	userService domain.UserService,
+	tokens AccessTokenManager,
+	refreshTokens domain.RefreshTokenStorage,
+	sessions domain.SessionStorage,
+	mfa domain.MFAService,
+	passkeys domain.PasskeyService,
+	magicLinks domain.MagicLinkService,
+	lockout domain.LockoutService,
+	access AccessResolver,
+	refreshTokenTTL time.Duration,
+	policy LoginPolicy,
+) domain.AuthService {
+	return &authService{
+		logger:          logger,
+		userService:     userService,
+		tokens:          tokens,
+		refreshTokens:   refreshTokens,
+		sessions:        sessions,
+		mfa:             mfa,
+		passkeys:        passkeys,
+		magicLinks:      magicLinks,
+		lockout:         lockout,
+		access:          access,
+		refreshTokenTTL: refreshTokenTTL,
+		policy:          policy,
+		now:             time.Now,
+	}
+}
+
+func (s *authService) Login(ctx context.Context, login, password string, client domain.ClientInfo) (result *domain.LoginResult, err error) {
+	defer observeDuration(s.logger, "Login", &err)()
+
+	user, err := s.authenticate(ctx, login, password, client)
+	if err != nil {
+		return nil, err
+	}
+	// Checked only once the password is known to be right, so that it reveals nothing to others.
+	if s.policy.RequireVerifiedEmail && user.EmailVerifiedAt == nil {
+		return nil, domain.ErrEmailNotVerified
+	}
+	return s.completeLogin(ctx, user, client)
+}
+
+func (s *authService) VerifyMFA(ctx context.Context, mfaToken, code string, client domain.ClientInfo) (tokens *domain.AuthTokens, err error) {
+	defer observeDuration(s.logger, "VerifyMFA", &err)()
+
+	userID, err := s.mfa.CompleteChallenge(ctx, mfaToken, code)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.loadTokenUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, domain.ErrInvalidMFAChallenge
+		}
+		return nil, err
+	}
+	return s.startSession(ctx, user, client)
+}
+
+func (s *authService) LoginWithPasskey(ctx context.Context, sessionID string, response []byte, client domain.ClientInfo) (tokens *domain.AuthTokens, err error) {
+	defer observeDuration(s.logger, "LoginWithPasskey", &err)()
+
+	userID, err := s.passkeys.FinishLogin(ctx, sessionID, response)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.loadTokenUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, domain.ErrInvalidPasskey
+		}
+		return nil, err
+	}
+	if s.policy.RequireVerifiedEmail && user.EmailVerifiedAt == nil {
+		return nil, domain.ErrEmailNotVerified
+	}
+	// A passkey with user verification, which passkey logins require, is a second factor in
+	// itself, so there is no MFA challenge here.
+	return s.startSession(ctx, user, client)
+}
+
+func (s *authService) LoginWithMagicLink(ctx context.Context, token string, client domain.ClientInfo) (result *domain.LoginResult, err error) {
+	defer observeDuration(s.logger, "LoginWithMagicLink", &err)()
+
+	// The link verifies the email, so the verified-email policy always holds here.
+	user, err := s.magicLinks.ConsumeMagicLink(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	// An email inbox is a single factor, so users with MFA still have to complete a challenge.
+	return s.completeLogin(ctx, user, client)
+}
+
+func (s *authService) Refresh(ctx context.Context, refreshToken string, client domain.ClientInfo) (tokens *domain.AuthTokens, err error) {
+	defer observeDuration(s.logger, "Refresh", &err)()
+
+	current, err := s.refreshTokens.GetRefreshTokenByHash(ctx, tokenx.HashOpaque(refreshToken))
+	if err != nil {
+		if errors.Is(err, domain.ErrRefreshTokenNotFound) {
+			return nil, domain.ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	if current.RevokedAt != nil || !s.now().Before(current.ExpiresAt) {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+	if current.UsedAt != nil {
+		return nil, s.handleReuse(ctx, current)
+	}
+	session, err := s.sessions.GetSession(ctx, current.FamilyID)
+	if err != nil {
+		if errors.Is(err, domain.ErrSessionNotFound) {
+			return nil, domain.ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	if session.RevokedAt != nil {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+	// The user is loaded before the token is spent, so that a failed lookup leaves it usable
+	// instead of turning the client's retry into a reuse. The session keeps its organization,
+	// and a user who left it can no longer refresh there.
+	user, err := s.userService.GetUserByID(domain.ContextWithTenant(ctx, session.OrganizationID), current.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, domain.ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	if err = s.refreshTokens.MarkRefreshTokenUsed(ctx, current.ID); err != nil {
+		if errors.Is(err, domain.ErrRefreshTokenReused) {
+			// Lost a race against another rotation of the same token.
+			return nil, s.handleReuse(ctx, current)
+		}
+		return nil, err
+	}
+
+	if err = s.sessions.TouchSession(ctx, session.ID, client.IP, s.now()); err != nil {
+		return nil, fmt.Errorf("touch session: %w", err)
+	}
+
+	return s.issueTokens(ctx, user, session.ID)
+}
+
+func (s *authService) Logout(ctx context.Context, refreshToken string) (err error) {
+	defer observeDuration(s.logger, "Logout", &err)()
+
+	current, err := s.refreshTokens.GetRefreshTokenByHash(ctx, tokenx.HashOpaque(refreshToken))
+	if err != nil {
+		if errors.Is(err, domain.ErrRefreshTokenNotFound) {
+			return nil
+		}
+		return err
+	}
+	return s.revokeSession(ctx, current.UserID, current.FamilyID)
+}
+
+func (s *authService) VerifyAccessToken(ctx context.Context, token string) (*domain.Principal, error) {
+	claims, err := s.tokens.Verify(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrUnauthorized, err)
+	}
+	userID, err := claims.UserID()
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid subject", domain.ErrUnauthorized)
+	}
+
+	session, err := s.sessions.GetSession(ctx, claims.SessionID)
+	if err != nil {
+		if errors.Is(err, domain.ErrSessionNotFound) {
+			return nil, fmt.Errorf("%w: unknown session", domain.ErrUnauthorized)
+		}
+		return nil, err
+	}
+	if session.RevokedAt != nil || session.UserID != userID {
+		return nil, fmt.Errorf("%w: session revoked", domain.ErrUnauthorized)
+	}
+
+	return &domain.Principal{
+		UserID:           userID,
+		Username:         claims.Username,
+		OrganizationID:   claims.OrganizationID,
+		OrganizationRole: claims.OrganizationRole,
+		Roles:            claims.Roles,
+		Permissions:      claims.Permissions,
+		SessionID:        claims.SessionID,
+	}, nil
+}
+
+// loadTokenUser loads the user named by a challenge or passkey in an organization of that
+// user, the tenant of the unauthenticated request only if the user is a member there.
+func (s *authService) loadTokenUser(ctx context.Context, userID int64) (*domain.UserResponse, error) {
+	ctx, err := s.userService.WithUserTenant(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.userService.GetUserByID(ctx, userID)
+}
+
+// handleReuse revokes every token of the family: a used refresh token presented again
+// means it was copied, and there is no way to tell the legitimate client from the thief.
+func (s *authService) handleReuse(ctx context.Context, token *domain.RefreshToken) error {
+	s.logger.Warn("refresh token reuse detected", "user_id", token.UserID, "session_id", token.FamilyID)
+	if err := s.revokeSession(ctx, token.UserID, token.FamilyID); err != nil {
+		return err
+	}
+	return domain.ErrRefreshTokenReused
+}
+
+// revokeSession ends a session together with its refresh token family.
+func (s *authService) revokeSession(ctx context.Context, userID int64, sessionID string) error {
+	if err := s.refreshTokens.RevokeRefreshTokenFamily(ctx, sessionID); err != nil {
+		return fmt.Errorf("revoke refresh token family: %w", err)
+	}
+	if err := s.sessions.RevokeSession(ctx, userID, sessionID); err != nil && !errors.Is(err, domain.ErrSessionNotFound) {
+		return fmt.Errorf("revoke session: %w", err)
+	}
+	return nil
+}
+
+// authenticate verifies the password unless the client or the account is locked out after failed
+// logins, and counts the failures towards the next lock.
+func (s *authService) authenticate(ctx context.Context, login, password string, client domain.ClientInfo) (*domain.UserResponse, error) {
+	var subjects []domain.LoginSubject
+	if client.IP != "" {
+		subjects = append(subjects, domain.IPSubject(client.IP))
+	}
+	// Locked clients are turned away before the password is hashed.
+	if err := s.lockout.Check(ctx, subjects...); err != nil {
+		loginAttempts.WithLabelValues(loginResult(err)).Inc()
+		return nil, err
+	}
+
+	user, authErr := s.userService.Authenticate(ctx, login, password)
+	var invalidPassword *domain.InvalidPasswordError
+	switch {
+	case authErr == nil:
+		subjects = append(subjects, domain.AccountSubject(user.ID))
+	case errors.As(authErr, &invalidPassword):
+		subjects = append(subjects, domain.AccountSubject(invalidPassword.UserID))
+	}
+	// A locked account gets the same answer whether or not the password is right.
+	if err := s.lockout.Check(ctx, subjects...); err != nil {
+		loginAttempts.WithLabelValues(loginResult(err)).Inc()
+		return nil, err
+	}
+	loginAttempts.WithLabelValues(loginResult(authErr)).Inc()
+
+	switch {
+	case authErr == nil:
+		if err := s.lockout.RecordSuccess(ctx, domain.AccountSubject(user.ID)); err != nil {
+			return nil, fmt.Errorf("reset login failures: %w", err)
+		}
+		return user, nil
+	case errors.Is(authErr, domain.ErrInvalidCredentials):
+		if err := s.lockout.RecordFailure(ctx, subjects...); err != nil {
+			return nil, fmt.Errorf("record login failure: %w", err)
+		}
+	}
+	return nil, authErr
+}
+
+// loginResult labels a login attempt in the login attempts metric.
+func loginResult(err error) string {
+	switch {
+	case err == nil:
+		return "success"
+	case errors.Is(err, domain.ErrLoginLocked):
+		return "locked"
+	case errors.Is(err, domain.ErrInvalidCredentials):
+		return "failure"
+	default:
+		return "error"
+	}
+}
+
+// completeLogin challenges users with MFA and starts the session of everyone else.
+func (s *authService) completeLogin(ctx context.Context, user *domain.UserResponse, client domain.ClientInfo) (*domain.LoginResult, error) {
+	challenge, err := s.mfa.StartChallenge(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if challenge != nil {
+		return &domain.LoginResult{MFAChallenge: challenge}, nil
+	}
+	tokens, err := s.startSession(ctx, user, client)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.LoginResult{Tokens: tokens}, nil
+}
+
+func (s *authService) startSession(ctx context.Context, user *domain.UserResponse, client domain.ClientInfo) (*domain.AuthTokens, error) {
+	sessionID, err := tokenx.NewID()
+	if err != nil {
+		return nil, err
+	}
+	err = s.sessions.CreateSession(ctx, &domain.Session{
+		ID:             sessionID,
+		UserID:         user.ID,
+		OrganizationID: user.OrganizationID,
+		Device:         client.Device,
+		IP:             client.IP,
+		UserAgent:      client.UserAgent,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create session: %w", err)
+	}
+	return s.issueTokens(ctx, user, sessionID)
+}
+
+// issueTokens issues an access token bound to the session and a refresh token in the session's family.
+func (s *authService) issueTokens(ctx context.Context, user *domain.UserResponse, sessionID string) (*domain.AuthTokens, error) {
+	access, err := s.access.UserAccess(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("resolve access: %w", err)
+	}
+	claims := tokenx.NewClaims(user.ID, user.Username, access.Roles)
+	claims.OrganizationID = user.OrganizationID
+	claims.OrganizationRole = user.OrganizationRole
+	claims.Permissions = access.Permissions
+	claims.SessionID = sessionID
+	accessToken, err := s.tokens.Issue(claims)
+	if err != nil {
+		return nil, fmt.Errorf("issue access token: %w", err)
+	}
+
+	refreshToken, err := tokenx.NewOpaque()
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.refreshTokens.CreateRefreshToken(ctx, &domain.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  sessionID,
+		TokenHash: tokenx.HashOpaque(refreshToken),
+		ExpiresAt: s.now().Add(s.refreshTokenTTL),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("store refresh token: %w", err)
+	}
+
+	return &domain.AuthTokens{
+		AccessToken:  accessToken,
+		TokenType:    tokenTypeBearer,
+		ExpiresIn:    int64(s.tokens.TTL().Seconds()),
+		RefreshToken: refreshToken,
+	}, nil
+}
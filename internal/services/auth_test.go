@@ -0,0 +1,592 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/kerim-dauren/user-service/pkg/tokenx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memRefreshTokens is an in-memory domain.RefreshTokenStorage.
+type memRefreshTokens struct {
+	mu     sync.Mutex
+	nextID int64
+	tokens map[int64]*domain.RefreshToken
+}
+
+func newMemRefreshTokens() *memRefreshTokens {
+	return &memRefreshTokens{tokens: map[int64]*domain.RefreshToken{}}
+}
+
+func (m *memRefreshTokens) CreateRefreshToken(_ context.Context, t *domain.RefreshToken) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nextID++
+	stored := *t
+	stored.ID = m.nextID
+	m.tokens[stored.ID] = &stored
+	return stored.ID, nil
+}
+
+func (m *memRefreshTokens) GetRefreshTokenByHash(_ context.Context, hash string) (*domain.RefreshToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, t := range m.tokens {
+		if t.TokenHash == hash {
+			found := *t
+			return &found, nil
+		}
+	}
+	return nil, domain.ErrRefreshTokenNotFound
+}
+
+func (m *memRefreshTokens) MarkRefreshTokenUsed(_ context.Context, id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t := m.tokens[id]
+	if t.UsedAt != nil || t.RevokedAt != nil {
+		return domain.ErrRefreshTokenReused
+	}
+	now := time.Now()
+	t.UsedAt = &now
+	return nil
+}
+
+func (m *memRefreshTokens) RevokeRefreshTokenFamily(_ context.Context, familyID string) error {
+	return m.revoke(func(t *domain.RefreshToken) bool { return t.FamilyID == familyID })
+}
+
+func (m *memRefreshTokens) RevokeUserRefreshTokens(_ context.Context, userID int64) error {
+	return m.revoke(func(t *domain.RefreshToken) bool { return t.UserID == userID })
+}
+
+func (m *memRefreshTokens) revoke(match func(*domain.RefreshToken) bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	for _, t := range m.tokens {
+		if match(t) && t.RevokedAt == nil {
+			t.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+// memSessions is an in-memory domain.SessionStorage.
+type memSessions struct {
+	mu       sync.Mutex
+	sessions map[string]*domain.Session
+}
+
+func newMemSessions() *memSessions {
+	return &memSessions{sessions: map[string]*domain.Session{}}
+}
+
+func (m *memSessions) CreateSession(_ context.Context, s *domain.Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored := *s
+	stored.CreatedAt = time.Now()
+	stored.LastSeenAt = stored.CreatedAt
+	m.sessions[s.ID] = &stored
+	return nil
+}
+
+func (m *memSessions) GetSession(_ context.Context, id string) (*domain.Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.sessions[id]
+	if !ok {
+		return nil, domain.ErrSessionNotFound
+	}
+	found := *s
+	return &found, nil
+}
+
+func (m *memSessions) ListUserSessions(_ context.Context, userID int64) ([]*domain.Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var sessions []*domain.Session
+	for _, s := range m.sessions {
+		if s.UserID == userID && s.RevokedAt == nil {
+			found := *s
+			sessions = append(sessions, &found)
+		}
+	}
+	return sessions, nil
+}
+
+func (m *memSessions) TouchSession(_ context.Context, id string, ip string, lastSeenAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s, ok := m.sessions[id]; ok {
+		s.IP, s.LastSeenAt = ip, lastSeenAt
+	}
+	return nil
+}
+
+func (m *memSessions) RevokeSession(_ context.Context, userID int64, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.sessions[id]
+	if !ok || s.UserID != userID || s.RevokedAt != nil {
+		return domain.ErrSessionNotFound
+	}
+	now := time.Now()
+	s.RevokedAt = &now
+	return nil
+}
+
+func (m *memSessions) RevokeUserSessions(_ context.Context, userID int64, exceptID string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var revoked []string
+	now := time.Now()
+	for _, s := range m.sessions {
+		if s.UserID == userID && s.ID != exceptID && s.RevokedAt == nil {
+			s.RevokedAt = &now
+			revoked = append(revoked, s.ID)
+		}
+	}
+	return revoked, nil
+}
+
+func newTestTokenManager(t *testing.T) *tokenx.Manager {
+	t.Helper()
+	m, err := tokenx.NewManager(tokenx.Config{Algorithm: tokenx.AlgorithmEdDSA, Issuer: "test"})
+	if err != nil {
+		t.Fatalf("failed to create token manager: %v", err)
+	}
+	return m
+}
+
+type authTestEnv struct {
+	refreshTokens *memRefreshTokens
+	sessions      *memSessions
+}
+
+func newTestAuthService(t *testing.T, userService domain.UserService) (domain.AuthService, *authTestEnv) {
+	t.Helper()
+	env := &authTestEnv{refreshTokens: newMemRefreshTokens(), sessions: newMemSessions()}
+	service := NewAuthService(slog.Default(), userService, newTestTokenManager(t), env.refreshTokens, env.sessions,
+		withoutMFA(t), nil, nil, withoutLockout(t), staticAccess{}, time.Hour, LoginPolicy{})
+	return service, env
+}
+
+// withoutMFA returns an MFA service for which no user has MFA enabled.
+func withoutMFA(t *testing.T) domain.MFAService {
+	mfa := domain.NewMockMFAService(gomock.NewController(t))
+	mfa.EXPECT().StartChallenge(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	return mfa
+}
+
+// withoutLockout returns a lockout service under which nobody is ever locked out.
+func withoutLockout(t *testing.T) domain.LockoutService {
+	lockout := domain.NewMockLockoutService(gomock.NewController(t))
+	lockout.EXPECT().Check(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	lockout.EXPECT().RecordFailure(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	lockout.EXPECT().RecordSuccess(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	return lockout
+}
+
+// login signs alice in with her password and returns the tokens of the new session.
+func login(ctx context.Context, t *testing.T, service domain.AuthService) *domain.AuthTokens {
+	t.Helper()
+	result, err := service.Login(ctx, "alice", "password123", client)
+	require.NoError(t, err)
+	require.NotNil(t, result.Tokens)
+	return result.Tokens
+}
+
+var client = domain.ClientInfo{Device: "laptop", IP: "10.0.0.1", UserAgent: "test"}
+
+var alice = &domain.UserResponse{ID: 1, Username: "alice", Email: "alice@example.com"}
+
+func TestAuthService_Login(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := domain.NewMockUserService(ctrl)
+	service, _ := newTestAuthService(t, userService)
+	ctx := context.Background()
+
+	userService.EXPECT().Authenticate(ctx, "alice", "password123").Return(alice, nil)
+
+	tokens := login(ctx, t, service)
+	assert.Equal(t, "Bearer", tokens.TokenType)
+	assert.Equal(t, int64(900), tokens.ExpiresIn)
+	assert.NotEmpty(t, tokens.RefreshToken)
+
+	principal, err := service.VerifyAccessToken(ctx, tokens.AccessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), principal.UserID)
+	assert.Equal(t, "alice", principal.Username)
+}
+
+func TestAuthService_Login_InvalidCredentials(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := domain.NewMockUserService(ctrl)
+	service, _ := newTestAuthService(t, userService)
+	ctx := context.Background()
+
+	userService.EXPECT().Authenticate(ctx, "alice", "wrong").Return(nil, domain.ErrInvalidCredentials)
+
+	result, err := service.Login(ctx, "alice", "wrong", client)
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	assert.Nil(t, result)
+}
+
+func TestAuthService_Login_RequireVerifiedEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := domain.NewMockUserService(ctrl)
+	sessions := newMemSessions()
+	service := NewAuthService(slog.Default(), userService, newTestTokenManager(t), newMemRefreshTokens(), sessions,
+		withoutMFA(t), nil, nil, withoutLockout(t), staticAccess{}, time.Hour, LoginPolicy{RequireVerifiedEmail: true})
+	ctx := context.Background()
+
+	userService.EXPECT().Authenticate(ctx, "alice", "password123").Return(alice, nil)
+
+	result, err := service.Login(ctx, "alice", "password123", client)
+	assert.ErrorIs(t, err, domain.ErrEmailNotVerified)
+	assert.Nil(t, result)
+	assert.Empty(t, sessions.sessions)
+
+	verifiedAt := time.Now()
+	verified := *alice
+	verified.EmailVerifiedAt = &verifiedAt
+	userService.EXPECT().Authenticate(ctx, "alice", "password123").Return(&verified, nil)
+
+	tokens := login(ctx, t, service)
+	assert.NotEmpty(t, tokens.AccessToken)
+}
+
+func TestAuthService_VerifyAccessToken_Invalid(t *testing.T) {
+	service, _ := newTestAuthService(t, nil)
+
+	principal, err := service.VerifyAccessToken(context.Background(), "not-a-token")
+	assert.ErrorIs(t, err, domain.ErrUnauthorized)
+	assert.Nil(t, principal)
+}
+
+func TestAuthService_Refresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := domain.NewMockUserService(ctrl)
+	service, _ := newTestAuthService(t, userService)
+	ctx := context.Background()
+
+	userService.EXPECT().Authenticate(ctx, "alice", "password123").Return(alice, nil)
+	userService.EXPECT().GetUserByID(inOrganization(domain.DefaultOrganizationID), int64(1)).Return(alice, nil).Times(2)
+
+	first := login(ctx, t, service)
+
+	second, err := service.Refresh(ctx, first.RefreshToken, client)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+
+	third, err := service.Refresh(ctx, second.RefreshToken, client)
+	require.NoError(t, err)
+	assert.NotEmpty(t, third.AccessToken)
+}
+
+func TestAuthService_Refresh_ReuseRevokesFamily(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := domain.NewMockUserService(ctrl)
+	service, _ := newTestAuthService(t, userService)
+	ctx := context.Background()
+
+	userService.EXPECT().Authenticate(ctx, "alice", "password123").Return(alice, nil)
+	userService.EXPECT().GetUserByID(inOrganization(domain.DefaultOrganizationID), int64(1)).Return(alice, nil).Times(1)
+
+	stolen := login(ctx, t, service)
+	rotated, err := service.Refresh(ctx, stolen.RefreshToken, client)
+	require.NoError(t, err)
+
+	_, err = service.Refresh(ctx, stolen.RefreshToken, client)
+	assert.ErrorIs(t, err, domain.ErrRefreshTokenReused)
+
+	// The legitimately rotated token belongs to the same family and must be dead too.
+	_, err = service.Refresh(ctx, rotated.RefreshToken, client)
+	assert.ErrorIs(t, err, domain.ErrInvalidRefreshToken)
+}
+
+func TestAuthService_Refresh_OtherOrganization(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := domain.NewMockUserService(ctrl)
+	service, _ := newTestAuthService(t, userService)
+	ctx := context.Background()
+
+	member := &domain.UserResponse{ID: 1, Username: "alice", OrganizationID: 7, OrganizationRole: domain.OrganizationRoleMember}
+	userService.EXPECT().Authenticate(ctx, "alice", "password123").Return(member, nil)
+	tokens := login(ctx, t, service)
+
+	// A failed lookup leaves the token unspent, so that the retry is not taken for a reuse.
+	userService.EXPECT().GetUserByID(inOrganization(7), int64(1)).Return(nil, errors.New("connection reset"))
+	_, err := service.Refresh(ctx, tokens.RefreshToken, client)
+	require.Error(t, err)
+
+	// The request names no tenant; the session's organization is used all the same.
+	userService.EXPECT().GetUserByID(inOrganization(7), int64(1)).Return(member, nil)
+	rotated, err := service.Refresh(ctx, tokens.RefreshToken, client)
+	require.NoError(t, err)
+	principal, err := service.VerifyAccessToken(ctx, rotated.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), principal.OrganizationID)
+}
+
+func TestAuthService_Refresh_Expired(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := domain.NewMockUserService(ctrl)
+	service, _ := newTestAuthService(t, userService)
+	ctx := context.Background()
+
+	userService.EXPECT().Authenticate(ctx, "alice", "password123").Return(alice, nil)
+
+	tokens := login(ctx, t, service)
+
+	service.(*authService).now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	_, err := service.Refresh(ctx, tokens.RefreshToken, client)
+	assert.ErrorIs(t, err, domain.ErrInvalidRefreshToken)
+}
+
+func TestAuthService_Refresh_Unknown(t *testing.T) {
+	service, _ := newTestAuthService(t, nil)
+
+	_, err := service.Refresh(context.Background(), "unknown", client)
+	assert.ErrorIs(t, err, domain.ErrInvalidRefreshToken)
+}
+
+func TestAuthService_Logout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := domain.NewMockUserService(ctrl)
+	service, _ := newTestAuthService(t, userService)
+	ctx := context.Background()
+
+	userService.EXPECT().Authenticate(ctx, "alice", "password123").Return(alice, nil)
+
+	tokens := login(ctx, t, service)
+
+	assert.NoError(t, service.Logout(ctx, tokens.RefreshToken))
+	_, err := service.Refresh(ctx, tokens.RefreshToken, client)
+	assert.ErrorIs(t, err, domain.ErrInvalidRefreshToken)
+
+	// Logging out twice or with an unknown token is not an error.
+	assert.NoError(t, service.Logout(ctx, tokens.RefreshToken))
+	assert.NoError(t, service.Logout(ctx, "unknown"))
+}
+
+func TestAuthService_Login_StartsSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := domain.NewMockUserService(ctrl)
+	service, env := newTestAuthService(t, userService)
+	ctx := context.Background()
+
+	userService.EXPECT().Authenticate(ctx, "alice", "password123").Return(alice, nil)
+
+	tokens := login(ctx, t, service)
+
+	principal, err := service.VerifyAccessToken(ctx, tokens.AccessToken)
+	require.NoError(t, err)
+	session, err := env.sessions.GetSession(ctx, principal.SessionID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), session.UserID)
+	assert.Equal(t, "laptop", session.Device)
+	assert.Equal(t, "10.0.0.1", session.IP)
+	assert.Equal(t, "test", session.UserAgent)
+}
+
+func TestAuthService_VerifyAccessToken_RevokedSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := domain.NewMockUserService(ctrl)
+	service, env := newTestAuthService(t, userService)
+	ctx := context.Background()
+
+	userService.EXPECT().Authenticate(ctx, "alice", "password123").Return(alice, nil)
+
+	tokens := login(ctx, t, service)
+	principal, err := service.VerifyAccessToken(ctx, tokens.AccessToken)
+	require.NoError(t, err)
+
+	sessions := NewSessionService(slog.Default(), env.sessions, env.refreshTokens)
+	require.NoError(t, sessions.RevokeSession(ctx, 1, principal.SessionID))
+
+	_, err = service.VerifyAccessToken(ctx, tokens.AccessToken)
+	assert.ErrorIs(t, err, domain.ErrUnauthorized)
+	_, err = service.Refresh(ctx, tokens.RefreshToken, client)
+	assert.ErrorIs(t, err, domain.ErrInvalidRefreshToken)
+}
+
+func TestAuthService_Login_MFA(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := domain.NewMockUserService(ctrl)
+	mfa := domain.NewMockMFAService(ctrl)
+	sessions := newMemSessions()
+	service := NewAuthService(slog.Default(), userService, newTestTokenManager(t), newMemRefreshTokens(), sessions,
+		mfa, nil, nil, withoutLockout(t), staticAccess{}, time.Hour, LoginPolicy{})
+	ctx := context.Background()
+
+	userService.EXPECT().Authenticate(ctx, "alice", "password123").Return(alice, nil)
+	mfa.EXPECT().StartChallenge(ctx, int64(1)).Return(&domain.MFAChallenge{Token: "challenge", ExpiresIn: 300}, nil)
+
+	result, err := service.Login(ctx, "alice", "password123", client)
+	require.NoError(t, err)
+	assert.Nil(t, result.Tokens)
+	assert.Equal(t, "challenge", result.MFAChallenge.Token)
+	assert.Empty(t, sessions.sessions, "no session before the second factor")
+
+	mfa.EXPECT().CompleteChallenge(ctx, "challenge", "000000").Return(int64(0), domain.ErrInvalidMFACode)
+	_, err = service.VerifyMFA(ctx, "challenge", "000000", client)
+	assert.ErrorIs(t, err, domain.ErrInvalidMFACode)
+
+	mfa.EXPECT().CompleteChallenge(ctx, "challenge", "123456").Return(int64(1), nil)
+	userService.EXPECT().WithUserTenant(ctx, int64(1)).Return(ctx, nil)
+	userService.EXPECT().GetUserByID(ctx, int64(1)).Return(alice, nil)
+	tokens, err := service.VerifyMFA(ctx, "challenge", "123456", client)
+	require.NoError(t, err)
+	principal, err := service.VerifyAccessToken(ctx, tokens.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), principal.UserID)
+	assert.Len(t, sessions.sessions, 1)
+}
+
+func TestAuthService_LoginWithPasskey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := domain.NewMockUserService(ctrl)
+	passkeys := domain.NewMockPasskeyService(ctrl)
+	sessions := newMemSessions()
+	service := NewAuthService(slog.Default(), userService, newTestTokenManager(t), newMemRefreshTokens(), sessions,
+		domain.NewMockMFAService(ctrl), passkeys, nil, withoutLockout(t), staticAccess{}, time.Hour, LoginPolicy{})
+	ctx := context.Background()
+
+	passkeys.EXPECT().FinishLogin(ctx, "session", []byte("bad")).Return(int64(0), domain.ErrInvalidPasskey)
+	_, err := service.LoginWithPasskey(ctx, "session", []byte("bad"), client)
+	assert.ErrorIs(t, err, domain.ErrInvalidPasskey)
+
+	// No MFA challenge is started: the mock MFA service expects no calls.
+	passkeys.EXPECT().FinishLogin(ctx, "session", []byte("assertion")).Return(int64(1), nil)
+	userService.EXPECT().WithUserTenant(ctx, int64(1)).Return(ctx, nil)
+	userService.EXPECT().GetUserByID(ctx, int64(1)).Return(alice, nil)
+	tokens, err := service.LoginWithPasskey(ctx, "session", []byte("assertion"), client)
+	require.NoError(t, err)
+	principal, err := service.VerifyAccessToken(ctx, tokens.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), principal.UserID)
+	assert.Len(t, sessions.sessions, 1)
+}
+
+func TestAuthService_LoginWithMagicLink(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := domain.NewMockUserService(ctrl)
+	mfa := domain.NewMockMFAService(ctrl)
+	magicLinks := domain.NewMockMagicLinkService(ctrl)
+	sessions := newMemSessions()
+	service := NewAuthService(slog.Default(), userService, newTestTokenManager(t), newMemRefreshTokens(), sessions,
+		mfa, nil, magicLinks, withoutLockout(t), staticAccess{}, time.Hour, LoginPolicy{RequireVerifiedEmail: true})
+	ctx := context.Background()
+
+	magicLinks.EXPECT().ConsumeMagicLink(ctx, "used").Return(nil, domain.ErrInvalidMagicLinkToken)
+	_, err := service.LoginWithMagicLink(ctx, "used", client)
+	assert.ErrorIs(t, err, domain.ErrInvalidMagicLinkToken)
+
+	verifiedAt := time.Now()
+	verified := *alice
+	verified.EmailVerifiedAt = &verifiedAt
+	magicLinks.EXPECT().ConsumeMagicLink(ctx, "token").Return(&verified, nil)
+	mfa.EXPECT().StartChallenge(ctx, int64(1)).Return(nil, nil)
+	result, err := service.LoginWithMagicLink(ctx, "token", client)
+	require.NoError(t, err)
+	principal, err := service.VerifyAccessToken(ctx, result.Tokens.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), principal.UserID)
+
+	magicLinks.EXPECT().ConsumeMagicLink(ctx, "mfa").Return(&verified, nil)
+	mfa.EXPECT().StartChallenge(ctx, int64(1)).Return(&domain.MFAChallenge{Token: "challenge", ExpiresIn: 300}, nil)
+	result, err = service.LoginWithMagicLink(ctx, "mfa", client)
+	require.NoError(t, err)
+	assert.Nil(t, result.Tokens)
+	assert.Equal(t, "challenge", result.MFAChallenge.Token)
+	assert.Len(t, sessions.sessions, 1, "no session before the second factor")
+}
+
+func TestAuthService_Login_Lockout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := domain.NewMockUserService(ctrl)
+	lockout, storage := newTestLockoutService(t, userService)
+	service := NewAuthService(slog.Default(), userService, newTestTokenManager(t), newMemRefreshTokens(), newMemSessions(),
+		withoutMFA(t), nil, nil, lockout, staticAccess{}, time.Hour, LoginPolicy{})
+	ctx := context.Background()
+	now := time.Now()
+	lockout.now = func() time.Time { return now }
+
+	userService.EXPECT().Authenticate(ctx, "nobody", "wrong").Return(nil, domain.ErrInvalidCredentials)
+	_, err := service.Login(ctx, "nobody", "wrong", client)
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	assert.Equal(t, 1, storage.failures[domain.IPSubject(client.IP)].Failures, "unknown users count against the IP only")
+
+	userService.EXPECT().Authenticate(ctx, "alice", "wrong").Return(nil, &domain.InvalidPasswordError{UserID: 1}).Times(5)
+	for range 5 {
+		_, err = service.Login(ctx, "alice", "wrong", client)
+		assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+		now = now.Add(time.Minute) // Past the backoff delay
+	}
+
+	// The right password gets the same answer as a wrong one while the account is locked.
+	userService.EXPECT().Authenticate(ctx, "alice", "password123").Return(alice, nil).Times(2)
+	_, err = service.Login(ctx, "alice", "password123", client)
+	assert.ErrorIs(t, err, domain.ErrLoginLocked)
+
+	now = now.Add(15 * time.Minute)
+	login(ctx, t, service)
+	_, locked := storage.failures[domain.AccountSubject(1)]
+	assert.False(t, locked, "a successful login forgets the failures of the account")
+}
+
+func TestAuthService_Roles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := domain.NewMockUserService(ctrl)
+	service := NewAuthService(slog.Default(), userService, newTestTokenManager(t), newMemRefreshTokens(), newMemSessions(),
+		withoutMFA(t), nil, nil, withoutLockout(t), staticAccess{
+			1: {Roles: []string{domain.RoleAdmin}, Permissions: []string{domain.PermissionUsersRead}},
+		}, time.Hour, LoginPolicy{})
+	ctx := context.Background()
+
+	userService.EXPECT().Authenticate(ctx, "alice", "password123").Return(alice, nil)
+	principal, err := service.VerifyAccessToken(ctx, login(ctx, t, service).AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, []string{domain.RoleAdmin}, principal.Roles)
+	assert.True(t, principal.HasPermission(domain.PermissionUsersRead))
+	assert.False(t, principal.HasPermission(domain.PermissionUsersDelete))
+}
+
+func TestAuthService_Organization(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := domain.NewMockUserService(ctrl)
+	service, _ := newTestAuthService(t, userService)
+	ctx := context.Background()
+
+	member := &domain.UserResponse{ID: 1, Username: "alice", OrganizationID: 7, OrganizationRole: domain.OrganizationRoleAdmin}
+	userService.EXPECT().Authenticate(ctx, "alice", "password123").Return(member, nil)
+	principal, err := service.VerifyAccessToken(ctx, login(ctx, t, service).AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), principal.OrganizationID)
+	assert.Equal(t, domain.OrganizationRoleAdmin, principal.OrganizationRole)
+
+	// The token wins over any tenant named by the request.
+	tenantCtx := domain.ContextWithTenant(domain.ContextWithPrincipal(ctx, principal), 3)
+	assert.Equal(t, int64(7), domain.TenantFromContext(tenantCtx))
+}
+
+// staticAccess grants fixed roles and permissions by user ID.
+type staticAccess map[int64]*domain.Access
+
+func (a staticAccess) UserAccess(_ context.Context, userID int64) (*domain.Access, error) {
+	if access, ok := a[userID]; ok {
+		return access, nil
+	}
+	return &domain.Access{}, nil
+}
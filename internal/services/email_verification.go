@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/kerim-dauren/user-service/pkg/tokenx"
+)
+
+// EmailVerificationConfig configures verification links.
+type EmailVerificationConfig struct {
+	TTL         time.Duration // Lifetime of a verification token
+	URL         string        // Page that receives the token in the "token" query parameter
+	Limit       int           // Links sent to one email per LimitWindow; further requests are dropped
+	LimitWindow time.Duration
+}
+
+type emailVerificationService struct {
+	logger        *slog.Logger
+	userService   domain.UserService
+	verifications domain.EmailVerificationStorage
+	notifier      domain.Notifier
+	cfg           EmailVerificationConfig
+	now           func() time.Time
+}
+
+func NewEmailVerificationService(
+	logger *slog.Logger,
+	userService domain.UserService,
+	verifications domain.EmailVerificationStorage,
+	notifier domain.Notifier,
+	cfg EmailVerificationConfig,
+) domain.EmailVerificationService {
+	return &emailVerificationService{
+		logger:        logger,
+		userService:   userService,
+		verifications: verifications,
+		notifier:      notifier,
+		cfg:           cfg,
+		now:           time.Now,
+	}
+}
+
+func (s *emailVerificationService) SendEmailVerification(ctx context.Context, email string) (err error) {
+	defer observeDuration(s.logger, "SendEmailVerification", &err)()
+
+	if email == "" {
+		return domain.NewValidationError(domain.FieldViolation{Field: "email", Description: "is required"})
+	}
+	user, err := s.userService.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	token, err := tokenx.NewOpaque()
+	if err != nil {
+		return err
+	}
+	now := s.now()
+	expiresAt := now.Add(s.cfg.TTL)
+	_, err = s.verifications.CreateEmailVerificationToken(ctx, &domain.EmailVerificationToken{
+		UserID:    user.ID,
+		Email:     user.Email,
+		TokenHash: tokenx.HashOpaque(token),
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+	}, s.cfg.Limit, now.Add(-s.cfg.LimitWindow))
+	if errors.Is(err, domain.ErrEmailThrottled) {
+		s.logger.Warn("email verification throttled", "user_id", user.ID)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("create email verification token: %w", err)
+	}
+	link, err := actionLink(s.cfg.URL, token)
+	if err != nil {
+		return err
+	}
+	notifyAsync(ctx, s.logger, s.notifier, domain.Notification{
+		Kind:      domain.NotificationEmailVerification,
+		UserID:    user.ID,
+		Email:     user.Email,
+		Username:  user.Username,
+		Link:      link,
+		ExpiresAt: expiresAt,
+	})
+	return nil
+}
+
+func (s *emailVerificationService) ConfirmEmail(ctx context.Context, token string) (err error) {
+	defer observeDuration(s.logger, "ConfirmEmail", &err)()
+
+	if token == "" {
+		return domain.ErrInvalidEmailVerificationToken
+	}
+	verification, err := s.verifications.ConsumeEmailVerificationToken(ctx, tokenx.HashOpaque(token), s.now())
+	if err != nil {
+		return err
+	}
+	// The link, not the caller, decides which organization the account is in.
+	if ctx, err = s.userService.WithUserTenant(ctx, verification.UserID); err == nil {
+		err = s.userService.VerifyEmail(ctx, verification.UserID, verification.Email)
+	}
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			// The user was deleted or changed their email after the link was sent.
+			return domain.ErrInvalidEmailVerificationToken
+		}
+		return err
+	}
+	if err = s.verifications.DeleteUserEmailVerificationTokens(ctx, verification.UserID); err != nil {
+		return fmt.Errorf("delete email verification tokens: %w", err)
+	}
+	return nil
+}
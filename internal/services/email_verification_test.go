@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/kerim-dauren/user-service/pkg/tokenx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memEmailVerifications is an in-memory domain.EmailVerificationStorage.
+type memEmailVerifications struct {
+	mu     sync.Mutex
+	nextID int64
+	tokens map[int64]*domain.EmailVerificationToken
+}
+
+func newMemEmailVerifications() *memEmailVerifications {
+	return &memEmailVerifications{tokens: map[int64]*domain.EmailVerificationToken{}}
+}
+
+func (m *memEmailVerifications) CreateEmailVerificationToken(_ context.Context, t *domain.EmailVerificationToken, limit int, since time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	count := 0
+	for _, stored := range m.tokens {
+		if stored.Email == t.Email && stored.CreatedAt.After(since) {
+			count++
+		}
+	}
+	if count >= limit {
+		return 0, domain.ErrEmailThrottled
+	}
+	m.nextID++
+	stored := *t
+	stored.ID = m.nextID
+	m.tokens[stored.ID] = &stored
+	return stored.ID, nil
+}
+
+func (m *memEmailVerifications) ConsumeEmailVerificationToken(_ context.Context, hash string, now time.Time) (*domain.EmailVerificationToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, t := range m.tokens {
+		if t.TokenHash == hash && t.UsedAt == nil && t.ExpiresAt.After(now) {
+			t.UsedAt = &now
+			consumed := *t
+			return &consumed, nil
+		}
+	}
+	return nil, domain.ErrInvalidEmailVerificationToken
+}
+
+func (m *memEmailVerifications) DeleteUserEmailVerificationTokens(_ context.Context, userID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, t := range m.tokens {
+		if t.UserID == userID {
+			delete(m.tokens, id)
+		}
+	}
+	return nil
+}
+
+func newTestEmailVerificationService(t *testing.T, userService domain.UserService) (*emailVerificationService, *memEmailVerifications, chanNotifier) {
+	t.Helper()
+	verifications := newMemEmailVerifications()
+	notifier := make(chanNotifier, 1)
+	service := NewEmailVerificationService(slog.Default(), userService, verifications, notifier, EmailVerificationConfig{
+		TTL:         24 * time.Hour,
+		URL:         "https://example.com/verify",
+		Limit:       2,
+		LimitWindow: time.Hour,
+	})
+	return service.(*emailVerificationService), verifications, notifier
+}
+
+// sendVerification requests a link for alice and returns its token.
+func sendVerification(t *testing.T, service domain.EmailVerificationService, notifier chanNotifier) string {
+	t.Helper()
+	require.NoError(t, service.SendEmailVerification(context.Background(), alice.Email))
+	link, err := url.Parse(receive(t, notifier).Link)
+	require.NoError(t, err)
+	return link.Query().Get("token")
+}
+
+func TestEmailVerificationService_Send(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := domain.NewMockUserService(ctrl)
+	service, verifications, notifier := newTestEmailVerificationService(t, userService)
+
+	userService.EXPECT().GetUserByEmail(gomock.Any(), alice.Email).Return(alice, nil)
+
+	require.NoError(t, service.SendEmailVerification(context.Background(), alice.Email))
+
+	n := receive(t, notifier)
+	assert.Equal(t, domain.NotificationEmailVerification, n.Kind)
+	assert.Equal(t, alice.Email, n.Email)
+	link, err := url.Parse(n.Link)
+	require.NoError(t, err)
+
+	require.Len(t, verifications.tokens, 1)
+	stored := verifications.tokens[1]
+	assert.Equal(t, tokenx.HashOpaque(link.Query().Get("token")), stored.TokenHash)
+	assert.Equal(t, alice.Email, stored.Email)
+}
+
+func TestEmailVerificationService_Send_Throttled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := domain.NewMockUserService(ctrl)
+	service, verifications, notifier := newTestEmailVerificationService(t, userService)
+	ctx := context.Background()
+
+	userService.EXPECT().GetUserByEmail(ctx, alice.Email).Return(alice, nil).Times(4)
+	sendVerification(t, service, notifier)
+	sendVerification(t, service, notifier)
+
+	assert.NoError(t, service.SendEmailVerification(ctx, alice.Email), "throttled requests look like any other")
+	assert.Len(t, verifications.tokens, 2)
+	assert.Empty(t, notifier)
+
+	service.now = func() time.Time { return time.Now().Add(61 * time.Minute) }
+	sendVerification(t, service, notifier)
+	assert.Len(t, verifications.tokens, 3)
+}
+
+func TestEmailVerificationService_Send_NothingToDo(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := domain.NewMockUserService(ctrl)
+	service, verifications, notifier := newTestEmailVerificationService(t, userService)
+	ctx := context.Background()
+
+	verifiedAt := time.Now()
+	verified := *alice
+	verified.EmailVerifiedAt = &verifiedAt
+	userService.EXPECT().GetUserByEmail(ctx, alice.Email).Return(&verified, nil)
+	userService.EXPECT().GetUserByEmail(ctx, "nobody@example.com").Return(nil, domain.ErrUserNotFound)
+
+	assert.NoError(t, service.SendEmailVerification(ctx, alice.Email))
+	assert.NoError(t, service.SendEmailVerification(ctx, "nobody@example.com"))
+	assert.Empty(t, verifications.tokens)
+	assert.Empty(t, notifier)
+}
+
+func TestEmailVerificationService_Confirm(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := domain.NewMockUserService(ctrl)
+	service, verifications, notifier := newTestEmailVerificationService(t, userService)
+	ctx := context.Background()
+
+	userService.EXPECT().GetUserByEmail(gomock.Any(), alice.Email).Return(alice, nil)
+	token := sendVerification(t, service, notifier)
+
+	userService.EXPECT().WithUserTenant(ctx, alice.ID).Return(ctx, nil)
+	userService.EXPECT().VerifyEmail(ctx, alice.ID, alice.Email).Return(nil)
+
+	require.NoError(t, service.ConfirmEmail(ctx, token))
+	assert.Empty(t, verifications.tokens)
+
+	err := service.ConfirmEmail(ctx, token)
+	assert.ErrorIs(t, err, domain.ErrInvalidEmailVerificationToken, "tokens are single-use")
+}
+
+func TestEmailVerificationService_Confirm_EmailChanged(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := domain.NewMockUserService(ctrl)
+	service, _, notifier := newTestEmailVerificationService(t, userService)
+	ctx := context.Background()
+
+	userService.EXPECT().GetUserByEmail(gomock.Any(), alice.Email).Return(alice, nil)
+	token := sendVerification(t, service, notifier)
+
+	userService.EXPECT().WithUserTenant(ctx, alice.ID).Return(ctx, nil)
+	userService.EXPECT().VerifyEmail(ctx, alice.ID, alice.Email).Return(domain.ErrUserNotFound)
+
+	err := service.ConfirmEmail(ctx, token)
+	assert.ErrorIs(t, err, domain.ErrInvalidEmailVerificationToken)
+}
+
+func TestEmailVerificationService_Confirm_Expired(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := domain.NewMockUserService(ctrl)
+	service, _, notifier := newTestEmailVerificationService(t, userService)
+
+	userService.EXPECT().GetUserByEmail(gomock.Any(), alice.Email).Return(alice, nil)
+	token := sendVerification(t, service, notifier)
+
+	service.now = func() time.Time { return time.Now().Add(25 * time.Hour) }
+
+	for _, token := range []string{token, "", "unknown"} {
+		err := service.ConfirmEmail(context.Background(), token)
+		assert.ErrorIs(t, err, domain.ErrInvalidEmailVerificationToken, token)
+	}
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/kerim-dauren/user-service/pkg/tokenx"
+)
+
+// InvitationConfig configures invitation links.
+type InvitationConfig struct {
+	TTL time.Duration // Lifetime of an invitation
+	URL string        // Page that receives the token in the "token" query parameter
+}
+
+type invitationService struct {
+	logger        *slog.Logger
+	userService   domain.UserService
+	organizations domain.OrganizationStorage
+	invitations   domain.InvitationStorage
+	validator     *UserValidator
+	notifier      domain.Notifier
+	cfg           InvitationConfig
+	now           func() time.Time
+}
+
+func NewInvitationService(
+	logger *slog.Logger,
+	userService domain.UserService,
+	organizations domain.OrganizationStorage,
+	invitations domain.InvitationStorage,
+	validator *UserValidator,
+	notifier domain.Notifier,
+	cfg InvitationConfig,
+) domain.InvitationService {
+	return &invitationService{
+		logger:        logger,
+		userService:   userService,
+		organizations: organizations,
+		invitations:   invitations,
+		validator:     validator,
+		notifier:      notifier,
+		cfg:           cfg,
+		now:           time.Now,
+	}
+}
+
+func (s *invitationService) CreateInvitation(ctx context.Context, email, role string) (invitation *domain.Invitation, err error) {
+	defer observeDuration(s.logger, "CreateInvitation", &err)()
+
+	if err = s.validator.ValidateEmail(email); err != nil {
+		return nil, err
+	}
+	if err = validateOrganizationRole(role); err != nil {
+		return nil, err
+	}
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, domain.ErrUnauthorized
+	}
+	if role == domain.OrganizationRoleOwner && principal.OrganizationRole != domain.OrganizationRoleOwner {
+		return nil, domain.ErrForbidden
+	}
+	org, err := s.organizations.GetOrganization(ctx, domain.TenantFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := tokenx.NewOpaque()
+	if err != nil {
+		return nil, err
+	}
+	invitation = &domain.Invitation{
+		OrganizationID: org.ID,
+		Email:          email,
+		Role:           role,
+		InviterID:      principal.UserID,
+		Status:         domain.InvitationPending,
+		TokenHash:      tokenx.HashOpaque(token),
+		ExpiresAt:      s.now().Add(s.cfg.TTL),
+		CreatedAt:      s.now(),
+	}
+	if invitation.ID, err = s.invitations.CreateInvitation(ctx, invitation); err != nil {
+		return nil, fmt.Errorf("create invitation: %w", err)
+	}
+	link, err := actionLink(s.cfg.URL, token)
+	if err != nil {
+		return nil, err
+	}
+	notifyAsync(ctx, s.logger, s.notifier, domain.Notification{
+		Kind:         domain.NotificationInvitation,
+		Email:        email,
+		Link:         link,
+		ExpiresAt:    invitation.ExpiresAt,
+		Organization: org.Name,
+		Inviter:      principal.Username,
+	})
+	s.logger.Info("invitation created", "organization_id", org.ID, "invitation_id", invitation.ID, "role", role, "by", principal.UserID)
+	return invitation, nil
+}
+
+func (s *invitationService) ListInvitations(ctx context.Context) (invitations []*domain.Invitation, err error) {
+	defer observeDuration(s.logger, "ListInvitations", &err)()
+	return s.invitations.ListInvitations(ctx, domain.TenantFromContext(ctx))
+}
+
+func (s *invitationService) RevokeInvitation(ctx context.Context, id int64) (err error) {
+	defer observeDuration(s.logger, "RevokeInvitation", &err)()
+
+	orgID := domain.TenantFromContext(ctx)
+	if err = s.invitations.RevokeInvitation(ctx, orgID, id, s.now()); err != nil {
+		return err
+	}
+	s.logger.Info("invitation revoked", "organization_id", orgID, "invitation_id", id, "by", actorID(ctx))
+	return nil
+}
+
+func (s *invitationService) AcceptInvitation(ctx context.Context, req domain.AcceptInvitationRequest) (user *domain.UserResponse, err error) {
+	defer observeDuration(s.logger, "AcceptInvitation", &err)()
+
+	if req.Token == "" {
+		return nil, domain.ErrInvalidInvitationToken
+	}
+	tokenHash := tokenx.HashOpaque(req.Token)
+	invitation, err := s.invitations.GetPendingInvitation(ctx, tokenHash, s.now())
+	if err != nil {
+		return nil, err
+	}
+	// The invitation, not the caller, decides which organization the account is in.
+	ctx = domain.ContextWithTenant(ctx, invitation.OrganizationID)
+
+	// The account is found or created before the invitation is answered, so that a rejected
+	// username or password does not burn the link.
+	created := false
+	user, err = s.userService.GetUserByEmail(ctx, invitation.Email)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		user, err = s.createInvitee(ctx, invitation, req)
+		created = err == nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = s.invitations.RespondToInvitation(ctx, tokenHash, domain.InvitationAccepted, s.now()); err != nil {
+		if created {
+			// Lost a race against a revocation or another acceptance of the same link.
+			if deleteErr := s.userService.DeleteUser(ctx, user.ID); deleteErr != nil {
+				s.logger.Error("failed to delete invitee", "user_id", user.ID, "error", deleteErr)
+			}
+		}
+		return nil, err
+	}
+
+	switch {
+	case user.OrganizationID != invitation.OrganizationID:
+		// An account of another organization joins this one as well.
+		if err = s.organizations.AddMember(ctx, invitation.OrganizationID, user.ID, invitation.Role); err != nil {
+			return nil, fmt.Errorf("add invitee: %w", err)
+		}
+	case user.OrganizationRole != domain.OrganizationRoleOwner && user.OrganizationRole != invitation.Role:
+		// Invitations never demote an owner.
+		if err = s.organizations.SetMemberRole(ctx, invitation.OrganizationID, user.ID, invitation.Role); err != nil {
+			return nil, fmt.Errorf("set invitee role: %w", err)
+		}
+	}
+	if err = s.userService.VerifyEmail(ctx, user.ID, invitation.Email); err != nil {
+		return nil, fmt.Errorf("verify invitee email: %w", err)
+	}
+	s.logger.Info("invitation accepted", "organization_id", invitation.OrganizationID, "invitation_id", invitation.ID,
+		"user_id", user.ID, "new_account", created)
+	return s.userService.GetUserByID(ctx, user.ID)
+}
+
+func (s *invitationService) DeclineInvitation(ctx context.Context, token string) (err error) {
+	defer observeDuration(s.logger, "DeclineInvitation", &err)()
+
+	if token == "" {
+		return domain.ErrInvalidInvitationToken
+	}
+	invitation, err := s.invitations.RespondToInvitation(ctx, tokenx.HashOpaque(token), domain.InvitationDeclined, s.now())
+	if err != nil {
+		return err
+	}
+	s.logger.Info("invitation declined", "organization_id", invitation.OrganizationID, "invitation_id", invitation.ID)
+	return nil
+}
+
+// createInvitee creates the account of an invitee in the organization of the invitation through
+// UserService.CreateUser, which applies the usual validation and uniqueness checks.
+func (s *invitationService) createInvitee(ctx context.Context, invitation *domain.Invitation, req domain.AcceptInvitationRequest) (*domain.UserResponse, error) {
+	id, err := s.userService.CreateUser(ctx, &domain.User{
+		Username:         req.Username,
+		Email:            invitation.Email,
+		Password:         req.Password,
+		OrganizationID:   invitation.OrganizationID,
+		OrganizationRole: invitation.Role,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.userService.GetUserByID(ctx, id)
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/kerim-dauren/user-service/pkg/tokenx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memInvitations is an in-memory domain.InvitationStorage.
+type memInvitations struct {
+	mu          sync.Mutex
+	invitations []*domain.Invitation
+}
+
+func (m *memInvitations) CreateInvitation(_ context.Context, inv *domain.Invitation) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored := *inv
+	stored.ID = int64(len(m.invitations) + 1)
+	m.invitations = append(m.invitations, &stored)
+	return stored.ID, nil
+}
+
+func (m *memInvitations) ListInvitations(_ context.Context, orgID int64) ([]*domain.Invitation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var found []*domain.Invitation
+	for _, inv := range m.invitations {
+		if inv.OrganizationID == orgID {
+			found = append(found, inv)
+		}
+	}
+	return found, nil
+}
+
+func (m *memInvitations) GetPendingInvitation(_ context.Context, tokenHash string, now time.Time) (*domain.Invitation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if inv := m.pending(tokenHash, now); inv != nil {
+		copied := *inv
+		return &copied, nil
+	}
+	return nil, domain.ErrInvalidInvitationToken
+}
+
+func (m *memInvitations) RespondToInvitation(_ context.Context, tokenHash, status string, now time.Time) (*domain.Invitation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if inv := m.pending(tokenHash, now); inv != nil {
+		inv.Status, inv.RespondedAt = status, &now
+		copied := *inv
+		return &copied, nil
+	}
+	return nil, domain.ErrInvalidInvitationToken
+}
+
+func (m *memInvitations) RevokeInvitation(_ context.Context, orgID, id int64, now time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, inv := range m.invitations {
+		if inv.OrganizationID == orgID && inv.ID == id && inv.Status == domain.InvitationPending {
+			inv.Status, inv.RespondedAt = domain.InvitationRevoked, &now
+			return nil
+		}
+	}
+	return domain.ErrInvitationNotFound
+}
+
+func (m *memInvitations) pending(tokenHash string, now time.Time) *domain.Invitation {
+	for _, inv := range m.invitations {
+		if inv.TokenHash == tokenHash && inv.Status == domain.InvitationPending && inv.ExpiresAt.After(now) {
+			return inv
+		}
+	}
+	return nil
+}
+
+type invitationTestEnv struct {
+	service       domain.InvitationService
+	userService   *domain.MockUserService
+	organizations *domain.MockOrganizationStorage
+	invitations   *memInvitations
+	notifier      chanNotifier
+}
+
+func newTestInvitationService(t *testing.T) *invitationTestEnv {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	env := &invitationTestEnv{
+		userService:   domain.NewMockUserService(ctrl),
+		organizations: domain.NewMockOrganizationStorage(ctrl),
+		invitations:   &memInvitations{},
+		notifier:      make(chanNotifier, 1),
+	}
+	env.service = NewInvitationService(slog.Default(), env.userService, env.organizations, env.invitations,
+		testValidator(t), env.notifier, InvitationConfig{TTL: time.Hour, URL: "https://example.com/invitation"})
+	return env
+}
+
+// invite creates an invitation to organization 5 as an owner of it and returns the token from the link.
+func (env *invitationTestEnv) invite(t *testing.T, email, role string) string {
+	t.Helper()
+	ctx := domain.ContextWithPrincipal(context.Background(),
+		&domain.Principal{UserID: 1, Username: "owner", OrganizationID: 5, OrganizationRole: domain.OrganizationRoleOwner})
+	env.organizations.EXPECT().GetOrganization(gomock.Any(), int64(5)).Return(&domain.Organization{ID: 5, Name: "Acme"}, nil)
+
+	_, err := env.service.CreateInvitation(ctx, email, role)
+	require.NoError(t, err)
+	link, err := url.Parse(receive(t, env.notifier).Link)
+	require.NoError(t, err)
+	return link.Query().Get("token")
+}
+
+// inOrganization matches contexts acting in an organization.
+type inOrganization int64
+
+func (id inOrganization) Matches(x any) bool {
+	ctx, ok := x.(context.Context)
+	return ok && domain.TenantFromContext(ctx) == int64(id)
+}
+
+func (id inOrganization) String() string {
+	return fmt.Sprintf("acts in organization %d", int64(id))
+}
+
+func TestInvitationService_Create(t *testing.T) {
+	env := newTestInvitationService(t)
+	ctx := domain.ContextWithPrincipal(context.Background(),
+		&domain.Principal{UserID: 2, Username: "bob", OrganizationID: 5, OrganizationRole: domain.OrganizationRoleAdmin})
+
+	env.organizations.EXPECT().GetOrganization(gomock.Any(), int64(5)).Return(&domain.Organization{ID: 5, Name: "Acme"}, nil)
+	invitation, err := env.service.CreateInvitation(ctx, "carol@example.com", domain.OrganizationRoleAdmin)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), invitation.OrganizationID)
+	assert.Equal(t, int64(2), invitation.InviterID)
+	assert.Equal(t, domain.InvitationPending, invitation.Status)
+
+	n := receive(t, env.notifier)
+	assert.Equal(t, domain.NotificationInvitation, n.Kind)
+	assert.Equal(t, "carol@example.com", n.Email)
+	assert.Equal(t, "Acme", n.Organization)
+	assert.Equal(t, "bob", n.Inviter)
+	link, err := url.Parse(n.Link)
+	require.NoError(t, err)
+	assert.Equal(t, tokenx.HashOpaque(link.Query().Get("token")), env.invitations.invitations[0].TokenHash, "only the hash is stored")
+
+	_, err = env.service.CreateInvitation(ctx, "dave@example.com", domain.OrganizationRoleOwner)
+	assert.ErrorIs(t, err, domain.ErrForbidden, "only owners invite owners")
+
+	var validationErr *domain.ValidationError
+	_, err = env.service.CreateInvitation(ctx, "not-an-email", domain.OrganizationRoleMember)
+	assert.ErrorAs(t, err, &validationErr)
+}
+
+func TestInvitationService_Accept_NewAccount(t *testing.T) {
+	env := newTestInvitationService(t)
+	token := env.invite(t, "carol@example.com", domain.OrganizationRoleAdmin)
+	ctx := context.Background()
+
+	carol := &domain.UserResponse{ID: 9, Username: "carol", Email: "carol@example.com", OrganizationID: 5, OrganizationRole: domain.OrganizationRoleAdmin}
+	env.userService.EXPECT().GetUserByEmail(inOrganization(5), "carol@example.com").Return(nil, domain.ErrUserNotFound)
+	// The account is created straight into the organization with the invited role.
+	env.userService.EXPECT().CreateUser(inOrganization(5), &domain.User{Username: "carol", Email: "carol@example.com", Password: "password123",
+		OrganizationID: 5, OrganizationRole: domain.OrganizationRoleAdmin}).Return(int64(9), nil)
+	env.userService.EXPECT().GetUserByID(inOrganization(5), int64(9)).Return(carol, nil).Times(2)
+	env.userService.EXPECT().VerifyEmail(inOrganization(5), int64(9), "carol@example.com").Return(nil)
+
+	user, err := env.service.AcceptInvitation(ctx, domain.AcceptInvitationRequest{Token: token, Username: "carol", Password: "password123"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(9), user.ID)
+	assert.Equal(t, domain.InvitationAccepted, env.invitations.invitations[0].Status)
+
+	_, err = env.service.AcceptInvitation(ctx, domain.AcceptInvitationRequest{Token: token, Username: "carol", Password: "password123"})
+	assert.ErrorIs(t, err, domain.ErrInvalidInvitationToken, "invitations are single-use")
+}
+
+func TestInvitationService_Accept_RejectedAccount(t *testing.T) {
+	env := newTestInvitationService(t)
+	token := env.invite(t, "carol@example.com", domain.OrganizationRoleMember)
+	ctx := context.Background()
+
+	env.userService.EXPECT().GetUserByEmail(gomock.Any(), "carol@example.com").Return(nil, domain.ErrUserNotFound)
+	env.userService.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Return(int64(0), domain.ErrUsernameAlreadyExists)
+
+	_, err := env.service.AcceptInvitation(ctx, domain.AcceptInvitationRequest{Token: token, Username: "taken", Password: "password123"})
+	assert.ErrorIs(t, err, domain.ErrUsernameAlreadyExists)
+	assert.Equal(t, domain.InvitationPending, env.invitations.invitations[0].Status, "a rejected account does not burn the invitation")
+}
+
+func TestInvitationService_Accept_ExistingAccount(t *testing.T) {
+	env := newTestInvitationService(t)
+	token := env.invite(t, "carol@example.com", domain.OrganizationRoleAdmin)
+	ctx := context.Background()
+
+	carol := &domain.UserResponse{ID: 9, Username: "carol", Email: "carol@example.com", OrganizationID: 5, OrganizationRole: domain.OrganizationRoleMember}
+	env.userService.EXPECT().GetUserByEmail(inOrganization(5), "carol@example.com").Return(carol, nil)
+	env.userService.EXPECT().VerifyEmail(inOrganization(5), int64(9), "carol@example.com").Return(nil)
+	env.organizations.EXPECT().SetMemberRole(gomock.Any(), int64(5), int64(9), domain.OrganizationRoleAdmin).Return(nil)
+	env.userService.EXPECT().GetUserByID(inOrganization(5), int64(9)).Return(carol, nil)
+
+	_, err := env.service.AcceptInvitation(ctx, domain.AcceptInvitationRequest{Token: token})
+	require.NoError(t, err)
+}
+
+func TestInvitationService_Accept_AccountInOtherOrganization(t *testing.T) {
+	env := newTestInvitationService(t)
+	token := env.invite(t, "carol@example.com", domain.OrganizationRoleMember)
+	ctx := context.Background()
+
+	// No CreateUser call is expected: the account joins as it is.
+	carol := &domain.UserResponse{ID: 9, Username: "carol", Email: "carol@example.com", OrganizationID: 1, OrganizationRole: domain.OrganizationRoleOwner}
+	joined := &domain.UserResponse{ID: 9, Username: "carol", Email: "carol@example.com", OrganizationID: 5, OrganizationRole: domain.OrganizationRoleMember}
+	env.userService.EXPECT().GetUserByEmail(inOrganization(5), "carol@example.com").Return(carol, nil)
+	env.organizations.EXPECT().AddMember(gomock.Any(), int64(5), int64(9), domain.OrganizationRoleMember).Return(nil)
+	env.userService.EXPECT().VerifyEmail(inOrganization(5), int64(9), "carol@example.com").Return(nil)
+	env.userService.EXPECT().GetUserByID(inOrganization(5), int64(9)).Return(joined, nil)
+
+	user, err := env.service.AcceptInvitation(ctx, domain.AcceptInvitationRequest{Token: token})
+	require.NoError(t, err)
+	assert.Equal(t, joined, user)
+	assert.Equal(t, domain.InvitationAccepted, env.invitations.invitations[0].Status)
+}
+
+func TestInvitationService_DeclineAndRevoke(t *testing.T) {
+	env := newTestInvitationService(t)
+	ctx := context.Background()
+
+	declined := env.invite(t, "carol@example.com", domain.OrganizationRoleMember)
+	require.NoError(t, env.service.DeclineInvitation(ctx, declined))
+	assert.ErrorIs(t, env.service.DeclineInvitation(ctx, declined), domain.ErrInvalidInvitationToken)
+	_, err := env.service.AcceptInvitation(ctx, domain.AcceptInvitationRequest{Token: declined})
+	assert.ErrorIs(t, err, domain.ErrInvalidInvitationToken)
+
+	revoked := env.invite(t, "dave@example.com", domain.OrganizationRoleMember)
+	owner := domain.ContextWithPrincipal(ctx, &domain.Principal{UserID: 1, OrganizationID: 5})
+	other := domain.ContextWithPrincipal(ctx, &domain.Principal{UserID: 3, OrganizationID: 6})
+	assert.ErrorIs(t, env.service.RevokeInvitation(other, 2), domain.ErrInvitationNotFound, "other organizations cannot revoke it")
+	require.NoError(t, env.service.RevokeInvitation(owner, 2))
+	_, err = env.service.AcceptInvitation(ctx, domain.AcceptInvitationRequest{Token: revoked})
+	assert.ErrorIs(t, err, domain.ErrInvalidInvitationToken)
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/kerim-dauren/user-service/internal/domain"
+)
+
+// LockoutThresholds are the numbers of failed logins at which a subject is slowed down and locked.
+type LockoutThresholds struct {
+	BackoffAfter int // Failures after which every further one delays the next attempt exponentially
+	LockAfter    int // Failures after which logins are refused for LockoutConfig.Duration
+}
+
+// LockoutConfig configures the brute-force protection of logins.
+type LockoutConfig struct {
+	Account     LockoutThresholds
+	IP          LockoutThresholds
+	BackoffBase time.Duration // Delay after the first failure past BackoffAfter; it doubles with every failure
+	BackoffMax  time.Duration
+	Duration    time.Duration // How long a lock lasts
+	ResetAfter  time.Duration // Failures are forgotten after this long without one
+}
+
+type lockoutService struct {
+	logger      *slog.Logger
+	userService domain.UserService
+	storage     domain.LockoutStorage
+	cfg         LockoutConfig
+	now         func() time.Time
+}
+
+func NewLockoutService(
+	logger *slog.Logger,
+	userService domain.UserService,
+	storage domain.LockoutStorage,
+	cfg LockoutConfig,
+) domain.LockoutService {
+	return &lockoutService{
+		logger:      logger,
+		userService: userService,
+		storage:     storage,
+		cfg:         cfg,
+		now:         time.Now,
+	}
+}
+
+func (s *lockoutService) Check(ctx context.Context, subjects ...domain.LoginSubject) error {
+	if len(subjects) == 0 {
+		return nil
+	}
+	failures, err := s.storage.GetLoginFailures(ctx, subjects)
+	if err != nil {
+		return fmt.Errorf("get login failures: %w", err)
+	}
+	now := s.now()
+	for _, f := range failures {
+		if f.LockedUntil != nil && now.Before(*f.LockedUntil) {
+			return domain.ErrLoginLocked
+		}
+	}
+	return nil
+}
+
+func (s *lockoutService) RecordFailure(ctx context.Context, subjects ...domain.LoginSubject) error {
+	now := s.now()
+	for _, subject := range subjects {
+		failures, err := s.storage.RecordLoginFailure(ctx, subject, now, now.Add(-s.cfg.ResetAfter))
+		if err != nil {
+			return fmt.Errorf("record login failure: %w", err)
+		}
+		delay, locked := s.delay(subject.Scope, failures)
+		if delay == 0 {
+			continue
+		}
+		if locked {
+			s.logger.Warn("login locked after failed attempts",
+				"scope", subject.Scope, "key", subject.Key, "failures", failures, "until", now.Add(delay))
+			loginLockouts.WithLabelValues(string(subject.Scope)).Inc()
+		}
+		if err = s.storage.LockLoginSubject(ctx, subject, now.Add(delay)); err != nil {
+			return fmt.Errorf("lock login subject: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *lockoutService) RecordSuccess(ctx context.Context, subject domain.LoginSubject) error {
+	return s.storage.DeleteLoginFailures(ctx, subject)
+}
+
+func (s *lockoutService) UnlockAccount(ctx context.Context, userID int64) (err error) {
+	defer observeDuration(s.logger, "UnlockAccount", &err)()
+
+	if _, err = s.userService.GetUserByID(ctx, userID); err != nil {
+		return err
+	}
+	return s.storage.DeleteLoginFailures(ctx, domain.AccountSubject(userID))
+}
+
+// delay returns how long a subject must wait after its nth failure, and whether that is a full lock.
+func (s *lockoutService) delay(scope domain.LoginScope, failures int) (time.Duration, bool) {
+	thresholds := s.cfg.Account
+	if scope == domain.LoginScopeIP {
+		thresholds = s.cfg.IP
+	}
+	if thresholds.LockAfter > 0 && failures >= thresholds.LockAfter {
+		return s.cfg.Duration, true
+	}
+	if thresholds.BackoffAfter <= 0 || failures < thresholds.BackoffAfter {
+		return 0, false
+	}
+	// Capping the shift keeps the doubling from overflowing before BackoffMax applies.
+	delay := s.cfg.BackoffBase << min(failures-thresholds.BackoffAfter, 30)
+	return min(delay, s.cfg.BackoffMax), false
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memLockout is an in-memory domain.LockoutStorage.
+type memLockout struct {
+	mu       sync.Mutex
+	failures map[domain.LoginSubject]*domain.LoginFailures
+}
+
+func newMemLockout() *memLockout {
+	return &memLockout{failures: map[domain.LoginSubject]*domain.LoginFailures{}}
+}
+
+func (m *memLockout) GetLoginFailures(_ context.Context, subjects []domain.LoginSubject) ([]*domain.LoginFailures, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var found []*domain.LoginFailures
+	for _, s := range subjects {
+		if f, ok := m.failures[s]; ok {
+			copied := *f
+			found = append(found, &copied)
+		}
+	}
+	return found, nil
+}
+
+func (m *memLockout) RecordLoginFailure(_ context.Context, subject domain.LoginSubject, now, resetBefore time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f, ok := m.failures[subject]
+	if !ok {
+		f = &domain.LoginFailures{Subject: subject}
+		m.failures[subject] = f
+	}
+	if f.LastFailureAt.Before(resetBefore) {
+		f.Failures = 0
+	}
+	f.Failures++
+	f.LastFailureAt = now
+	return f.Failures, nil
+}
+
+func (m *memLockout) LockLoginSubject(_ context.Context, subject domain.LoginSubject, until time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if f, ok := m.failures[subject]; ok {
+		f.LockedUntil = &until
+	}
+	return nil
+}
+
+func (m *memLockout) DeleteLoginFailures(_ context.Context, subject domain.LoginSubject) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.failures, subject)
+	return nil
+}
+
+var testLockoutConfig = LockoutConfig{
+	Account:     LockoutThresholds{BackoffAfter: 2, LockAfter: 5},
+	IP:          LockoutThresholds{BackoffAfter: 10, LockAfter: 20},
+	BackoffBase: time.Second,
+	BackoffMax:  3 * time.Second,
+	Duration:    15 * time.Minute,
+	ResetAfter:  time.Hour,
+}
+
+func newTestLockoutService(t *testing.T, userService domain.UserService) (*lockoutService, *memLockout) {
+	t.Helper()
+	storage := newMemLockout()
+	service := NewLockoutService(slog.Default(), userService, storage, testLockoutConfig)
+	return service.(*lockoutService), storage
+}
+
+func TestLockoutService_Backoff(t *testing.T) {
+	service, storage := newTestLockoutService(t, nil)
+	ctx := context.Background()
+	now := time.Now()
+	service.now = func() time.Time { return now }
+	account := domain.AccountSubject(1)
+
+	require.NoError(t, service.RecordFailure(ctx, account))
+	assert.NoError(t, service.Check(ctx, account), "no delay before BackoffAfter")
+
+	// The delay doubles with every failure and is capped at BackoffMax.
+	for _, delay := range []time.Duration{time.Second, 2 * time.Second, 3 * time.Second} {
+		require.NoError(t, service.RecordFailure(ctx, account))
+		assert.ErrorIs(t, service.Check(ctx, account), domain.ErrLoginLocked)
+		assert.Equal(t, now.Add(delay), *storage.failures[account].LockedUntil)
+	}
+
+	now = now.Add(3 * time.Second)
+	assert.NoError(t, service.Check(ctx, account))
+	assert.NoError(t, service.Check(ctx, domain.IPSubject("10.0.0.1")), "other subjects are not affected")
+}
+
+func TestLockoutService_Lock(t *testing.T) {
+	service, storage := newTestLockoutService(t, nil)
+	ctx := context.Background()
+	now := time.Now()
+	service.now = func() time.Time { return now }
+	account, ip := domain.AccountSubject(1), domain.IPSubject("10.0.0.1")
+
+	for range 5 {
+		require.NoError(t, service.RecordFailure(ctx, ip, account))
+	}
+	assert.Equal(t, now.Add(15*time.Minute), *storage.failures[account].LockedUntil)
+	assert.Nil(t, storage.failures[ip].LockedUntil, "IPs have higher thresholds")
+	assert.ErrorIs(t, service.Check(ctx, ip, account), domain.ErrLoginLocked)
+
+	now = now.Add(15 * time.Minute)
+	assert.NoError(t, service.Check(ctx, ip, account))
+
+	// Failures are forgotten after a quiet ResetAfter.
+	now = now.Add(time.Hour)
+	require.NoError(t, service.RecordFailure(ctx, account))
+	assert.Equal(t, 1, storage.failures[account].Failures)
+}
+
+func TestLockoutService_UnlockAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := domain.NewMockUserService(ctrl)
+	service, storage := newTestLockoutService(t, userService)
+	ctx := context.Background()
+	account := domain.AccountSubject(1)
+
+	for range 5 {
+		require.NoError(t, service.RecordFailure(ctx, account))
+	}
+	require.ErrorIs(t, service.Check(ctx, account), domain.ErrLoginLocked)
+
+	userService.EXPECT().GetUserByID(ctx, int64(1)).Return(alice, nil)
+	require.NoError(t, service.UnlockAccount(ctx, 1))
+	assert.NoError(t, service.Check(ctx, account))
+	assert.Empty(t, storage.failures)
+
+	userService.EXPECT().GetUserByID(ctx, int64(2)).Return(nil, domain.ErrUserNotFound)
+	assert.ErrorIs(t, service.UnlockAccount(ctx, 2), domain.ErrUserNotFound)
+}
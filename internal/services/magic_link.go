@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/kerim-dauren/user-service/pkg/tokenx"
+)
+
+// MagicLinkConfig configures login links.
+type MagicLinkConfig struct {
+	TTL         time.Duration // Lifetime of a login token
+	URL         string        // Page that receives the token in the "token" query parameter
+	Limit       int           // Links sent to one email per LimitWindow; further requests are dropped
+	LimitWindow time.Duration
+}
+
+type magicLinkService struct {
+	logger      *slog.Logger
+	userService domain.UserService
+	links       domain.MagicLinkStorage
+	notifier    domain.Notifier
+	cfg         MagicLinkConfig
+	now         func() time.Time
+}
+
+func NewMagicLinkService(
+	logger *slog.Logger,
+	userService domain.UserService,
+	links domain.MagicLinkStorage,
+	notifier domain.Notifier,
+	cfg MagicLinkConfig,
+) domain.MagicLinkService {
+	return &magicLinkService{
+		logger:      logger,
+		userService: userService,
+		links:       links,
+		notifier:    notifier,
+		cfg:         cfg,
+		now:         time.Now,
+	}
+}
+
+func (s *magicLinkService) RequestMagicLink(ctx context.Context, email string) (err error) {
+	defer observeDuration(s.logger, "RequestMagicLink", &err)()
+
+	if email == "" {
+		return domain.NewValidationError(domain.FieldViolation{Field: "email", Description: "is required"})
+	}
+	user, err := s.userService.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	token, err := tokenx.NewOpaque()
+	if err != nil {
+		return err
+	}
+	now := s.now()
+	expiresAt := now.Add(s.cfg.TTL)
+	_, err = s.links.CreateMagicLinkToken(ctx, &domain.MagicLinkToken{
+		UserID:    user.ID,
+		Email:     user.Email,
+		TokenHash: tokenx.HashOpaque(token),
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+	}, s.cfg.Limit, now.Add(-s.cfg.LimitWindow))
+	if errors.Is(err, domain.ErrEmailThrottled) {
+		// Answering differently would tell registered emails apart from unknown ones.
+		s.logger.Warn("magic link throttled", "user_id", user.ID)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("create magic link token: %w", err)
+	}
+	link, err := actionLink(s.cfg.URL, token)
+	if err != nil {
+		return err
+	}
+	notifyAsync(ctx, s.logger, s.notifier, domain.Notification{
+		Kind:      domain.NotificationMagicLink,
+		UserID:    user.ID,
+		Email:     user.Email,
+		Username:  user.Username,
+		Link:      link,
+		ExpiresAt: expiresAt,
+	})
+	return nil
+}
+
+func (s *magicLinkService) ConsumeMagicLink(ctx context.Context, token string) (user *domain.UserResponse, err error) {
+	defer observeDuration(s.logger, "ConsumeMagicLink", &err)()
+
+	if token == "" {
+		return nil, domain.ErrInvalidMagicLinkToken
+	}
+	link, err := s.links.ConsumeMagicLinkToken(ctx, tokenx.HashOpaque(token), s.now())
+	if err != nil {
+		return nil, err
+	}
+	// The link, not the caller, decides which organization the account is in.
+	if ctx, err = s.userService.WithUserTenant(ctx, link.UserID); err == nil {
+		user, err = s.userService.GetUserByID(ctx, link.UserID)
+	}
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, domain.ErrInvalidMagicLinkToken
+		}
+		return nil, err
+	}
+	if user.Email != link.Email {
+		// The user changed their email after the link was sent.
+		return nil, domain.ErrInvalidMagicLinkToken
+	}
+	if user.EmailVerifiedAt == nil {
+		if err = s.userService.VerifyEmail(ctx, user.ID, user.Email); err != nil {
+			return nil, fmt.Errorf("verify email: %w", err)
+		}
+		verifiedAt := s.now()
+		user.EmailVerifiedAt = &verifiedAt
+	}
+	// Older links of the user die with this one, so that none can be replayed later. They are
+	// kept while they count towards the send limit, which logging in must not reset.
+	now := s.now()
+	if err = s.links.InvalidateUserMagicLinkTokens(ctx, user.ID, now, now.Add(-s.cfg.LimitWindow)); err != nil {
+		return nil, fmt.Errorf("invalidate magic link tokens: %w", err)
+	}
+	return user, nil
+}
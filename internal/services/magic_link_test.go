@@ -0,0 +1,252 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/kerim-dauren/user-service/pkg/tokenx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memMagicLinks is an in-memory domain.MagicLinkStorage.
+type memMagicLinks struct {
+	mu     sync.Mutex
+	nextID int64
+	tokens map[int64]*domain.MagicLinkToken
+}
+
+func newMemMagicLinks() *memMagicLinks {
+	return &memMagicLinks{tokens: map[int64]*domain.MagicLinkToken{}}
+}
+
+func (m *memMagicLinks) CreateMagicLinkToken(_ context.Context, t *domain.MagicLinkToken, limit int, since time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	count := 0
+	for _, stored := range m.tokens {
+		if stored.Email == t.Email && stored.CreatedAt.After(since) {
+			count++
+		}
+	}
+	if count >= limit {
+		return 0, domain.ErrEmailThrottled
+	}
+	m.nextID++
+	stored := *t
+	stored.ID = m.nextID
+	m.tokens[stored.ID] = &stored
+	return stored.ID, nil
+}
+
+func (m *memMagicLinks) ConsumeMagicLinkToken(_ context.Context, hash string, now time.Time) (*domain.MagicLinkToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, t := range m.tokens {
+		if t.TokenHash == hash && t.UsedAt == nil && t.ExpiresAt.After(now) {
+			t.UsedAt = &now
+			consumed := *t
+			return &consumed, nil
+		}
+	}
+	return nil, domain.ErrInvalidMagicLinkToken
+}
+
+func (m *memMagicLinks) InvalidateUserMagicLinkTokens(_ context.Context, userID int64, now, prunedBefore time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, t := range m.tokens {
+		if t.UserID != userID {
+			continue
+		}
+		if !t.CreatedAt.After(prunedBefore) {
+			delete(m.tokens, id)
+		} else if t.UsedAt == nil {
+			t.UsedAt = &now
+		}
+	}
+	return nil
+}
+
+func newTestMagicLinkService(t *testing.T, userService domain.UserService) (*magicLinkService, *memMagicLinks, chanNotifier) {
+	t.Helper()
+	links := newMemMagicLinks()
+	notifier := make(chanNotifier, 1)
+	service := NewMagicLinkService(slog.Default(), userService, links, notifier, MagicLinkConfig{
+		TTL:         10 * time.Minute,
+		URL:         "https://example.com/magic-link",
+		Limit:       2,
+		LimitWindow: time.Hour,
+	})
+	return service.(*magicLinkService), links, notifier
+}
+
+// requestMagicLink requests a link for alice and returns its token.
+func requestMagicLink(t *testing.T, service domain.MagicLinkService, notifier chanNotifier) string {
+	t.Helper()
+	require.NoError(t, service.RequestMagicLink(context.Background(), alice.Email))
+	link, err := url.Parse(receive(t, notifier).Link)
+	require.NoError(t, err)
+	return link.Query().Get("token")
+}
+
+func TestMagicLinkService_Request(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := domain.NewMockUserService(ctrl)
+	service, links, notifier := newTestMagicLinkService(t, userService)
+	ctx := context.Background()
+
+	userService.EXPECT().GetUserByEmail(ctx, alice.Email).Return(alice, nil)
+	userService.EXPECT().GetUserByEmail(ctx, "nobody@example.com").Return(nil, domain.ErrUserNotFound)
+
+	require.NoError(t, service.RequestMagicLink(ctx, alice.Email))
+	n := receive(t, notifier)
+	assert.Equal(t, domain.NotificationMagicLink, n.Kind)
+	assert.Equal(t, alice.Email, n.Email)
+	link, err := url.Parse(n.Link)
+	require.NoError(t, err)
+	require.Len(t, links.tokens, 1)
+	assert.Equal(t, tokenx.HashOpaque(link.Query().Get("token")), links.tokens[1].TokenHash)
+
+	assert.NoError(t, service.RequestMagicLink(ctx, "nobody@example.com"))
+	assert.Len(t, links.tokens, 1)
+	assert.Empty(t, notifier)
+}
+
+func TestMagicLinkService_Request_Throttled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := domain.NewMockUserService(ctrl)
+	service, links, notifier := newTestMagicLinkService(t, userService)
+	ctx := context.Background()
+
+	userService.EXPECT().GetUserByEmail(ctx, alice.Email).Return(alice, nil).Times(4)
+	requestMagicLink(t, service, notifier)
+	requestMagicLink(t, service, notifier)
+
+	assert.NoError(t, service.RequestMagicLink(ctx, alice.Email), "throttled requests look like any other")
+	assert.Len(t, links.tokens, 2)
+	assert.Empty(t, notifier)
+
+	service.now = func() time.Time { return time.Now().Add(61 * time.Minute) }
+	require.NoError(t, service.RequestMagicLink(ctx, alice.Email))
+	receive(t, notifier)
+	assert.Len(t, links.tokens, 3)
+}
+
+func TestMagicLinkService_Request_ConcurrentThrottled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := domain.NewMockUserService(ctrl)
+	service, links, _ := newTestMagicLinkService(t, userService)
+	ctx := context.Background()
+
+	userService.EXPECT().GetUserByEmail(ctx, alice.Email).Return(alice, nil).Times(10)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, service.RequestMagicLink(ctx, alice.Email))
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, links.tokens, 2, "concurrent requests do not exceed the limit")
+}
+
+func TestMagicLinkService_Consume(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := domain.NewMockUserService(ctrl)
+	service, links, notifier := newTestMagicLinkService(t, userService)
+	ctx := context.Background()
+
+	userService.EXPECT().GetUserByEmail(gomock.Any(), alice.Email).Return(alice, nil).Times(2)
+	older := requestMagicLink(t, service, notifier)
+	token := requestMagicLink(t, service, notifier)
+
+	unverified := *alice
+	userService.EXPECT().WithUserTenant(ctx, alice.ID).Return(ctx, nil)
+	userService.EXPECT().GetUserByID(ctx, alice.ID).Return(&unverified, nil)
+	userService.EXPECT().VerifyEmail(ctx, alice.ID, alice.Email).Return(nil)
+
+	user, err := service.ConsumeMagicLink(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, alice.ID, user.ID)
+	assert.NotNil(t, user.EmailVerifiedAt, "following the link verifies the email")
+	for _, stored := range links.tokens {
+		assert.NotNil(t, stored.UsedAt, "every outstanding link of the user is invalidated")
+	}
+
+	for _, token := range []string{token, older} {
+		_, err = service.ConsumeMagicLink(ctx, token)
+		assert.ErrorIs(t, err, domain.ErrInvalidMagicLinkToken, "links are single-use")
+	}
+}
+
+func TestMagicLinkService_Consume_KeepsThrottle(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := domain.NewMockUserService(ctrl)
+	service, links, notifier := newTestMagicLinkService(t, userService)
+	ctx := context.Background()
+
+	userService.EXPECT().GetUserByEmail(gomock.Any(), alice.Email).Return(alice, nil).Times(4)
+	requestMagicLink(t, service, notifier)
+	token := requestMagicLink(t, service, notifier)
+
+	verifiedAt := time.Now()
+	verified := *alice
+	verified.EmailVerifiedAt = &verifiedAt
+	userService.EXPECT().WithUserTenant(ctx, alice.ID).Return(ctx, nil).Times(2)
+	userService.EXPECT().GetUserByID(ctx, alice.ID).Return(&verified, nil).Times(2)
+	_, err := service.ConsumeMagicLink(ctx, token)
+	require.NoError(t, err)
+
+	require.NoError(t, service.RequestMagicLink(ctx, alice.Email))
+	assert.Empty(t, notifier, "logging in does not reset the send limit")
+	assert.Len(t, links.tokens, 2)
+
+	// Tokens older than the window no longer count and are pruned with the next login.
+	service.now = func() time.Time { return time.Now().Add(61 * time.Minute) }
+	token = requestMagicLink(t, service, notifier)
+	_, err = service.ConsumeMagicLink(ctx, token)
+	require.NoError(t, err)
+	assert.Len(t, links.tokens, 1)
+}
+
+func TestMagicLinkService_Consume_EmailChanged(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := domain.NewMockUserService(ctrl)
+	service, _, notifier := newTestMagicLinkService(t, userService)
+	ctx := context.Background()
+
+	userService.EXPECT().GetUserByEmail(gomock.Any(), alice.Email).Return(alice, nil)
+	token := requestMagicLink(t, service, notifier)
+
+	changed := *alice
+	changed.Email = "alice@example.org"
+	userService.EXPECT().WithUserTenant(ctx, alice.ID).Return(ctx, nil)
+	userService.EXPECT().GetUserByID(ctx, alice.ID).Return(&changed, nil)
+
+	_, err := service.ConsumeMagicLink(ctx, token)
+	assert.ErrorIs(t, err, domain.ErrInvalidMagicLinkToken)
+}
+
+func TestMagicLinkService_Consume_Expired(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := domain.NewMockUserService(ctrl)
+	service, _, notifier := newTestMagicLinkService(t, userService)
+
+	userService.EXPECT().GetUserByEmail(gomock.Any(), alice.Email).Return(alice, nil)
+	token := requestMagicLink(t, service, notifier)
+
+	service.now = func() time.Time { return time.Now().Add(11 * time.Minute) }
+
+	for _, token := range []string{token, "", "unknown"} {
+		_, err := service.ConsumeMagicLink(context.Background(), token)
+		assert.ErrorIs(t, err, domain.ErrInvalidMagicLinkToken, token)
+	}
+}
@@ -0,0 +1,21 @@
+package services
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// observeDuration records the duration and result of a service method.
+// Usage: defer observeDuration(logger, "Method", &err)()
+func observeDuration(logger *slog.Logger, method string, err *error) func() {
+	start := time.Now()
+	return func() {
+		result := "success"
+		if *err != nil {
+			result = "error"
+			logger.Error(fmt.Sprintf("%s failed", method), "err", *err)
+		}
+		requestDuration.WithLabelValues(method, result).Observe(time.Since(start).Seconds())
+	}
+}
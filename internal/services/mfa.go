@@ -0,0 +1,256 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/kerim-dauren/user-service/pkg/cryptox"
+	"github.com/kerim-dauren/user-service/pkg/otpx"
+	"github.com/kerim-dauren/user-service/pkg/tokenx"
+)
+
+const qrCodeSize = 256
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// MFAConfig configures TOTP enrollment and login challenges.
+type MFAConfig struct {
+	Issuer            string        // Shown next to the account in authenticator apps
+	ChallengeTTL      time.Duration // Time to enter a code after the password
+	MaxAttempts       int           // Wrong codes allowed per challenge
+	RecoveryCodeCount int
+}
+
+type mfaService struct {
+	logger      *slog.Logger
+	userService domain.UserService
+	storage     domain.MFAStorage
+	secrets     *cryptox.Box
+	totp        otpx.TOTP
+	cfg         MFAConfig
+	now         func() time.Time
+}
+
+func NewMFAService(
+	logger *slog.Logger,
+	userService domain.UserService,
+	storage domain.MFAStorage,
+	secrets *cryptox.Box,
+	cfg MFAConfig,
+) domain.MFAService {
+	return &mfaService{
+		logger:      logger,
+		userService: userService,
+		storage:     storage,
+		secrets:     secrets,
+		totp:        otpx.NewTOTP(),
+		cfg:         cfg,
+		now:         time.Now,
+	}
+}
+
+func (s *mfaService) EnrollTOTP(ctx context.Context, userID int64) (enrollment *domain.TOTPEnrollment, err error) {
+	defer observeDuration(s.logger, "EnrollTOTP", &err)()
+
+	user, err := s.userService.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := otpx.NewSecret()
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := s.secrets.Seal(secret, secretAdditionalData(userID))
+	if err != nil {
+		return nil, fmt.Errorf("encrypt totp secret: %w", err)
+	}
+	if err = s.storage.SavePendingTOTPCredential(ctx, &domain.TOTPCredential{UserID: userID, SecretEncrypted: sealed}); err != nil {
+		return nil, err
+	}
+
+	uri := s.totp.KeyURI(s.cfg.Issuer, user.Username, secret)
+	qrCode, err := otpx.QRCode(uri, qrCodeSize)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.TOTPEnrollment{Secret: otpx.EncodeSecret(secret), URI: uri, QRCode: qrCode}, nil
+}
+
+func (s *mfaService) ConfirmTOTP(ctx context.Context, userID int64, code string) (recoveryCodes []string, err error) {
+	defer observeDuration(s.logger, "ConfirmTOTP", &err)()
+
+	credential, err := s.storage.GetTOTPCredential(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if credential.EnabledAt != nil {
+		return nil, domain.ErrMFAAlreadyEnabled
+	}
+	secret, err := s.openSecret(credential)
+	if err != nil {
+		return nil, err
+	}
+	step, ok := s.totp.Validate(secret, code, s.now())
+	if !ok {
+		return nil, domain.ErrInvalidMFACode
+	}
+
+	recoveryCodes, hashes, err := s.newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err = s.storage.EnableTOTPCredential(ctx, userID, step, hashes, s.now()); err != nil {
+		return nil, err
+	}
+	return recoveryCodes, nil
+}
+
+func (s *mfaService) DisableMFA(ctx context.Context, userID int64, code string) (err error) {
+	defer observeDuration(s.logger, "DisableMFA", &err)()
+
+	if err = s.verifyCode(ctx, userID, code); err != nil {
+		return err
+	}
+	return s.storage.DeleteMFA(ctx, userID)
+}
+
+func (s *mfaService) RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) (recoveryCodes []string, err error) {
+	defer observeDuration(s.logger, "RegenerateRecoveryCodes", &err)()
+
+	if err = s.verifyCode(ctx, userID, code); err != nil {
+		return nil, err
+	}
+	recoveryCodes, hashes, err := s.newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err = s.storage.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return recoveryCodes, nil
+}
+
+func (s *mfaService) StartChallenge(ctx context.Context, userID int64) (challenge *domain.MFAChallenge, err error) {
+	defer observeDuration(s.logger, "StartChallenge", &err)()
+
+	credential, err := s.storage.GetTOTPCredential(ctx, userID)
+	if errors.Is(err, domain.ErrMFANotEnabled) || (err == nil && credential.EnabledAt == nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := tokenx.NewOpaque()
+	if err != nil {
+		return nil, err
+	}
+	if _, err = s.storage.CreateMFAChallenge(ctx, &domain.MFAChallengeRecord{
+		UserID:    userID,
+		TokenHash: tokenx.HashOpaque(token),
+		ExpiresAt: s.now().Add(s.cfg.ChallengeTTL),
+	}); err != nil {
+		return nil, fmt.Errorf("create mfa challenge: %w", err)
+	}
+	return &domain.MFAChallenge{Token: token, ExpiresIn: int64(s.cfg.ChallengeTTL.Seconds())}, nil
+}
+
+func (s *mfaService) CompleteChallenge(ctx context.Context, token, code string) (userID int64, err error) {
+	defer observeDuration(s.logger, "CompleteChallenge", &err)()
+
+	if token == "" {
+		return 0, domain.ErrInvalidMFAChallenge
+	}
+	challenge, err := s.storage.GetMFAChallenge(ctx, tokenx.HashOpaque(token))
+	if err != nil {
+		return 0, err
+	}
+	if !s.now().Before(challenge.ExpiresAt) {
+		return 0, domain.ErrInvalidMFAChallenge
+	}
+	// The attempt is counted before the code is checked, so that parallel guesses cannot
+	// all pass a limit read earlier.
+	if err = s.storage.CountMFAChallengeAttempt(ctx, challenge.ID, s.cfg.MaxAttempts); err != nil {
+		return 0, err
+	}
+
+	if err = s.verifyCode(ctx, challenge.UserID, code); err != nil {
+		if errors.Is(err, domain.ErrMFANotEnabled) {
+			// MFA was turned off in the meantime; the user has to log in again.
+			return 0, domain.ErrInvalidMFAChallenge
+		}
+		return 0, err
+	}
+	if err = s.storage.DeleteMFAChallenge(ctx, challenge.ID); err != nil {
+		return 0, err
+	}
+	return challenge.UserID, nil
+}
+
+// verifyCode accepts either a current TOTP code or an unused recovery code, each only once.
+func (s *mfaService) verifyCode(ctx context.Context, userID int64, code string) error {
+	credential, err := s.storage.GetTOTPCredential(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if credential.EnabledAt == nil {
+		return domain.ErrMFANotEnabled
+	}
+
+	code = strings.TrimSpace(code)
+	if _, err := strconv.Atoi(code); err != nil || len(code) != s.totp.Digits {
+		return s.storage.UseRecoveryCode(ctx, userID, hashRecoveryCode(code), s.now())
+	}
+	secret, err := s.openSecret(credential)
+	if err != nil {
+		return err
+	}
+	step, ok := s.totp.Validate(secret, code, s.now())
+	if !ok {
+		return domain.ErrInvalidMFACode
+	}
+	// Fails if this or a later code was used already, so an observed code cannot be replayed.
+	return s.storage.UseTOTPStep(ctx, userID, step)
+}
+
+func (s *mfaService) openSecret(credential *domain.TOTPCredential) ([]byte, error) {
+	secret, err := s.secrets.Open(credential.SecretEncrypted, secretAdditionalData(credential.UserID))
+	if err != nil {
+		return nil, fmt.Errorf("decrypt totp secret of user %d: %w", credential.UserID, err)
+	}
+	return secret, nil
+}
+
+// newRecoveryCodes returns codes formatted as xxxx-xxxx-xxxx-xxxx (80 bits each) and their hashes.
+func (s *mfaService) newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, s.cfg.RecoveryCodeCount)
+	hashes := make([]string, s.cfg.RecoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 10)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		raw := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))
+		codes[i] = raw[0:4] + "-" + raw[4:8] + "-" + raw[8:12] + "-" + raw[12:16]
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode ignores case, dashes and spaces so that codes can be typed loosely.
+func hashRecoveryCode(code string) string {
+	normalized := strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(code))
+	return tokenx.HashOpaque(normalized)
+}
+
+func secretAdditionalData(userID int64) []byte {
+	return []byte("totp:" + strconv.FormatInt(userID, 10))
+}
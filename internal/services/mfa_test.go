@@ -0,0 +1,337 @@
+package services
+
+import (
+	"context"
+	"encoding/base32"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/kerim-dauren/user-service/pkg/cryptox"
+	"github.com/kerim-dauren/user-service/pkg/otpx"
+)
+
+type memMFA struct {
+	mu            sync.Mutex
+	nextID        int64
+	credentials   map[int64]*domain.TOTPCredential
+	recoveryCodes map[int64]map[string]bool // Hash to used
+	challenges    map[int64]*domain.MFAChallengeRecord
+}
+
+func newMemMFA() *memMFA {
+	return &memMFA{
+		credentials:   map[int64]*domain.TOTPCredential{},
+		recoveryCodes: map[int64]map[string]bool{},
+		challenges:    map[int64]*domain.MFAChallengeRecord{},
+	}
+}
+
+func (m *memMFA) GetTOTPCredential(_ context.Context, userID int64) (*domain.TOTPCredential, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, ok := m.credentials[userID]
+	if !ok {
+		return nil, domain.ErrMFANotEnabled
+	}
+	copied := *c
+	return &copied, nil
+}
+
+func (m *memMFA) SavePendingTOTPCredential(_ context.Context, c *domain.TOTPCredential) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if existing, ok := m.credentials[c.UserID]; ok && existing.EnabledAt != nil {
+		return domain.ErrMFAAlreadyEnabled
+	}
+	stored := *c
+	m.credentials[c.UserID] = &stored
+	return nil
+}
+
+func (m *memMFA) EnableTOTPCredential(_ context.Context, userID int64, step int64, hashes []string, now time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, ok := m.credentials[userID]
+	if !ok || c.EnabledAt != nil {
+		return domain.ErrMFAAlreadyEnabled
+	}
+	c.EnabledAt = &now
+	c.LastUsedStep = step
+	m.replaceRecoveryCodes(userID, hashes)
+	return nil
+}
+
+func (m *memMFA) UseTOTPStep(_ context.Context, userID int64, step int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, ok := m.credentials[userID]
+	if !ok || c.EnabledAt == nil || c.LastUsedStep >= step {
+		return domain.ErrInvalidMFACode
+	}
+	c.LastUsedStep = step
+	return nil
+}
+
+func (m *memMFA) DeleteMFA(_ context.Context, userID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.credentials, userID)
+	delete(m.recoveryCodes, userID)
+	return nil
+}
+
+func (m *memMFA) ReplaceRecoveryCodes(_ context.Context, userID int64, hashes []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.replaceRecoveryCodes(userID, hashes)
+	return nil
+}
+
+func (m *memMFA) replaceRecoveryCodes(userID int64, hashes []string) {
+	m.recoveryCodes[userID] = map[string]bool{}
+	for _, hash := range hashes {
+		m.recoveryCodes[userID][hash] = false
+	}
+}
+
+func (m *memMFA) UseRecoveryCode(_ context.Context, userID int64, hash string, _ time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	used, ok := m.recoveryCodes[userID][hash]
+	if !ok || used {
+		return domain.ErrInvalidMFACode
+	}
+	m.recoveryCodes[userID][hash] = true
+	return nil
+}
+
+func (m *memMFA) CreateMFAChallenge(_ context.Context, c *domain.MFAChallengeRecord) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nextID++
+	stored := *c
+	stored.ID = m.nextID
+	m.challenges[stored.ID] = &stored
+	return stored.ID, nil
+}
+
+func (m *memMFA) GetMFAChallenge(_ context.Context, hash string) (*domain.MFAChallengeRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, c := range m.challenges {
+		if c.TokenHash == hash {
+			copied := *c
+			return &copied, nil
+		}
+	}
+	return nil, domain.ErrInvalidMFAChallenge
+}
+
+func (m *memMFA) CountMFAChallengeAttempt(_ context.Context, id int64, maxAttempts int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, ok := m.challenges[id]
+	if !ok || c.Attempts >= maxAttempts {
+		return domain.ErrInvalidMFAChallenge
+	}
+	c.Attempts++
+	return nil
+}
+
+func (m *memMFA) DeleteMFAChallenge(_ context.Context, id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.challenges[id]; !ok {
+		return domain.ErrInvalidMFAChallenge
+	}
+	delete(m.challenges, id)
+	return nil
+}
+
+type mfaTestEnv struct {
+	storage *memMFA
+	now     time.Time
+}
+
+func newTestMFAService(t *testing.T) (domain.MFAService, *mfaTestEnv) {
+	t.Helper()
+	userService := domain.NewMockUserService(gomock.NewController(t))
+	userService.EXPECT().GetUserByID(gomock.Any(), int64(1)).Return(alice, nil).AnyTimes()
+	secrets, err := cryptox.NewBox("")
+	require.NoError(t, err)
+
+	env := &mfaTestEnv{storage: newMemMFA(), now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	service := NewMFAService(slog.Default(), userService, env.storage, secrets, MFAConfig{
+		Issuer:            "Test",
+		ChallengeTTL:      5 * time.Minute,
+		MaxAttempts:       3,
+		RecoveryCodeCount: 4,
+	})
+	service.(*mfaService).now = func() time.Time { return env.now }
+	return service, env
+}
+
+// enrollTOTP turns MFA on for alice and returns her secret and recovery codes.
+func enrollTOTP(ctx context.Context, t *testing.T, service domain.MFAService, env *mfaTestEnv) ([]byte, []string) {
+	t.Helper()
+	enrollment, err := service.EnrollTOTP(ctx, 1)
+	require.NoError(t, err)
+	secret, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(enrollment.Secret)
+	require.NoError(t, err)
+
+	recoveryCodes, err := service.ConfirmTOTP(ctx, 1, otpx.NewTOTP().Code(secret, env.now))
+	require.NoError(t, err)
+	return secret, recoveryCodes
+}
+
+func TestMFAService_EnrollTOTP(t *testing.T) {
+	service, env := newTestMFAService(t)
+	ctx := context.Background()
+
+	enrollment, err := service.EnrollTOTP(ctx, 1)
+	require.NoError(t, err)
+	assert.Contains(t, enrollment.URI, "otpauth://totp/Test:alice?")
+	assert.NotEmpty(t, enrollment.QRCode)
+	assert.NotContains(t, env.storage.credentials[1].SecretEncrypted, enrollment.Secret, "the secret is stored encrypted")
+
+	challenge, err := service.StartChallenge(ctx, 1)
+	require.NoError(t, err)
+	assert.Nil(t, challenge, "MFA stays off until confirmed")
+
+	_, err = service.ConfirmTOTP(ctx, 1, "000000")
+	assert.ErrorIs(t, err, domain.ErrInvalidMFACode)
+
+	secret, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(enrollment.Secret)
+	require.NoError(t, err)
+	recoveryCodes, err := service.ConfirmTOTP(ctx, 1, otpx.NewTOTP().Code(secret, env.now))
+	require.NoError(t, err)
+	assert.Len(t, recoveryCodes, 4)
+	assert.Regexp(t, `^[a-z2-7]{4}-[a-z2-7]{4}-[a-z2-7]{4}-[a-z2-7]{4}$`, recoveryCodes[0])
+
+	_, err = service.EnrollTOTP(ctx, 1)
+	assert.ErrorIs(t, err, domain.ErrMFAAlreadyEnabled)
+}
+
+func TestMFAService_CompleteChallenge(t *testing.T) {
+	service, env := newTestMFAService(t)
+	ctx := context.Background()
+	secret, _ := enrollTOTP(ctx, t, service, env)
+
+	challenge, err := service.StartChallenge(ctx, 1)
+	require.NoError(t, err)
+	require.NotNil(t, challenge)
+	assert.Equal(t, int64(300), challenge.ExpiresIn)
+
+	// The code that confirmed the enrollment was used already.
+	_, err = service.CompleteChallenge(ctx, challenge.Token, otpx.NewTOTP().Code(secret, env.now))
+	assert.ErrorIs(t, err, domain.ErrInvalidMFACode)
+
+	env.now = env.now.Add(30 * time.Second)
+	code := otpx.NewTOTP().Code(secret, env.now)
+	userID, err := service.CompleteChallenge(ctx, challenge.Token, code)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), userID)
+
+	_, err = service.CompleteChallenge(ctx, challenge.Token, code)
+	assert.ErrorIs(t, err, domain.ErrInvalidMFAChallenge, "a challenge is completed only once")
+}
+
+func TestMFAService_CompleteChallenge_RecoveryCode(t *testing.T) {
+	service, env := newTestMFAService(t)
+	ctx := context.Background()
+	_, recoveryCodes := enrollTOTP(ctx, t, service, env)
+
+	challenge, err := service.StartChallenge(ctx, 1)
+	require.NoError(t, err)
+	_, err = service.CompleteChallenge(ctx, challenge.Token, " "+recoveryCodes[0]+" ")
+	require.NoError(t, err)
+
+	challenge, err = service.StartChallenge(ctx, 1)
+	require.NoError(t, err)
+	_, err = service.CompleteChallenge(ctx, challenge.Token, recoveryCodes[0])
+	assert.ErrorIs(t, err, domain.ErrInvalidMFACode, "recovery codes are single-use")
+}
+
+func TestMFAService_CompleteChallenge_Limits(t *testing.T) {
+	service, env := newTestMFAService(t)
+	ctx := context.Background()
+	secret, _ := enrollTOTP(ctx, t, service, env)
+	env.now = env.now.Add(time.Minute)
+
+	challenge, err := service.StartChallenge(ctx, 1)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err = service.CompleteChallenge(ctx, challenge.Token, "000000")
+		assert.ErrorIs(t, err, domain.ErrInvalidMFACode)
+	}
+	_, err = service.CompleteChallenge(ctx, challenge.Token, otpx.NewTOTP().Code(secret, env.now))
+	assert.ErrorIs(t, err, domain.ErrInvalidMFAChallenge, "too many attempts")
+
+	challenge, err = service.StartChallenge(ctx, 1)
+	require.NoError(t, err)
+	env.now = env.now.Add(6 * time.Minute)
+	_, err = service.CompleteChallenge(ctx, challenge.Token, otpx.NewTOTP().Code(secret, env.now))
+	assert.ErrorIs(t, err, domain.ErrInvalidMFAChallenge, "expired")
+
+	_, err = service.CompleteChallenge(ctx, "unknown", "123456")
+	assert.ErrorIs(t, err, domain.ErrInvalidMFAChallenge)
+}
+
+func TestMFAService_CompleteChallenge_ConcurrentAttempts(t *testing.T) {
+	service, env := newTestMFAService(t)
+	ctx := context.Background()
+	enrollTOTP(ctx, t, service, env)
+
+	challenge, err := service.StartChallenge(ctx, 1)
+	require.NoError(t, err)
+
+	const guesses = 20
+	errs := make(chan error, guesses)
+	var wg sync.WaitGroup
+	for i := 0; i < guesses; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := service.CompleteChallenge(ctx, challenge.Token, "000000")
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	checked := 0
+	for err := range errs {
+		if errors.Is(err, domain.ErrInvalidMFACode) {
+			checked++
+		} else {
+			assert.ErrorIs(t, err, domain.ErrInvalidMFAChallenge)
+		}
+	}
+	assert.Equal(t, 3, checked, "no more codes are checked than MaxAttempts")
+}
+
+func TestMFAService_RegenerateRecoveryCodes(t *testing.T) {
+	service, env := newTestMFAService(t)
+	ctx := context.Background()
+	_, oldCodes := enrollTOTP(ctx, t, service, env)
+
+	newCodes, err := service.RegenerateRecoveryCodes(ctx, 1, oldCodes[0])
+	require.NoError(t, err)
+	assert.Len(t, newCodes, 4)
+
+	assert.ErrorIs(t, service.DisableMFA(ctx, 1, oldCodes[1]), domain.ErrInvalidMFACode, "old codes are replaced")
+	assert.NoError(t, service.DisableMFA(ctx, 1, newCodes[0]))
+
+	challenge, err := service.StartChallenge(ctx, 1)
+	require.NoError(t, err)
+	assert.Nil(t, challenge)
+	assert.ErrorIs(t, service.DisableMFA(ctx, 1, newCodes[1]), domain.ErrMFANotEnabled)
+}
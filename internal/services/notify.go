@@ -0,0 +1,33 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+
+	"github.com/kerim-dauren/user-service/internal/domain"
+)
+
+// actionLink adds token to the "token" query parameter of base.
+func actionLink(base, token string) (string, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("invalid link url %q: %w", base, err)
+	}
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// notifyAsync delivers n in the background so that its latency does not tell
+// registered emails apart from unknown ones. Failures are logged.
+func notifyAsync(ctx context.Context, logger *slog.Logger, notifier domain.Notifier, n domain.Notification) {
+	go func() {
+		if err := notifier.Notify(context.WithoutCancel(ctx), n); err != nil {
+			logger.Error("failed to deliver notification",
+				slog.String("kind", n.Kind), slog.Int64("user_id", n.UserID), slog.Any("error", err))
+		}
+	}()
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/kerim-dauren/user-service/internal/domain"
+)
+
+var organizationSlugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,61}[a-z0-9]$`)
+
+const maxOrganizationNameLength = 255
+
+type organizationService struct {
+	logger  *slog.Logger
+	storage domain.OrganizationStorage
+}
+
+func NewOrganizationService(logger *slog.Logger, storage domain.OrganizationStorage) domain.OrganizationService {
+	return &organizationService{
+		logger:  logger,
+		storage: storage,
+	}
+}
+
+func (s *organizationService) CreateOrganization(ctx context.Context, org *domain.Organization) (id int64, err error) {
+	defer observeDuration(s.logger, "CreateOrganization", &err)()
+
+	org.Name = strings.TrimSpace(org.Name)
+	var violations []domain.FieldViolation
+	if !organizationSlugPattern.MatchString(org.Slug) {
+		violations = append(violations, domain.FieldViolation{Field: "slug", Description: "must be 3 to 63 lowercase letters, digits or inner hyphens"})
+	}
+	if org.Name == "" || len(org.Name) > maxOrganizationNameLength {
+		violations = append(violations, domain.FieldViolation{Field: "name", Description: "must be 1 to 255 characters"})
+	}
+	if len(violations) > 0 {
+		return 0, domain.NewValidationError(violations...)
+	}
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok {
+		return 0, domain.ErrUnauthorized
+	}
+
+	id, err = s.storage.CreateOrganization(ctx, org, principal.UserID)
+	if err != nil {
+		return 0, err
+	}
+	s.logger.Info("organization created", "organization_id", id, "slug", org.Slug, "by", principal.UserID)
+	return id, nil
+}
+
+func (s *organizationService) ListOrganizations(ctx context.Context) (orgs []*domain.Organization, err error) {
+	defer observeDuration(s.logger, "ListOrganizations", &err)()
+	return s.storage.ListOrganizations(ctx)
+}
+
+func (s *organizationService) GetOrganization(ctx context.Context) (org *domain.Organization, err error) {
+	defer observeDuration(s.logger, "GetOrganization", &err)()
+	return s.storage.GetOrganization(ctx, domain.TenantFromContext(ctx))
+}
+
+func (s *organizationService) ListMembers(ctx context.Context) (members []*domain.Member, err error) {
+	defer observeDuration(s.logger, "ListMembers", &err)()
+	return s.storage.ListMembers(ctx, domain.TenantFromContext(ctx))
+}
+
+func (s *organizationService) SetMemberRole(ctx context.Context, userID int64, role string) (err error) {
+	defer observeDuration(s.logger, "SetMemberRole", &err)()
+
+	if err = validateOrganizationRole(role); err != nil {
+		return err
+	}
+
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok {
+		return domain.ErrUnauthorized
+	}
+	if principal.UserID == userID {
+		return domain.ErrForbidden
+	}
+
+	orgID := domain.TenantFromContext(ctx)
+	member, err := s.storage.GetMember(ctx, orgID, userID)
+	if err != nil {
+		return err
+	}
+	// Owners can only be made and unmade by owners, so admins cannot take over an organization.
+	if (role == domain.OrganizationRoleOwner || member.Role == domain.OrganizationRoleOwner) &&
+		principal.OrganizationRole != domain.OrganizationRoleOwner {
+		return domain.ErrForbidden
+	}
+	if member.Role == role {
+		return nil
+	}
+
+	if err = s.storage.SetMemberRole(ctx, orgID, userID, role); err != nil {
+		return err
+	}
+	s.logger.Info("member role changed", "organization_id", orgID, "user_id", userID, "role", role, "by", principal.UserID)
+	return nil
+}
+
+func validateOrganizationRole(role string) error {
+	switch role {
+	case domain.OrganizationRoleOwner, domain.OrganizationRoleAdmin, domain.OrganizationRoleMember:
+		return nil
+	}
+	return domain.NewValidationError(domain.FieldViolation{Field: "role", Description: "must be one of owner, admin, member"})
+}
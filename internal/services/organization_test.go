@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrganizationService_CreateOrganization(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storage := domain.NewMockOrganizationStorage(ctrl)
+	svc := NewOrganizationService(slog.New(slog.NewTextHandler(io.Discard, nil)), storage)
+	ctx := domain.ContextWithPrincipal(context.Background(), &domain.Principal{UserID: 7, OrganizationID: 1})
+
+	storage.EXPECT().CreateOrganization(gomock.Any(), &domain.Organization{Slug: "acme", Name: "Acme"}, int64(7)).Return(int64(2), nil)
+	id, err := svc.CreateOrganization(ctx, &domain.Organization{Slug: "acme", Name: " Acme "})
+	require.NoError(t, err, "the creator becomes the owner")
+	assert.Equal(t, int64(2), id)
+
+	_, err = svc.CreateOrganization(context.Background(), &domain.Organization{Slug: "acme", Name: "Acme"})
+	assert.ErrorIs(t, err, domain.ErrUnauthorized)
+
+	for _, org := range []*domain.Organization{
+		{Slug: "Acme", Name: "Acme"},
+		{Slug: "-acme", Name: "Acme"},
+		{Slug: "ac", Name: "Acme"},
+		{Slug: "a", Name: "Acme"},
+		{Slug: "acme", Name: "  "},
+	} {
+		_, err := svc.CreateOrganization(ctx, org)
+		var validationErr *domain.ValidationError
+		assert.ErrorAs(t, err, &validationErr, org.Slug)
+	}
+}
+
+func TestOrganizationService_SetMemberRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storage := domain.NewMockOrganizationStorage(ctrl)
+	svc := NewOrganizationService(slog.New(slog.NewTextHandler(io.Discard, nil)), storage)
+	as := func(role string) context.Context {
+		return domain.ContextWithPrincipal(context.Background(), &domain.Principal{UserID: 1, OrganizationID: 5, OrganizationRole: role})
+	}
+
+	storage.EXPECT().GetMember(gomock.Any(), int64(5), int64(2)).Return(&domain.Member{UserID: 2, Role: domain.OrganizationRoleMember}, nil).AnyTimes()
+	storage.EXPECT().GetMember(gomock.Any(), int64(5), int64(3)).Return(&domain.Member{UserID: 3, Role: domain.OrganizationRoleOwner}, nil).AnyTimes()
+	storage.EXPECT().GetMember(gomock.Any(), int64(5), int64(4)).Return(nil, domain.ErrUserNotFound).AnyTimes()
+
+	storage.EXPECT().SetMemberRole(gomock.Any(), int64(5), int64(2), domain.OrganizationRoleAdmin).Return(nil)
+	assert.NoError(t, svc.SetMemberRole(as(domain.OrganizationRoleAdmin), 2, domain.OrganizationRoleAdmin))
+
+	// Admins can neither make nor unmake owners.
+	assert.ErrorIs(t, svc.SetMemberRole(as(domain.OrganizationRoleAdmin), 2, domain.OrganizationRoleOwner), domain.ErrForbidden)
+	assert.ErrorIs(t, svc.SetMemberRole(as(domain.OrganizationRoleAdmin), 3, domain.OrganizationRoleMember), domain.ErrForbidden)
+
+	storage.EXPECT().SetMemberRole(gomock.Any(), int64(5), int64(3), domain.OrganizationRoleMember).Return(nil)
+	assert.NoError(t, svc.SetMemberRole(as(domain.OrganizationRoleOwner), 3, domain.OrganizationRoleMember))
+
+	assert.ErrorIs(t, svc.SetMemberRole(as(domain.OrganizationRoleOwner), 1, domain.OrganizationRoleMember), domain.ErrForbidden)
+	assert.ErrorIs(t, svc.SetMemberRole(as(domain.OrganizationRoleOwner), 4, domain.OrganizationRoleMember), domain.ErrUserNotFound)
+
+	var validationErr *domain.ValidationError
+	assert.ErrorAs(t, svc.SetMemberRole(as(domain.OrganizationRoleOwner), 2, "root"), &validationErr)
+}
@@ -0,0 +1,321 @@
+package services
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/kerim-dauren/user-service/pkg/tokenx"
+)
+
+const passkeyNameMaxLength = 100
+
+// PasskeyConfig identifies the relying party that passkeys are bound to.
+type PasskeyConfig struct {
+	RPID          string        // Domain of the web app, e.g. example.com
+	RPDisplayName string        // Shown by the authenticator
+	RPOrigins     []string      // Origins allowed to run ceremonies, e.g. https://app.example.com
+	CeremonyTTL   time.Duration // Time to finish a registration or login after it began
+}
+
+type passkeyService struct {
+	logger      *slog.Logger
+	userService domain.UserService
+	storage     domain.PasskeyStorage
+	webAuthn    *webauthn.WebAuthn
+	ceremonyTTL time.Duration
+	now         func() time.Time
+}
+
+func NewPasskeyService(
+	logger *slog.Logger,
+	userService domain.UserService,
+	storage domain.PasskeyStorage,
+	cfg PasskeyConfig,
+) (domain.PasskeyService, error) {
+	webAuthn, err := webauthn.New(&webauthn.Config{
+		RPID:          cfg.RPID,
+		RPDisplayName: cfg.RPDisplayName,
+		RPOrigins:     cfg.RPOrigins,
+		AuthenticatorSelection: protocol.AuthenticatorSelection{
+			ResidentKey: protocol.ResidentKeyRequirementRequired,
+			// Logins skip MFA, so the authenticator must verify the user (PIN or biometrics);
+			// presence alone would let a stolen security key in.
+			UserVerification: protocol.VerificationRequired,
+		},
+		Timeouts: webauthn.TimeoutsConfig{
+			Login:        webauthn.TimeoutConfig{Timeout: cfg.CeremonyTTL, TimeoutUVD: cfg.CeremonyTTL},
+			Registration: webauthn.TimeoutConfig{Timeout: cfg.CeremonyTTL, TimeoutUVD: cfg.CeremonyTTL},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid passkey config: %w", err)
+	}
+	return &passkeyService{
+		logger:      logger,
+		userService: userService,
+		storage:     storage,
+		webAuthn:    webAuthn,
+		ceremonyTTL: cfg.CeremonyTTL,
+		now:         time.Now,
+	}, nil
+}
+
+func (s *passkeyService) BeginRegistration(ctx context.Context, userID int64) (ceremony *domain.PasskeyCeremony, err error) {
+	defer observeDuration(s.logger, "BeginPasskeyRegistration", &err)()
+
+	user, err := s.loadUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	exclusions := make([]protocol.CredentialDescriptor, 0, len(user.credentials))
+	for _, c := range user.credentials {
+		exclusions = append(exclusions, c.Descriptor())
+	}
+	options, session, err := s.webAuthn.BeginRegistration(user, webauthn.WithExclusions(exclusions))
+	if err != nil {
+		return nil, fmt.Errorf("begin passkey registration: %w", err)
+	}
+	return s.startCeremony(ctx, domain.PasskeyRegistration, &userID, options, session)
+}
+
+func (s *passkeyService) FinishRegistration(ctx context.Context, userID int64, sessionID, name string, response []byte) (passkey *domain.Passkey, err error) {
+	defer observeDuration(s.logger, "FinishPasskeyRegistration", &err)()
+
+	name = strings.TrimSpace(name)
+	if len(name) > passkeyNameMaxLength {
+		return nil, domain.NewValidationError(domain.FieldViolation{
+			Field:       "name",
+			Description: fmt.Sprintf("must be at most %d characters", passkeyNameMaxLength),
+		})
+	}
+	session, err := s.consumeCeremony(ctx, sessionID, domain.PasskeyRegistration)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.loadUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !userOwnsSession(user, session) {
+		return nil, domain.ErrInvalidPasskeySession
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(response)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidPasskeyAttestation, err)
+	}
+	credential, err := s.webAuthn.CreateCredential(user, *session, parsed)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidPasskeyAttestation, err)
+	}
+
+	if name == "" {
+		name = fmt.Sprintf("Passkey %d", len(user.credentials)+1)
+	}
+	passkey = &domain.Passkey{
+		UserID:          userID,
+		CredentialID:    credential.ID,
+		Name:            name,
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		Transports:      make([]string, 0, len(credential.Transport)),
+		AAGUID:          credential.Authenticator.AAGUID,
+		SignCount:       credential.Authenticator.SignCount,
+		BackupEligible:  credential.Flags.BackupEligible,
+		BackupState:     credential.Flags.BackupState,
+		CreatedAt:       s.now(),
+	}
+	for _, transport := range credential.Transport {
+		passkey.Transports = append(passkey.Transports, string(transport))
+	}
+	if passkey.ID, err = s.storage.CreatePasskey(ctx, passkey); err != nil {
+		return nil, err
+	}
+	return passkey, nil
+}
+
+func (s *passkeyService) ListPasskeys(ctx context.Context, userID int64) (passkeys []*domain.Passkey, err error) {
+	defer observeDuration(s.logger, "ListPasskeys", &err)()
+
+	return s.storage.ListPasskeys(ctx, userID)
+}
+
+func (s *passkeyService) DeletePasskey(ctx context.Context, userID, id int64) (err error) {
+	defer observeDuration(s.logger, "DeletePasskey", &err)()
+
+	return s.storage.DeletePasskey(ctx, userID, id)
+}
+
+func (s *passkeyService) BeginLogin(ctx context.Context) (ceremony *domain.PasskeyCeremony, err error) {
+	defer observeDuration(s.logger, "BeginPasskeyLogin", &err)()
+
+	options, session, err := s.webAuthn.BeginDiscoverableLogin()
+	if err != nil {
+		return nil, fmt.Errorf("begin passkey login: %w", err)
+	}
+	return s.startCeremony(ctx, domain.PasskeyLogin, nil, options, session)
+}
+
+func (s *passkeyService) FinishLogin(ctx context.Context, sessionID string, response []byte) (userID int64, err error) {
+	defer observeDuration(s.logger, "FinishPasskeyLogin", &err)()
+
+	session, err := s.consumeCeremony(ctx, sessionID, domain.PasskeyLogin)
+	if err != nil {
+		return 0, err
+	}
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(response)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", domain.ErrInvalidPasskey, err)
+	}
+
+	// The assertion names the credential used; the library then checks that its user handle matches the owner.
+	var passkey *domain.Passkey
+	handler := func(rawID, _ []byte) (webauthn.User, error) {
+		found, err := s.storage.GetPasskeyByCredentialID(ctx, rawID)
+		if err != nil {
+			return nil, err
+		}
+		passkey = found
+		// The passkey, not the caller, decides which organization the account is in.
+		ctx, err := s.userService.WithUserTenant(ctx, found.UserID)
+		if err != nil {
+			return nil, err
+		}
+		return s.loadUser(ctx, found.UserID)
+	}
+	_, credential, err := s.webAuthn.ValidatePasskeyLogin(handler, *session, parsed)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", domain.ErrInvalidPasskey, err)
+	}
+	if credential.Authenticator.CloneWarning {
+		s.logger.Warn("passkey signature counter went backwards, the authenticator may be cloned",
+			"user_id", passkey.UserID, "passkey_id", passkey.ID,
+			"stored_count", passkey.SignCount, "received_count", parsed.Response.AuthenticatorData.Counter)
+		return 0, fmt.Errorf("%w: signature counter did not increase", domain.ErrInvalidPasskey)
+	}
+
+	if err = s.storage.UpdatePasskeyUsage(ctx, passkey.ID, credential.Authenticator.SignCount, credential.Flags.BackupState, s.now()); err != nil {
+		return 0, err
+	}
+	return passkey.UserID, nil
+}
+
+func (s *passkeyService) startCeremony(
+	ctx context.Context,
+	kind domain.PasskeyCeremonyKind,
+	userID *int64,
+	options any,
+	session *webauthn.SessionData,
+) (*domain.PasskeyCeremony, error) {
+	encodedOptions, err := json.Marshal(options)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(session)
+	if err != nil {
+		return nil, err
+	}
+	token, err := tokenx.NewOpaque()
+	if err != nil {
+		return nil, err
+	}
+	if err = s.storage.CreatePasskeyCeremony(ctx, &domain.PasskeyCeremonyRecord{
+		UserID:    userID,
+		Kind:      kind,
+		TokenHash: tokenx.HashOpaque(token),
+		Data:      data,
+		ExpiresAt: s.now().Add(s.ceremonyTTL),
+	}); err != nil {
+		return nil, fmt.Errorf("create passkey ceremony: %w", err)
+	}
+	return &domain.PasskeyCeremony{SessionID: token, Options: encodedOptions}, nil
+}
+
+func (s *passkeyService) consumeCeremony(ctx context.Context, sessionID string, kind domain.PasskeyCeremonyKind) (*webauthn.SessionData, error) {
+	if sessionID == "" {
+		return nil, domain.ErrInvalidPasskeySession
+	}
+	record, err := s.storage.ConsumePasskeyCeremony(ctx, tokenx.HashOpaque(sessionID), kind, s.now())
+	if err != nil {
+		return nil, err
+	}
+	var session webauthn.SessionData
+	if err = json.Unmarshal(record.Data, &session); err != nil {
+		return nil, fmt.Errorf("decode passkey ceremony: %w", err)
+	}
+	return &session, nil
+}
+
+func (s *passkeyService) loadUser(ctx context.Context, userID int64) (*passkeyUser, error) {
+	user, err := s.userService.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	passkeys, err := s.storage.ListPasskeys(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	u := &passkeyUser{user: user, credentials: make([]webauthn.Credential, 0, len(passkeys))}
+	for _, p := range passkeys {
+		u.credentials = append(u.credentials, webAuthnCredential(p))
+	}
+	return u, nil
+}
+
+func userOwnsSession(user *passkeyUser, session *webauthn.SessionData) bool {
+	return string(user.WebAuthnID()) == string(session.UserID)
+}
+
+func webAuthnCredential(p *domain.Passkey) webauthn.Credential {
+	transports := make([]protocol.AuthenticatorTransport, 0, len(p.Transports))
+	for _, t := range p.Transports {
+		transports = append(transports, protocol.AuthenticatorTransport(t))
+	}
+	return webauthn.Credential{
+		ID:              p.CredentialID,
+		PublicKey:       p.PublicKey,
+		AttestationType: p.AttestationType,
+		Transport:       transports,
+		Flags: webauthn.CredentialFlags{
+			BackupEligible: p.BackupEligible,
+			BackupState:    p.BackupState,
+		},
+		Authenticator: webauthn.Authenticator{
+			AAGUID:    p.AAGUID,
+			SignCount: p.SignCount,
+		},
+	}
+}
+
+// passkeyUser adapts a user and their passkeys to webauthn.User.
+type passkeyUser struct {
+	user        *domain.UserResponse
+	credentials []webauthn.Credential
+}
+
+// WebAuthnID is the user handle stored by authenticators. It is the user ID rather than
+// anything personal, as the specification recommends.
+func (u *passkeyUser) WebAuthnID() []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(u.user.ID))
+}
+
+func (u *passkeyUser) WebAuthnName() string {
+	return u.user.Username
+}
+
+func (u *passkeyUser) WebAuthnDisplayName() string {
+	return u.user.Username
+}
+
+func (u *passkeyUser) WebAuthnCredentials() []webauthn.Credential {
+	return u.credentials
+}
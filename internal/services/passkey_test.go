@@ -0,0 +1,384 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kerim-dauren/user-service/internal/domain"
+)
+
+type memPasskeys struct {
+	mu         sync.Mutex
+	nextID     int64
+	passkeys   map[int64]*domain.Passkey
+	ceremonies map[string]*domain.PasskeyCeremonyRecord
+}
+
+func newMemPasskeys() *memPasskeys {
+	return &memPasskeys{passkeys: map[int64]*domain.Passkey{}, ceremonies: map[string]*domain.PasskeyCeremonyRecord{}}
+}
+
+func (m *memPasskeys) CreatePasskey(_ context.Context, p *domain.Passkey) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, existing := range m.passkeys {
+		if bytes.Equal(existing.CredentialID, p.CredentialID) {
+			return 0, domain.ErrPasskeyExists
+		}
+	}
+	m.nextID++
+	stored := *p
+	stored.ID = m.nextID
+	m.passkeys[stored.ID] = &stored
+	return stored.ID, nil
+}
+
+func (m *memPasskeys) ListPasskeys(_ context.Context, userID int64) ([]*domain.Passkey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var passkeys []*domain.Passkey
+	for id := int64(1); id <= m.nextID; id++ {
+		if p, ok := m.passkeys[id]; ok && p.UserID == userID {
+			copied := *p
+			passkeys = append(passkeys, &copied)
+		}
+	}
+	return passkeys, nil
+}
+
+func (m *memPasskeys) GetPasskeyByCredentialID(_ context.Context, credentialID []byte) (*domain.Passkey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, p := range m.passkeys {
+		if bytes.Equal(p.CredentialID, credentialID) {
+			copied := *p
+			return &copied, nil
+		}
+	}
+	return nil, domain.ErrPasskeyNotFound
+}
+
+func (m *memPasskeys) UpdatePasskeyUsage(_ context.Context, id int64, signCount uint32, backupState bool, usedAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.passkeys[id]
+	if !ok {
+		return domain.ErrPasskeyNotFound
+	}
+	p.SignCount, p.BackupState, p.LastUsedAt = signCount, backupState, &usedAt
+	return nil
+}
+
+func (m *memPasskeys) DeletePasskey(_ context.Context, userID, id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if p, ok := m.passkeys[id]; !ok || p.UserID != userID {
+		return domain.ErrPasskeyNotFound
+	}
+	delete(m.passkeys, id)
+	return nil
+}
+
+func (m *memPasskeys) CreatePasskeyCeremony(_ context.Context, c *domain.PasskeyCeremonyRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored := *c
+	m.ceremonies[c.TokenHash] = &stored
+	return nil
+}
+
+func (m *memPasskeys) ConsumePasskeyCeremony(_ context.Context, hash string, kind domain.PasskeyCeremonyKind, now time.Time) (*domain.PasskeyCeremonyRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, ok := m.ceremonies[hash]
+	if !ok || c.Kind != kind || !c.ExpiresAt.After(now) {
+		return nil, domain.ErrInvalidPasskeySession
+	}
+	delete(m.ceremonies, hash)
+	return c, nil
+}
+
+const (
+	testRPID   = "example.com"
+	testOrigin = "https://app.example.com"
+)
+
+// softAuthenticator is a software passkey with a P-256 key and "none" attestation.
+type softAuthenticator struct {
+	t            *testing.T
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	userHandle   []byte
+	signCount    uint32
+	presenceOnly bool // Sign without verifying the user, like a security key without a PIN
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	credentialID := make([]byte, 16)
+	_, err = rand.Read(credentialID)
+	require.NoError(t, err)
+	return &softAuthenticator{t: t, key: key, credentialID: credentialID}
+}
+
+// create answers navigator.credentials.create() for the given ceremony options.
+func (a *softAuthenticator) create(options json.RawMessage) []byte {
+	a.t.Helper()
+	var creation protocol.CredentialCreation
+	require.NoError(a.t, json.Unmarshal(options, &creation))
+	userHandle, err := base64.RawURLEncoding.DecodeString(creation.Response.User.ID.(string))
+	require.NoError(a.t, err)
+	a.userHandle = userHandle
+
+	publicKey, err := webauthncbor.Marshal(webauthncose.EC2PublicKeyData{
+		PublicKeyData: webauthncose.PublicKeyData{
+			KeyType:   int64(webauthncose.EllipticKey),
+			Algorithm: int64(webauthncose.AlgES256),
+		},
+		Curve:  1, // P-256
+		XCoord: a.key.X.FillBytes(make([]byte, 32)),
+		YCoord: a.key.Y.FillBytes(make([]byte, 32)),
+	})
+	require.NoError(a.t, err)
+	authData := a.authData(creation.Response.RelyingParty.ID, protocol.FlagAttestedCredentialData)
+	authData = append(authData, make([]byte, 16)...) // AAGUID
+	authData = binary.BigEndian.AppendUint16(authData, uint16(len(a.credentialID)))
+	authData = append(authData, a.credentialID...)
+	authData = append(authData, publicKey...)
+
+	attestation, err := webauthncbor.Marshal(map[string]any{"fmt": "none", "attStmt": map[string]any{}, "authData": authData})
+	require.NoError(a.t, err)
+	return a.credential(map[string]any{
+		"clientDataJSON":    a.clientData(protocol.CreateCeremony, creation.Response.Challenge.String()),
+		"attestationObject": b64(attestation),
+		"transports":        []string{"internal"},
+	})
+}
+
+// get answers navigator.credentials.get() for the given ceremony options.
+func (a *softAuthenticator) get(options json.RawMessage) []byte {
+	a.t.Helper()
+	var assertion protocol.CredentialAssertion
+	require.NoError(a.t, json.Unmarshal(options, &assertion))
+
+	a.signCount++
+	authData := a.authData(assertion.Response.RelyingPartyID, 0)
+	clientData := a.clientData(protocol.AssertCeremony, assertion.Response.Challenge.String())
+	clientDataJSON, err := base64.RawURLEncoding.DecodeString(clientData)
+	require.NoError(a.t, err)
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	digest := sha256.Sum256(append(authData, clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	require.NoError(a.t, err)
+
+	return a.credential(map[string]any{
+		"clientDataJSON":    clientData,
+		"authenticatorData": b64(authData),
+		"signature":         b64(signature),
+		"userHandle":        b64(a.userHandle),
+	})
+}
+
+func (a *softAuthenticator) authData(rpID string, flags protocol.AuthenticatorFlags) []byte {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	flags |= protocol.FlagUserPresent
+	if !a.presenceOnly {
+		flags |= protocol.FlagUserVerified
+	}
+	data := append(rpIDHash[:], byte(flags))
+	return binary.BigEndian.AppendUint32(data, a.signCount)
+}
+
+func (a *softAuthenticator) clientData(ceremony protocol.CeremonyType, challenge string) string {
+	data, err := json.Marshal(protocol.CollectedClientData{Type: ceremony, Challenge: challenge, Origin: testOrigin})
+	require.NoError(a.t, err)
+	return b64(data)
+}
+
+func (a *softAuthenticator) credential(response map[string]any) []byte {
+	data, err := json.Marshal(map[string]any{
+		"id":       b64(a.credentialID),
+		"rawId":    b64(a.credentialID),
+		"type":     "public-key",
+		"response": response,
+	})
+	require.NoError(a.t, err)
+	return data
+}
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func newTestPasskeyService(t *testing.T) (domain.PasskeyService, *memPasskeys) {
+	t.Helper()
+	userService := domain.NewMockUserService(gomock.NewController(t))
+	userService.EXPECT().WithUserTenant(gomock.Any(), int64(1)).DoAndReturn(
+		func(ctx context.Context, _ int64) (context.Context, error) { return ctx, nil }).AnyTimes()
+	userService.EXPECT().GetUserByID(gomock.Any(), int64(1)).Return(alice, nil).AnyTimes()
+	storage := newMemPasskeys()
+	service, err := NewPasskeyService(slog.Default(), userService, storage, PasskeyConfig{
+		RPID:          testRPID,
+		RPDisplayName: "Test",
+		RPOrigins:     []string{testOrigin},
+		CeremonyTTL:   5 * time.Minute,
+	})
+	require.NoError(t, err)
+	return service, storage
+}
+
+// registerPasskey adds a passkey of the authenticator to alice.
+func registerPasskey(ctx context.Context, t *testing.T, service domain.PasskeyService, authenticator *softAuthenticator) *domain.Passkey {
+	t.Helper()
+	ceremony, err := service.BeginRegistration(ctx, 1)
+	require.NoError(t, err)
+	passkey, err := service.FinishRegistration(ctx, 1, ceremony.SessionID, "", authenticator.create(ceremony.Options))
+	require.NoError(t, err)
+	return passkey
+}
+
+func TestPasskeyService_Registration(t *testing.T) {
+	service, _ := newTestPasskeyService(t)
+	ctx := context.Background()
+	authenticator := newSoftAuthenticator(t)
+
+	passkey := registerPasskey(ctx, t, service, authenticator)
+	assert.Equal(t, int64(1), passkey.UserID)
+	assert.Equal(t, authenticator.credentialID, passkey.CredentialID)
+	assert.Equal(t, "Passkey 1", passkey.Name)
+	assert.Equal(t, []string{"internal"}, passkey.Transports)
+
+	// A second authenticator becomes a second passkey; the first one is excluded from its options.
+	ceremony, err := service.BeginRegistration(ctx, 1)
+	require.NoError(t, err)
+	assert.Contains(t, string(ceremony.Options), b64(authenticator.credentialID))
+	second, err := service.FinishRegistration(ctx, 1, ceremony.SessionID, " YubiKey ", newSoftAuthenticator(t).create(ceremony.Options))
+	require.NoError(t, err)
+	assert.Equal(t, "YubiKey", second.Name)
+
+	passkeys, err := service.ListPasskeys(ctx, 1)
+	require.NoError(t, err)
+	assert.Len(t, passkeys, 2)
+
+	require.NoError(t, service.DeletePasskey(ctx, 1, passkey.ID))
+	assert.ErrorIs(t, service.DeletePasskey(ctx, 1, passkey.ID), domain.ErrPasskeyNotFound)
+}
+
+func TestPasskeyService_Registration_Invalid(t *testing.T) {
+	service, _ := newTestPasskeyService(t)
+	ctx := context.Background()
+	authenticator := newSoftAuthenticator(t)
+
+	ceremony, err := service.BeginRegistration(ctx, 1)
+	require.NoError(t, err)
+	response := authenticator.create(ceremony.Options)
+	tampered := bytes.Replace(response, []byte(`"type":"public-key"`), []byte(`"type":"password"`), 1)
+	_, err = service.FinishRegistration(ctx, 1, ceremony.SessionID, "", tampered)
+	assert.ErrorIs(t, err, domain.ErrInvalidPasskeyAttestation)
+
+	_, err = service.FinishRegistration(ctx, 1, ceremony.SessionID, "", response)
+	assert.ErrorIs(t, err, domain.ErrInvalidPasskeySession, "a session is used only once")
+
+	login, err := service.BeginLogin(ctx)
+	require.NoError(t, err)
+	_, err = service.FinishRegistration(ctx, 1, login.SessionID, "", response)
+	assert.ErrorIs(t, err, domain.ErrInvalidPasskeySession, "login sessions cannot register passkeys")
+}
+
+func TestPasskeyService_Login(t *testing.T) {
+	service, storage := newTestPasskeyService(t)
+	ctx := context.Background()
+	authenticator := newSoftAuthenticator(t)
+	passkey := registerPasskey(ctx, t, service, authenticator)
+
+	for i := 1; i <= 2; i++ {
+		ceremony, err := service.BeginLogin(ctx)
+		require.NoError(t, err)
+		userID, err := service.FinishLogin(ctx, ceremony.SessionID, authenticator.get(ceremony.Options))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), userID)
+
+		stored := storage.passkeys[passkey.ID]
+		assert.Equal(t, uint32(i), stored.SignCount)
+		assert.NotNil(t, stored.LastUsedAt)
+	}
+}
+
+func TestPasskeyService_Login_ClonedAuthenticator(t *testing.T) {
+	service, storage := newTestPasskeyService(t)
+	ctx := context.Background()
+	authenticator := newSoftAuthenticator(t)
+	passkey := registerPasskey(ctx, t, service, authenticator)
+
+	ceremony, err := service.BeginLogin(ctx)
+	require.NoError(t, err)
+	_, err = service.FinishLogin(ctx, ceremony.SessionID, authenticator.get(ceremony.Options))
+	require.NoError(t, err)
+
+	clone := *authenticator
+	clone.signCount = 0
+	ceremony, err = service.BeginLogin(ctx)
+	require.NoError(t, err)
+	_, err = service.FinishLogin(ctx, ceremony.SessionID, clone.get(ceremony.Options))
+	assert.ErrorIs(t, err, domain.ErrInvalidPasskey)
+	assert.Equal(t, uint32(1), storage.passkeys[passkey.ID].SignCount)
+}
+
+func TestPasskeyService_Login_WithoutUserVerification(t *testing.T) {
+	service, storage := newTestPasskeyService(t)
+	ctx := context.Background()
+	authenticator := newSoftAuthenticator(t)
+	passkey := registerPasskey(ctx, t, service, authenticator)
+
+	stolen := *authenticator
+	stolen.presenceOnly = true
+	ceremony, err := service.BeginLogin(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, string(ceremony.Options), `"userVerification":"required"`)
+	_, err = service.FinishLogin(ctx, ceremony.SessionID, stolen.get(ceremony.Options))
+	assert.ErrorIs(t, err, domain.ErrInvalidPasskey, "a touch alone does not skip MFA")
+	assert.Nil(t, storage.passkeys[passkey.ID].LastUsedAt)
+}
+
+func TestPasskeyService_Login_Invalid(t *testing.T) {
+	service, _ := newTestPasskeyService(t)
+	ctx := context.Background()
+	authenticator := newSoftAuthenticator(t)
+	registerPasskey(ctx, t, service, authenticator)
+
+	ceremony, err := service.BeginLogin(ctx)
+	require.NoError(t, err)
+	_, err = service.FinishLogin(ctx, ceremony.SessionID, newSoftAuthenticator(t).get(ceremony.Options))
+	assert.ErrorIs(t, err, domain.ErrInvalidPasskey, "unknown credential")
+
+	// An assertion answering another challenge.
+	other, err := service.BeginLogin(ctx)
+	require.NoError(t, err)
+	ceremony, err = service.BeginLogin(ctx)
+	require.NoError(t, err)
+	_, err = service.FinishLogin(ctx, ceremony.SessionID, authenticator.get(other.Options))
+	assert.ErrorIs(t, err, domain.ErrInvalidPasskey)
+
+	_, err = service.FinishLogin(ctx, "unknown", authenticator.get(other.Options))
+	assert.ErrorIs(t, err, domain.ErrInvalidPasskeySession)
+}
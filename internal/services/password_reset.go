@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/kerim-dauren/user-service/pkg/tokenx"
+)
+
+// PasswordResetConfig configures reset links.
+type PasswordResetConfig struct {
+	TTL         time.Duration // Lifetime of a reset token
+	URL         string        // Page that receives the token in the "token" query parameter
+	Limit       int           // Links sent to one account per LimitWindow; further requests are dropped
+	LimitWindow time.Duration
+}
+
+type passwordResetService struct {
+	logger      *slog.Logger
+	userService domain.UserService
+	resets      domain.PasswordResetStorage
+	validator   *UserValidator
+	notifier    domain.Notifier
+	cfg         PasswordResetConfig
+	now         func() time.Time
+}
+
+func NewPasswordResetService(
+	logger *slog.Logger,
+	userService domain.UserService,
+	resets domain.PasswordResetStorage,
+	validator *UserValidator,
+	notifier domain.Notifier,
+	cfg PasswordResetConfig,
+) domain.PasswordResetService {
+	return &passwordResetService{
+		logger:      logger,
+		userService: userService,
+		resets:      resets,
+		validator:   validator,
+		notifier:    notifier,
+		cfg:         cfg,
+		now:         time.Now,
+	}
+}
+
+func (s *passwordResetService) RequestPasswordReset(ctx context.Context, email string) (err error) {
+	defer observeDuration(s.logger, "RequestPasswordReset", &err)()
+
+	if email == "" {
+		return domain.NewValidationError(domain.FieldViolation{Field: "email", Description: "is required"})
+	}
+	user, err := s.userService.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			// Unknown emails look exactly like known ones to the caller.
+			return nil
+		}
+		return err
+	}
+
+	token, err := tokenx.NewOpaque()
+	if err != nil {
+		return err
+	}
+	now := s.now()
+	expiresAt := now.Add(s.cfg.TTL)
+	_, err = s.resets.CreatePasswordResetToken(ctx, &domain.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: tokenx.HashOpaque(token),
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+	}, s.cfg.Limit, now.Add(-s.cfg.LimitWindow))
+	if errors.Is(err, domain.ErrEmailThrottled) {
+		s.logger.Warn("password reset throttled", "user_id", user.ID)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("create password reset token: %w", err)
+	}
+	link, err := actionLink(s.cfg.URL, token)
+	if err != nil {
+		return err
+	}
+	notifyAsync(ctx, s.logger, s.notifier, domain.Notification{
+		Kind:      domain.NotificationPasswordReset,
+		UserID:    user.ID,
+		Email:     user.Email,
+		Username:  user.Username,
+		Link:      link,
+		ExpiresAt: expiresAt,
+	})
+	return nil
+}
+
+func (s *passwordResetService) ConfirmPasswordReset(ctx context.Context, token, newPassword string) (err error) {
+	defer observeDuration(s.logger, "ConfirmPasswordReset", &err)()
+
+	// Check the password first so that a rejected one does not burn the token.
+	if err = s.validator.ValidatePassword("new_password", newPassword); err != nil {
+		return err
+	}
+	if token == "" {
+		return domain.ErrInvalidPasswordResetToken
+	}
+
+	tokenHash := tokenx.HashOpaque(token)
+	userID, err := s.resets.GetPasswordResetTokenUser(ctx, tokenHash, s.now())
+	if err != nil {
+		return err
+	}
+	// The rest of the policy needs the account, which only the token identifies,
+	// together with its organization.
+	ctx, err = s.userService.WithUserTenant(ctx, userID)
+	var user *domain.UserResponse
+	if err == nil {
+		user, err = s.userService.GetUserByID(ctx, userID)
+	}
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return domain.ErrInvalidPasswordResetToken
+	}
+	if err != nil {
+		return err
+	}
+	if err = s.validator.ValidatePassword("new_password", newPassword, user.Username, user.Email); err != nil {
+		return err
+	}
+
+	// The token is used up only once the password also passed the history check, so a
+	// rejected one can be retried with the same link.
+	err = s.userService.ResetPassword(ctx, userID, newPassword, func(ctx context.Context) error {
+		_, err := s.resets.ConsumePasswordResetToken(ctx, tokenHash, s.now())
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err = s.resets.DeleteUserPasswordResetTokens(ctx, userID); err != nil {
+		return fmt.Errorf("delete password reset tokens: %w", err)
+	}
+	return nil
+}
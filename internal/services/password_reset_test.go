@@ -0,0 +1,288 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/kerim-dauren/user-service/pkg/tokenx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memPasswordResets is an in-memory domain.PasswordResetStorage.
+type memPasswordResets struct {
+	mu     sync.Mutex
+	nextID int64
+	tokens map[int64]*domain.PasswordResetToken
+}
+
+func newMemPasswordResets() *memPasswordResets {
+	return &memPasswordResets{tokens: map[int64]*domain.PasswordResetToken{}}
+}
+
+func (m *memPasswordResets) CreatePasswordResetToken(_ context.Context, t *domain.PasswordResetToken, limit int, since time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	count := 0
+	for _, stored := range m.tokens {
+		if stored.UserID == t.UserID && stored.CreatedAt.After(since) {
+			count++
+		}
+	}
+	if count >= limit {
+		return 0, domain.ErrEmailThrottled
+	}
+	m.nextID++
+	stored := *t
+	stored.ID = m.nextID
+	m.tokens[stored.ID] = &stored
+	return stored.ID, nil
+}
+
+func (m *memPasswordResets) GetPasswordResetTokenUser(_ context.Context, hash string, now time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, t := range m.tokens {
+		if t.TokenHash == hash && t.UsedAt == nil && t.ExpiresAt.After(now) {
+			return t.UserID, nil
+		}
+	}
+	return 0, domain.ErrInvalidPasswordResetToken
+}
+
+func (m *memPasswordResets) ConsumePasswordResetToken(_ context.Context, hash string, now time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, t := range m.tokens {
+		if t.TokenHash == hash && t.UsedAt == nil && t.ExpiresAt.After(now) {
+			t.UsedAt = &now
+			return t.UserID, nil
+		}
+	}
+	return 0, domain.ErrInvalidPasswordResetToken
+}
+
+func (m *memPasswordResets) DeleteUserPasswordResetTokens(_ context.Context, userID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, t := range m.tokens {
+		if t.UserID == userID {
+			delete(m.tokens, id)
+		}
+	}
+	return nil
+}
+
+// chanNotifier hands notifications to the test, which waits for the asynchronous delivery.
+type chanNotifier chan domain.Notification
+
+func (c chanNotifier) Notify(_ context.Context, n domain.Notification) error {
+	c <- n
+	return nil
+}
+
+func newTestPasswordResetService(t *testing.T, userService domain.UserService) (*passwordResetService, *memPasswordResets, chanNotifier) {
+	t.Helper()
+	resets := newMemPasswordResets()
+	notifier := make(chanNotifier, 1)
+	service := NewPasswordResetService(slog.Default(), userService, resets, testValidator(t), notifier, PasswordResetConfig{
+		TTL:         30 * time.Minute,
+		URL:         "https://example.com/reset?lang=en",
+		Limit:       2,
+		LimitWindow: time.Hour,
+	})
+	return service.(*passwordResetService), resets, notifier
+}
+
+func receive(t *testing.T, notifier chanNotifier) domain.Notification {
+	t.Helper()
+	select {
+	case n := <-notifier:
+		return n
+	case <-time.After(time.Second):
+		t.Fatal("notification was not delivered")
+		return domain.Notification{}
+	}
+}
+
+func TestPasswordResetService_Request(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := domain.NewMockUserService(ctrl)
+	service, resets, notifier := newTestPasswordResetService(t, userService)
+	ctx := context.Background()
+
+	userService.EXPECT().GetUserByEmail(ctx, alice.Email).Return(alice, nil)
+
+	require.NoError(t, service.RequestPasswordReset(ctx, alice.Email))
+
+	n := receive(t, notifier)
+	assert.Equal(t, domain.NotificationPasswordReset, n.Kind)
+	assert.Equal(t, alice.ID, n.UserID)
+	assert.Equal(t, alice.Email, n.Email)
+
+	link, err := url.Parse(n.Link)
+	require.NoError(t, err)
+	assert.Equal(t, "en", link.Query().Get("lang"))
+	token := link.Query().Get("token")
+	require.NotEmpty(t, token)
+
+	require.Len(t, resets.tokens, 1)
+	stored := resets.tokens[1]
+	assert.Equal(t, tokenx.HashOpaque(token), stored.TokenHash, "only the hash is stored")
+	assert.Equal(t, n.ExpiresAt, stored.ExpiresAt)
+}
+
+func TestPasswordResetService_Request_UnknownEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := domain.NewMockUserService(ctrl)
+	service, resets, notifier := newTestPasswordResetService(t, userService)
+	ctx := context.Background()
+
+	userService.EXPECT().GetUserByEmail(ctx, "nobody@example.com").Return(nil, domain.ErrUserNotFound)
+
+	assert.NoError(t, service.RequestPasswordReset(ctx, "nobody@example.com"))
+	assert.Empty(t, resets.tokens)
+	assert.Empty(t, notifier)
+}
+
+func TestPasswordResetService_Request_Throttled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := domain.NewMockUserService(ctrl)
+	service, resets, notifier := newTestPasswordResetService(t, userService)
+	ctx := context.Background()
+
+	userService.EXPECT().GetUserByEmail(ctx, alice.Email).Return(alice, nil).Times(4)
+	for i := 0; i < 2; i++ {
+		require.NoError(t, service.RequestPasswordReset(ctx, alice.Email))
+		receive(t, notifier)
+	}
+
+	assert.NoError(t, service.RequestPasswordReset(ctx, alice.Email), "throttled requests look like any other")
+	assert.Len(t, resets.tokens, 2)
+	assert.Empty(t, notifier)
+
+	service.now = func() time.Time { return time.Now().Add(61 * time.Minute) }
+	require.NoError(t, service.RequestPasswordReset(ctx, alice.Email))
+	receive(t, notifier)
+	assert.Len(t, resets.tokens, 3)
+}
+
+func TestPasswordResetService_Confirm(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := domain.NewMockUserService(ctrl)
+	service, resets, notifier := newTestPasswordResetService(t, userService)
+	ctx := context.Background()
+
+	userService.EXPECT().GetUserByEmail(ctx, alice.Email).Return(alice, nil).Times(2)
+	require.NoError(t, service.RequestPasswordReset(ctx, alice.Email))
+	link, _ := url.Parse(receive(t, notifier).Link)
+	token := link.Query().Get("token")
+	require.NoError(t, service.RequestPasswordReset(ctx, alice.Email))
+	receive(t, notifier)
+
+	userService.EXPECT().WithUserTenant(ctx, alice.ID).Return(ctx, nil)
+	userService.EXPECT().GetUserByID(ctx, alice.ID).Return(alice, nil)
+	userService.EXPECT().ResetPassword(ctx, alice.ID, "newpassword", gomock.Any()).DoAndReturn(claimReset)
+
+	require.NoError(t, service.ConfirmPasswordReset(ctx, token, "newpassword"))
+	assert.Empty(t, resets.tokens, "every outstanding token of the user is invalidated")
+
+	err := service.ConfirmPasswordReset(ctx, token, "newpassword")
+	assert.ErrorIs(t, err, domain.ErrInvalidPasswordResetToken, "tokens are single-use")
+}
+
+func TestPasswordResetService_Confirm_Expired(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := domain.NewMockUserService(ctrl)
+	service, _, notifier := newTestPasswordResetService(t, userService)
+	ctx := context.Background()
+
+	userService.EXPECT().GetUserByEmail(ctx, alice.Email).Return(alice, nil)
+	require.NoError(t, service.RequestPasswordReset(ctx, alice.Email))
+	link, _ := url.Parse(receive(t, notifier).Link)
+
+	service.now = func() time.Time { return time.Now().Add(time.Hour) }
+
+	err := service.ConfirmPasswordReset(ctx, link.Query().Get("token"), "newpassword")
+	assert.ErrorIs(t, err, domain.ErrInvalidPasswordResetToken)
+}
+
+func TestPasswordResetService_Confirm_InvalidPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := domain.NewMockUserService(ctrl)
+	service, resets, notifier := newTestPasswordResetService(t, userService)
+	ctx := context.Background()
+
+	userService.EXPECT().GetUserByEmail(ctx, alice.Email).Return(alice, nil)
+	require.NoError(t, service.RequestPasswordReset(ctx, alice.Email))
+	link, _ := url.Parse(receive(t, notifier).Link)
+
+	err := service.ConfirmPasswordReset(ctx, link.Query().Get("token"), "short")
+
+	var validationErr *domain.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "new_password", validationErr.Violations[0].Field)
+	assert.Nil(t, resets.tokens[1].UsedAt, "a rejected password does not burn the token")
+}
+
+func TestPasswordResetService_Confirm_PasswordContainsIdentity(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := domain.NewMockUserService(ctrl)
+	service, resets, notifier := newTestPasswordResetService(t, userService)
+	ctx := context.Background()
+
+	userService.EXPECT().GetUserByEmail(ctx, alice.Email).Return(alice, nil)
+	require.NoError(t, service.RequestPasswordReset(ctx, alice.Email))
+	link, _ := url.Parse(receive(t, notifier).Link)
+
+	userService.EXPECT().WithUserTenant(ctx, alice.ID).Return(ctx, nil)
+	userService.EXPECT().GetUserByID(ctx, alice.ID).Return(alice, nil)
+
+	err := service.ConfirmPasswordReset(ctx, link.Query().Get("token"), "alice-2024")
+
+	var validationErr *domain.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "must not contain the username or email address", validationErr.Violations[0].Description)
+	assert.Nil(t, resets.tokens[1].UsedAt, "a rejected password does not burn the token")
+}
+
+func TestPasswordResetService_Confirm_ReusedPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userService := domain.NewMockUserService(ctrl)
+	service, resets, notifier := newTestPasswordResetService(t, userService)
+	ctx := context.Background()
+
+	userService.EXPECT().GetUserByEmail(ctx, alice.Email).Return(alice, nil)
+	require.NoError(t, service.RequestPasswordReset(ctx, alice.Email))
+	link, _ := url.Parse(receive(t, notifier).Link)
+
+	userService.EXPECT().WithUserTenant(ctx, alice.ID).Return(ctx, nil)
+	userService.EXPECT().GetUserByID(ctx, alice.ID).Return(alice, nil)
+	reused := domain.NewValidationError(domain.FieldViolation{Field: "new_password", Description: "must differ from the last 3 passwords"})
+	userService.EXPECT().ResetPassword(ctx, alice.ID, "oldpassword", gomock.Any()).Return(reused)
+
+	err := service.ConfirmPasswordReset(ctx, link.Query().Get("token"), "oldpassword")
+	assert.ErrorIs(t, err, reused)
+	assert.Nil(t, resets.tokens[1].UsedAt, "a rejected password does not burn the token")
+}
+
+func TestPasswordResetService_Confirm_InvalidToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	service, _, _ := newTestPasswordResetService(t, domain.NewMockUserService(ctrl))
+
+	for _, token := range []string{"", "unknown"} {
+		err := service.ConfirmPasswordReset(context.Background(), token, "newpassword")
+		assert.ErrorIs(t, err, domain.ErrInvalidPasswordResetToken, token)
+	}
+}
+
+// claimReset stands for a domain.UserService.ResetPassword whose checks all pass.
+func claimReset(ctx context.Context, _ int64, _ string, claim func(ctx context.Context) error) error {
+	return claim(ctx)
+}
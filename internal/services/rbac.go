@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/kerim-dauren/user-service/internal/domain"
+)
+
+type roleService struct {
+	logger      *slog.Logger
+	userService domain.UserService
+	storage     domain.RoleStorage
+	now         func() time.Time
+}
+
+func NewRoleService(logger *slog.Logger, userService domain.UserService, storage domain.RoleStorage) domain.RoleService {
+	return &roleService{
+		logger:      logger,
+		userService: userService,
+		storage:     storage,
+		now:         time.Now,
+	}
+}
+
+func (s *roleService) ListRoles(ctx context.Context) (roles []*domain.Role, err error) {
+	defer observeDuration(s.logger, "ListRoles", &err)()
+	return s.storage.ListRoles(ctx)
+}
+
+func (s *roleService) ListUserRoles(ctx context.Context, userID int64) (roles []*domain.Role, err error) {
+	defer observeDuration(s.logger, "ListUserRoles", &err)()
+
+	if _, err = s.userService.GetUserByID(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.storage.ListUserRoles(ctx, userID)
+}
+
+func (s *roleService) AssignRole(ctx context.Context, userID int64, name string) (err error) {
+	defer observeDuration(s.logger, "AssignRole", &err)()
+
+	role, err := s.userRole(ctx, userID, name)
+	if err != nil {
+		return err
+	}
+	if err = s.storage.AssignRole(ctx, userID, role.ID, s.now()); err != nil {
+		return err
+	}
+	s.logger.Info("role assigned", "user_id", userID, "role", name, "by", actorID(ctx))
+	return nil
+}
+
+func (s *roleService) RevokeRole(ctx context.Context, userID int64, name string) (err error) {
+	defer observeDuration(s.logger, "RevokeRole", &err)()
+
+	role, err := s.userRole(ctx, userID, name)
+	if err != nil {
+		return err
+	}
+	if err = s.storage.RevokeRole(ctx, userID, role.ID); err != nil {
+		return err
+	}
+	s.logger.Info("role revoked", "user_id", userID, "role", name, "by", actorID(ctx))
+	return nil
+}
+
+func (s *roleService) UserAccess(ctx context.Context, userID int64) (access *domain.Access, err error) {
+	defer observeDuration(s.logger, "UserAccess", &err)()
+
+	roles, err := s.storage.ListUserRoles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	access = &domain.Access{}
+	for _, role := range roles {
+		access.Roles = append(access.Roles, role.Name)
+		access.Permissions = append(access.Permissions, role.Permissions...)
+	}
+	slices.Sort(access.Permissions)
+	access.Permissions = slices.Compact(access.Permissions)
+	return access, nil
+}
+
+// userRole checks that the user exists and returns the role named name.
+func (s *roleService) userRole(ctx context.Context, userID int64, name string) (*domain.Role, error) {
+	if _, err := s.userService.GetUserByID(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.storage.GetRoleByName(ctx, name)
+}
+
+// actorID returns the ID of the authenticated caller for audit logs, or 0.
+func actorID(ctx context.Context) int64 {
+	if principal, ok := domain.PrincipalFromContext(ctx); ok {
+		return principal.UserID
+	}
+	return 0
+}
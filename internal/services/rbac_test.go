@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRoleService(t *testing.T) (domain.RoleService, *domain.MockUserService, *domain.MockRoleStorage) {
+	ctrl := gomock.NewController(t)
+	users := domain.NewMockUserService(ctrl)
+	storage := domain.NewMockRoleStorage(ctrl)
+	return NewRoleService(slog.New(slog.NewTextHandler(io.Discard, nil)), users, storage), users, storage
+}
+
+func TestRoleService_UserAccess(t *testing.T) {
+	svc, _, storage := newTestRoleService(t)
+
+	storage.EXPECT().ListUserRoles(gomock.Any(), int64(1)).Return([]*domain.Role{
+		{Name: "support", Permissions: []string{domain.PermissionUsersRead, domain.PermissionUsersUnlock}},
+		{Name: "auditor", Permissions: []string{domain.PermissionRolesRead, domain.PermissionUsersRead}},
+	}, nil)
+
+	access, err := svc.UserAccess(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"support", "auditor"}, access.Roles)
+	assert.Equal(t, []string{domain.PermissionRolesRead, domain.PermissionUsersRead, domain.PermissionUsersUnlock}, access.Permissions)
+}
+
+func TestRoleService_AssignRole(t *testing.T) {
+	svc, users, storage := newTestRoleService(t)
+	ctx := context.Background()
+
+	users.EXPECT().GetUserByID(gomock.Any(), int64(1)).Return(&domain.UserResponse{ID: 1}, nil).Times(2)
+	storage.EXPECT().GetRoleByName(gomock.Any(), domain.RoleAdmin).Return(&domain.Role{ID: 10, Name: domain.RoleAdmin}, nil)
+	storage.EXPECT().AssignRole(gomock.Any(), int64(1), int64(10), gomock.Any()).Return(nil)
+	require.NoError(t, svc.AssignRole(ctx, 1, domain.RoleAdmin))
+
+	storage.EXPECT().GetRoleByName(gomock.Any(), "unknown").Return(nil, domain.ErrRoleNotFound)
+	assert.ErrorIs(t, svc.AssignRole(ctx, 1, "unknown"), domain.ErrRoleNotFound)
+
+	users.EXPECT().GetUserByID(gomock.Any(), int64(2)).Return(nil, domain.ErrUserNotFound)
+	assert.ErrorIs(t, svc.AssignRole(ctx, 2, domain.RoleAdmin), domain.ErrUserNotFound)
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/kerim-dauren/user-service/internal/domain"
+)
+
+type sessionService struct {
+	logger        *slog.Logger
+	sessions      domain.SessionStorage
+	refreshTokens domain.RefreshTokenStorage
+}
+
+func NewSessionService(
+	logger *slog.Logger,
+	sessions domain.SessionStorage,
+	refreshTokens domain.RefreshTokenStorage,
+) domain.SessionService {
+	return &sessionService{
+		logger:        logger,
+		sessions:      sessions,
+		refreshTokens: refreshTokens,
+	}
+}
+
+func (s *sessionService) ListSessions(ctx context.Context, userID int64) (sessions []*domain.Session, err error) {
+	defer observeDuration(s.logger, "ListSessions", &err)()
+	return s.sessions.ListUserSessions(ctx, userID)
+}
+
+func (s *sessionService) RevokeSession(ctx context.Context, userID int64, sessionID string) (err error) {
+	defer observeDuration(s.logger, "RevokeSession", &err)()
+
+	if err = s.sessions.RevokeSession(ctx, userID, sessionID); err != nil {
+		return err
+	}
+	if err = s.refreshTokens.RevokeRefreshTokenFamily(ctx, sessionID); err != nil {
+		return fmt.Errorf("revoke refresh token family: %w", err)
+	}
+	return nil
+}
+
+func (s *sessionService) RevokeOtherSessions(ctx context.Context, userID int64, keepSessionID string) (err error) {
+	defer observeDuration(s.logger, "RevokeOtherSessions", &err)()
+
+	revoked, err := s.sessions.RevokeUserSessions(ctx, userID, keepSessionID)
+	if err != nil {
+		return err
+	}
+	for _, id := range revoked {
+		if err = s.refreshTokens.RevokeRefreshTokenFamily(ctx, id); err != nil {
+			return fmt.Errorf("revoke refresh token family: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionService_RevokeSession_OtherUser(t *testing.T) {
+	ctx := context.Background()
+	env := &authTestEnv{refreshTokens: newMemRefreshTokens(), sessions: newMemSessions()}
+	require.NoError(t, env.sessions.CreateSession(ctx, &domain.Session{ID: "s1", UserID: 1}))
+
+	sessions := NewSessionService(slog.Default(), env.sessions, env.refreshTokens)
+	assert.ErrorIs(t, sessions.RevokeSession(ctx, 2, "s1"), domain.ErrSessionNotFound)
+}
+
+func TestSessionService_RevokeOtherSessions(t *testing.T) {
+	ctx := context.Background()
+	env := &authTestEnv{refreshTokens: newMemRefreshTokens(), sessions: newMemSessions()}
+	for _, id := range []string{"s1", "s2", "s3"} {
+		require.NoError(t, env.sessions.CreateSession(ctx, &domain.Session{ID: id, UserID: 1}))
+		_, err := env.refreshTokens.CreateRefreshToken(ctx, &domain.RefreshToken{UserID: 1, FamilyID: id, TokenHash: id})
+		require.NoError(t, err)
+	}
+
+	sessions := NewSessionService(slog.Default(), env.sessions, env.refreshTokens)
+	require.NoError(t, sessions.RevokeOtherSessions(ctx, 1, "s2"))
+
+	active, err := sessions.ListSessions(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	assert.Equal(t, "s2", active[0].ID)
+
+	for _, id := range []string{"s1", "s3"} {
+		token, err := env.refreshTokens.GetRefreshTokenByHash(ctx, id)
+		require.NoError(t, err)
+		assert.NotNil(t, token.RevokedAt, "refresh tokens of %s must be revoked", id)
+	}
+	kept, err := env.refreshTokens.GetRefreshTokenByHash(ctx, "s2")
+	require.NoError(t, err)
+	assert.Nil(t, kept.RevokedAt)
+}
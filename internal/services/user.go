@@ -2,16 +2,23 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"github.com/kerim-dauren/user-service/internal/domain"
 	"github.com/kerim-dauren/user-service/pkg/hashx"
+	"github.com/kerim-dauren/user-service/pkg/tokenx"
 	"github.com/prometheus/client_golang/prometheus"
 	"log/slog"
+	"sync"
 	"time"
 )
 
 var (
 	requestDuration *prometheus.HistogramVec
+	loginAttempts   *prometheus.CounterVec
+	loginLockouts   *prometheus.CounterVec
 )
 
 func init() {
@@ -23,31 +30,83 @@ func init() {
 		},
 		[]string{"method", "result"},
 	)
+	loginAttempts = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "user_service_login_attempts_total",
+			Help: "Password logins by result: success, failure, locked or error",
+		},
+		[]string{"result"},
+	)
+	loginLockouts = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "user_service_login_lockouts_total",
+			Help: "Accounts and client IPs locked after too many failed logins",
+		},
+		[]string{"scope"},
+	)
+	prometheus.MustRegister(requestDuration, loginAttempts, loginLockouts)
 }
 
 type userService struct {
-	logger         *slog.Logger
-	userStorage    domain.UserStorage
-	passwordHasher hashx.Hasher
+	logger          *slog.Logger
+	userStorage     domain.UserStorage
+	passwordHasher  hashx.ContextHasher
+	passwordChecker hashx.ContextChecker
+	validator       *UserValidator
+	pageTokens      *tokenx.PageTokenCodec
+	sessions        domain.SessionService
+	history         domain.PasswordHistoryStorage
+	historyConfig   PasswordHistoryConfig
+
+	dummyHashMu sync.Mutex
+	dummyHash   string // See dummyPasswordHash
+}
+
+// PasswordHistoryConfig controls which previous passwords cannot be reused.
+type PasswordHistoryConfig struct {
+	Size      int           // Number of most recent passwords, the current one included, that cannot be reused; 0 disables the history
+	Retention time.Duration // Replaced passwords are forgotten after this long; 0 keeps them until they fall out of Size
 }
 
 func NewUserService(
 	logger *slog.Logger,
 	userStorage domain.UserStorage,
-	passwordHasher hashx.Hasher,
+	passwordHasher hashx.ContextHasher,
+	passwordChecker hashx.ContextChecker,
+	validator *UserValidator,
+	pageTokens *tokenx.PageTokenCodec,
+	sessions domain.SessionService,
+	history domain.PasswordHistoryStorage,
+	historyConfig PasswordHistoryConfig,
 ) domain.UserService {
 	return &userService{
-		logger:         logger,
-		userStorage:    userStorage,
-		passwordHasher: passwordHasher,
+		logger:          logger,
+		userStorage:     userStorage,
+		passwordHasher:  passwordHasher,
+		passwordChecker: passwordChecker,
+		validator:       validator,
+		pageTokens:      pageTokens,
+		sessions:        sessions,
+		history:         history,
+		historyConfig:   historyConfig,
 	}
 }
 
 func (s *userService) CreateUser(ctx context.Context, user *domain.User) (id int64, err error) {
 	defer s.observeDuration("CreateUser", &err)()
-	hashedPass, err := s.passwordHasher.Hash(user.Password)
+	if err = s.validator.ValidateUser(user); err != nil {
+		return 0, err
+	}
+	// Never the tenant of the request: anyone may sign up, but not into any organization.
+	if user.OrganizationID == 0 {
+		user.OrganizationID = domain.DefaultOrganizationID
+	}
+	if user.OrganizationRole == "" {
+		user.OrganizationRole = domain.OrganizationRoleMember
+	}
+	hashedPass, err := s.hashPassword(ctx, user.Password)
 	if err != nil {
-		return 0, fmt.Errorf("hash error: %w", err)
+		return 0, err
 	}
 	user.Password = hashedPass
 	return s.userStorage.CreateUser(ctx, user)
@@ -60,18 +119,219 @@ func (s *userService) GetUserByID(ctx context.Context, id int64) (user *domain.U
 	if err != nil {
 		return nil, err
 	}
-	return &domain.UserResponse{
-		ID:       u.ID,
-		Username: u.Username,
-		Email:    u.Email,
-	}, nil
+	return toUserResponse(u), nil
 }
 
 func (s *userService) UpdateUser(ctx context.Context, user *domain.User) (err error) {
 	defer s.observeDuration("UpdateUser", &err)()
-	hashedPass, err := s.passwordHasher.Hash(user.Password)
-	user.Password = hashedPass
-	return s.userStorage.UpdateUser(ctx, user)
+	if err = s.validator.ValidateUserUpdate(user); err != nil {
+		return err
+	}
+	password := user.Password
+	user.Password = ""
+	var previousHash string
+	if password != "" && s.historyConfig.Size > 0 {
+		current, err := s.userStorage.GetUserByID(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		previousHash = current.Password
+	}
+	if err = s.userStorage.UpdateUser(ctx, user); err != nil {
+		return err
+	}
+	if password == "" {
+		return nil
+	}
+	return s.setPassword(ctx, user.ID, password, previousHash)
+}
+
+func (s *userService) PatchUser(ctx context.Context, id int64, patch *domain.UserPatch) (user *domain.UserResponse, err error) {
+	defer s.observeDuration("PatchUser", &err)()
+	if err = s.validator.ValidatePatch(patch); err != nil {
+		return nil, err
+	}
+	if !patch.IsEmpty() {
+		if err = s.userStorage.PatchUser(ctx, id, patch); err != nil {
+			return nil, err
+		}
+	}
+
+	u, err := s.userStorage.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return toUserResponse(u), nil
+}
+
+func (s *userService) ChangePassword(ctx context.Context, id int64, currentPassword, newPassword string) (err error) {
+	defer s.observeDuration("ChangePassword", &err)()
+
+	if err = s.validator.ValidatePassword("new_password", newPassword); err != nil {
+		return err
+	}
+	if newPassword == currentPassword {
+		return domain.NewValidationError(domain.FieldViolation{Field: "new_password", Description: "must differ from the current password"})
+	}
+
+	u, err := s.userStorage.GetUserByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	match, err := s.checkPassword(ctx, u.Password, currentPassword)
+	if err != nil {
+		return err
+	}
+	if !match {
+		return domain.ErrIncorrectPassword
+	}
+	if err = s.validator.ValidatePassword("new_password", newPassword, u.Username, u.Email); err != nil {
+		return err
+	}
+	if err = s.checkPasswordReuse(ctx, u, newPassword); err != nil {
+		return err
+	}
+	if err = s.setPassword(ctx, id, newPassword, u.Password); err != nil {
+		return err
+	}
+
+	// Whoever may know the old password loses access; the caller's session is kept.
+	var keepSessionID string
+	if principal, ok := domain.PrincipalFromContext(ctx); ok && principal.UserID == id {
+		keepSessionID = principal.SessionID
+	}
+	if err = s.sessions.RevokeOtherSessions(ctx, id, keepSessionID); err != nil {
+		return fmt.Errorf("revoke other sessions: %w", err)
+	}
+	return nil
+}
+
+func (s *userService) ResetPassword(ctx context.Context, id int64, newPassword string, claim func(ctx context.Context) error) (err error) {
+	defer s.observeDuration("ResetPassword", &err)()
+
+	if err = s.validator.ValidatePassword("new_password", newPassword); err != nil {
+		return err
+	}
+	u, err := s.userStorage.GetUserByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err = s.validator.ValidatePassword("new_password", newPassword, u.Username, u.Email); err != nil {
+		return err
+	}
+	if err = s.checkPasswordReuse(ctx, u, newPassword); err != nil {
+		return err
+	}
+	hashedPass, err := s.hashPassword(ctx, newPassword)
+	if err != nil {
+		return err
+	}
+	if claim != nil {
+		if err = claim(ctx); err != nil {
+			return err
+		}
+	}
+	if err = s.storePassword(ctx, id, hashedPass, u.Password); err != nil {
+		return err
+	}
+	if err = s.sessions.RevokeOtherSessions(ctx, id, ""); err != nil {
+		return fmt.Errorf("revoke sessions: %w", err)
+	}
+	return nil
+}
+
+func (s *userService) GetUserByEmail(ctx context.Context, email string) (user *domain.UserResponse, err error) {
+	defer s.observeDuration("GetUserByEmail", &err)()
+
+	u, err := s.userStorage.GetUserByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	return toUserResponse(u), nil
+}
+
+func (s *userService) VerifyEmail(ctx context.Context, id int64, email string) (err error) {
+	defer s.observeDuration("VerifyEmail", &err)()
+
+	return s.userStorage.MarkEmailVerified(ctx, id, email, time.Now())
+}
+
+func (s *userService) WithUserTenant(ctx context.Context, id int64) (_ context.Context, err error) {
+	defer s.observeDuration("WithUserTenant", &err)()
+
+	orgID, err := s.userStorage.GetUserOrganizationID(ctx, id)
+	if err != nil {
+		return ctx, err
+	}
+	return domain.ContextWithTenant(ctx, orgID), nil
+}
+
+// setPassword hashes password and stores it.
+func (s *userService) setPassword(ctx context.Context, id int64, password, previousHash string) error {
+	hashedPass, err := s.hashPassword(ctx, password)
+	if err != nil {
+		return err
+	}
+	return s.storePassword(ctx, id, hashedPass, previousHash)
+}
+
+// storePassword is the single path through which stored passwords change. The replaced
+// previousHash, if known, is kept in the password history.
+func (s *userService) storePassword(ctx context.Context, id int64, hashedPass, previousHash string) error {
+	err := s.userStorage.UpdatePassword(ctx, id, hashedPass)
+	if err != nil {
+		return err
+	}
+	if s.historyConfig.Size <= 1 || previousHash == "" {
+		return nil
+	}
+
+	now := time.Now()
+	if err = s.history.AddPasswordHistory(ctx, id, previousHash, now); err != nil {
+		return fmt.Errorf("add password history: %w", err)
+	}
+	if err = s.history.PrunePasswordHistory(ctx, id, s.historyConfig.Size-1, s.historySince(now)); err != nil {
+		return fmt.Errorf("prune password history: %w", err)
+	}
+	return nil
+}
+
+// checkPasswordReuse rejects password if it matches the current password of u or one of the
+// previous ones still in the history. Every comparison costs a full hash.
+func (s *userService) checkPasswordReuse(ctx context.Context, u *domain.User, password string) error {
+	if s.historyConfig.Size <= 0 {
+		return nil
+	}
+	hashes := []string{u.Password}
+	if s.historyConfig.Size > 1 {
+		previous, err := s.history.ListPasswordHistory(ctx, u.ID, s.historySince(time.Now()), s.historyConfig.Size-1)
+		if err != nil {
+			return fmt.Errorf("list password history: %w", err)
+		}
+		hashes = append(hashes, previous...)
+	}
+
+	for _, hash := range hashes {
+		match, err := s.checkPassword(ctx, hash, password)
+		if err != nil {
+			return err
+		}
+		if match {
+			return domain.NewValidationError(domain.FieldViolation{
+				Field:       "new_password",
+				Description: fmt.Sprintf("must differ from the last %d passwords", s.historyConfig.Size),
+			})
+		}
+	}
+	return nil
+}
+
+// historySince returns the creation time before which history entries are expired.
+func (s *userService) historySince(now time.Time) time.Time {
+	if s.historyConfig.Retention <= 0 {
+		return time.Time{}
+	}
+	return now.Add(-s.historyConfig.Retention)
 }
 
 func (s *userService) DeleteUser(ctx context.Context, id int64) (err error) {
@@ -79,14 +339,243 @@ func (s *userService) DeleteUser(ctx context.Context, id int64) (err error) {
 	return s.userStorage.DeleteUser(ctx, id)
 }
 
-func (s *userService) observeDuration(method string, err *error) func() {
-	start := time.Now()
-	return func() {
-		result := "success"
-		if *err != nil {
-			result = "error"
-			s.logger.Error(fmt.Sprintf("%s failed", method), "err", *err)
+func (s *userService) Authenticate(ctx context.Context, login, password string) (user *domain.UserResponse, err error) {
+	defer s.observeDuration("Authenticate", &err)()
+
+	u, err := s.userStorage.GetUserByLogin(ctx, login)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, s.rejectUnknownLogin(ctx, password)
 		}
-		requestDuration.WithLabelValues(method, result).Observe(time.Since(start).Seconds())
+		return nil, err
+	}
+	match, err := s.checkPassword(ctx, u.Password, password)
+	if err != nil {
+		return nil, err
+	}
+	if !match {
+		return nil, &domain.InvalidPasswordError{UserID: u.ID}
+	}
+	if s.passwordHasher.NeedsRehash(u.Password) {
+		go s.rehashPassword(context.WithoutCancel(ctx), u.ID, u.Password, password)
+	}
+	return toUserResponse(u), nil
+}
+
+// rejectUnknownLogin checks password against a dummy hash before failing, so that a login
+// for an unknown account takes as long as one with a wrong password and does not reveal
+// which accounts exist.
+func (s *userService) rejectUnknownLogin(ctx context.Context, password string) error {
+	hash, err := s.dummyPasswordHash(ctx)
+	if err != nil {
+		return err
 	}
+	if _, err = s.checkPassword(ctx, hash, password); err != nil {
+		return err
+	}
+	return domain.ErrInvalidCredentials
+}
+
+// dummyPasswordHash returns a hash made like those of new passwords, through the same pool
+// and pepper, computed on first use and kept afterwards.
+func (s *userService) dummyPasswordHash(ctx context.Context) (string, error) {
+	s.dummyHashMu.Lock()
+	defer s.dummyHashMu.Unlock()
+	if s.dummyHash == "" {
+		hash, err := s.hashPassword(ctx, "dummy password of unknown accounts")
+		if err != nil {
+			return "", err
+		}
+		s.dummyHash = hash
+	}
+	return s.dummyHash, nil
+}
+
+// rehashPassword upgrades a hash made with an old algorithm, old parameters or a rotated
+// pepper key while the plaintext is at hand. It runs after the login has been answered;
+// failures only delay the upgrade to a later login. The new hash replaces oldHash only, so
+// a password changed or reset in the meantime is never overwritten.
+func (s *userService) rehashPassword(ctx context.Context, id int64, oldHash, password string) {
+	hashedPass, err := s.hashPassword(ctx, password)
+	if err == nil {
+		err = s.userStorage.ReplacePasswordHash(ctx, id, oldHash, hashedPass)
+	}
+	if errors.Is(err, domain.ErrUserNotFound) {
+		s.logger.Info("password rehash skipped, the password changed meanwhile", "user_id", id)
+		return
+	}
+	if err != nil {
+		s.logger.Warn("password rehash failed", "user_id", id, "error", err)
+		return
+	}
+	s.logger.Info("password rehashed with the current parameters", "user_id", id)
+}
+
+func (s *userService) hashPassword(ctx context.Context, password string) (string, error) {
+	hash, err := s.passwordHasher.Hash(ctx, password)
+	if err != nil {
+		return "", hashingError(ctx, err)
+	}
+	return hash, nil
+}
+
+// checkPassword reports whether password matches hash. It fails only when the check could not run.
+func (s *userService) checkPassword(ctx context.Context, hash, password string) (bool, error) {
+	err := s.passwordChecker.CompareHashAndPassword(ctx, hash, password)
+	if errors.Is(err, hashx.ErrBusy) || ctx.Err() != nil {
+		return false, hashingError(ctx, err)
+	}
+	return err == nil, nil
+}
+
+// hashingError tells clients to back off when the hashing queue is full.
+func hashingError(ctx context.Context, err error) error {
+	switch {
+	case errors.Is(err, hashx.ErrBusy):
+		return domain.ErrServiceBusy
+	case errors.Is(err, hashx.ErrPasswordTooLong):
+		return validationError(violation("password", fmt.Sprintf("must be at most %d bytes long", hashx.BcryptMaxPasswordBytes)))
+	case ctx.Err() != nil:
+		return ctx.Err()
+	default:
+		return fmt.Errorf("hash error: %w", err)
+	}
+}
+
+func (s *userService) ListUsers(ctx context.Context, params domain.ListUsersParams) (page *domain.UserPage, err error) {
+	defer s.observeDuration("ListUsers", &err)()
+
+	query, err := s.userQuery(params)
+	if err != nil {
+		return nil, err
+	}
+	pageSize := query.Limit
+	// One extra row tells whether another page follows.
+	query.Limit++
+
+	users, err := s.userStorage.ListUsers(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	page = &domain.UserPage{Users: make([]*domain.UserResponse, 0, min(len(users), pageSize))}
+	if len(users) > pageSize {
+		users = users[:pageSize]
+		page.NextPageToken, err = s.pageTokens.Encode(userPageToken{
+			Query: queryFingerprint(query),
+			After: userCursor(users[len(users)-1], query.SortBy),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, u := range users {
+		page.Users = append(page.Users, toUserResponse(u))
+	}
+	return page, nil
+}
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// userPageToken is the signed content of a next-page token. Query binds the
+// token to the filter and sort it was issued for.
+type userPageToken struct {
+	Query string            `json:"q"`
+	After domain.UserCursor `json:"after"`
+}
+
+// userQuery validates params and resolves defaults and the page token.
+func (s *userService) userQuery(params domain.ListUsersParams) (domain.UserQuery, error) {
+	query := domain.UserQuery{Filter: params.Filter, SortBy: params.SortBy, Limit: params.PageSize}
+
+	var violations []domain.FieldViolation
+	switch {
+	case query.Limit < 0:
+		violations = append(violations, domain.FieldViolation{Field: "page_size", Description: "must not be negative"})
+	case query.Limit == 0:
+		query.Limit = defaultPageSize
+	case query.Limit > maxPageSize:
+		query.Limit = maxPageSize
+	}
+	switch query.SortBy {
+	case "":
+		query.SortBy = domain.UserSortByID
+	case domain.UserSortByID, domain.UserSortByCreatedAt, domain.UserSortByUsername, domain.UserSortByEmail:
+	default:
+		violations = append(violations, domain.FieldViolation{Field: "sort_by", Description: "must be one of id, created_at, username, email"})
+	}
+	switch params.SortOrder {
+	case "", domain.SortOrderAsc:
+	case domain.SortOrderDesc:
+		query.Desc = true
+	default:
+		violations = append(violations, domain.FieldViolation{Field: "sort_order", Description: "must be asc or desc"})
+	}
+	f := params.Filter
+	if !f.CreatedAfter.IsZero() && !f.CreatedBefore.IsZero() && !f.CreatedAfter.Before(f.CreatedBefore) {
+		violations = append(violations, domain.FieldViolation{Field: "created_before", Description: "must be later than created_after"})
+	}
+	if len(violations) > 0 {
+		return query, domain.NewValidationError(violations...)
+	}
+
+	if params.PageToken != "" {
+		var token userPageToken
+		if err := s.pageTokens.Decode(params.PageToken, &token); err != nil {
+			return query, domain.ErrInvalidPageToken
+		}
+		if token.Query != queryFingerprint(query) {
+			return query, domain.ErrInvalidPageToken
+		}
+		query.After = &token.After
+	}
+	return query, nil
+}
+
+// queryFingerprint identifies the filter and sort of a query, ignoring the page size.
+func queryFingerprint(query domain.UserQuery) string {
+	f := query.Filter
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%d\x00%d\x00%s\x00%t",
+		f.EmailPrefix, f.UsernameContains, unixNano(f.CreatedAfter), unixNano(f.CreatedBefore), query.SortBy, query.Desc)))
+	return base64.RawURLEncoding.EncodeToString(sum[:12])
+}
+
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+// userCursor keeps only the columns the listing is ordered by.
+func userCursor(u *domain.User, sortBy string) domain.UserCursor {
+	cursor := domain.UserCursor{ID: u.ID}
+	switch sortBy {
+	case domain.UserSortByCreatedAt:
+		cursor.CreatedAt = u.CreatedAt
+	case domain.UserSortByUsername:
+		cursor.Username = u.Username
+	case domain.UserSortByEmail:
+		cursor.Email = u.Email
+	}
+	return cursor
+}
+
+func toUserResponse(u *domain.User) *domain.UserResponse {
+	return &domain.UserResponse{
+		ID:               u.ID,
+		Username:         u.Username,
+		Email:            u.Email,
+		EmailVerifiedAt:  u.EmailVerifiedAt,
+		CreatedAt:        u.CreatedAt,
+		OrganizationID:   u.OrganizationID,
+		OrganizationRole: u.OrganizationRole,
+	}
+}
+
+func (s *userService) observeDuration(method string, err *error) func() {
+	return observeDuration(s.logger, method, err)
 }
@@ -4,10 +4,15 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
+	"github.com/golang/mock/gomock"
 	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/kerim-dauren/user-service/pkg/hashx"
+	"github.com/kerim-dauren/user-service/pkg/tokenx"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"log/slog"
 )
 
@@ -28,10 +33,42 @@ func (m *mockUserStorage) GetUserByID(ctx context.Context, id int64) (*domain.Us
 	return args.Get(0).(*domain.User), args.Error(1)
 }
 
+func (m *mockUserStorage) GetUserByLogin(ctx context.Context, login string) (*domain.User, error) {
+	args := m.Called(ctx, login)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+
+func (m *mockUserStorage) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+
 func (m *mockUserStorage) UpdateUser(ctx context.Context, user *domain.User) error {
 	return m.Called(ctx, user).Error(0)
 }
 
+func (m *mockUserStorage) PatchUser(ctx context.Context, id int64, patch *domain.UserPatch) error {
+	return m.Called(ctx, id, patch).Error(0)
+}
+
+func (m *mockUserStorage) UpdatePassword(ctx context.Context, id int64, passwordHash string) error {
+	return m.Called(ctx, id, passwordHash).Error(0)
+}
+
+func (m *mockUserStorage) ReplacePasswordHash(ctx context.Context, id int64, oldHash, newHash string) error {
+	return m.Called(ctx, id, oldHash, newHash).Error(0)
+}
+
+func (m *mockUserStorage) MarkEmailVerified(ctx context.Context, id int64, email string, verifiedAt time.Time) error {
+	return m.Called(ctx, id, email, verifiedAt).Error(0)
+}
+
 func (m *mockUserStorage) DeleteUser(ctx context.Context, id int64) error {
 	return m.Called(ctx, id).Error(0)
 }
@@ -40,19 +77,51 @@ type mockHasher struct {
 	mock.Mock
 }
 
-func (m *mockHasher) Hash(password string) (string, error) {
+func (m *mockHasher) Hash(_ context.Context, password string) (string, error) {
 	args := m.Called(password)
 	return args.String(0), args.Error(1)
 }
 
+func (m *mockHasher) NeedsRehash(hashedPassword string) bool {
+	return m.Called(hashedPassword).Bool(0)
+}
+
 func (m *mockHasher) Verify(hashedPassword, password string) (bool, error) {
 	args := m.Called(hashedPassword, password)
 	return args.Bool(0), args.Error(1)
 }
 
+type mockChecker struct {
+	mock.Mock
+}
+
+func (m *mockChecker) CompareHashAndPassword(_ context.Context, hashedPassword, password string) error {
+	return m.Called(hashedPassword, password).Error(0)
+}
+
+func (m *mockUserStorage) ListUsers(ctx context.Context, query domain.UserQuery) ([]*domain.User, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.User), args.Error(1)
+}
+
+func (m *mockUserStorage) GetUserOrganizationID(ctx context.Context, id int64) (int64, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func testPageTokens(t *testing.T) *tokenx.PageTokenCodec {
+	t.Helper()
+	codec, err := tokenx.NewPageTokenCodec("")
+	require.NoError(t, err)
+	return codec
+}
+
 func TestNewUserService(t *testing.T) {
 	logger := slog.Default()
-	service := NewUserService(logger, new(mockUserStorage), new(mockHasher))
+	service := NewUserService(logger, new(mockUserStorage), new(mockHasher), new(mockChecker), testValidator(t), testPageTokens(t), nil, nil, PasswordHistoryConfig{})
 	assert.NotNil(t, service)
 }
 
@@ -60,8 +129,9 @@ func TestUserService_CreateUser(t *testing.T) {
 	logger := slog.Default()
 	mockStorage := new(mockUserStorage)
 	mockHasher := new(mockHasher)
-	service := NewUserService(logger, mockStorage, mockHasher)
-	ctx := context.Background()
+	service := NewUserService(logger, mockStorage, mockHasher, new(mockChecker), testValidator(t), testPageTokens(t), nil, nil, PasswordHistoryConfig{})
+	// A sign-up joins the default organization whatever tenant the request names.
+	ctx := domain.ContextWithTenant(context.Background(), 7)
 	user := &domain.User{
 		Username: "testuser",
 		Email:    "test@example.com",
@@ -70,9 +140,11 @@ func TestUserService_CreateUser(t *testing.T) {
 
 	mockHasher.On("Hash", "password123").Return("hashed_password", nil)
 	mockStorage.On("CreateUser", ctx, &domain.User{
-		Username: "testuser",
-		Email:    "test@example.com",
-		Password: "hashed_password",
+		Username:         "testuser",
+		Email:            "test@example.com",
+		Password:         "hashed_password",
+		OrganizationID:   domain.DefaultOrganizationID,
+		OrganizationRole: domain.OrganizationRoleMember,
 	}).Return(int64(1), nil)
 
 	id, err := service.CreateUser(ctx, user)
@@ -86,7 +158,7 @@ func TestUserService_CreateUser_HashError(t *testing.T) {
 	logger := slog.Default()
 	mockStorage := new(mockUserStorage)
 	mockHasher := new(mockHasher)
-	service := NewUserService(logger, mockStorage, mockHasher)
+	service := NewUserService(logger, mockStorage, mockHasher, new(mockChecker), testValidator(t), testPageTokens(t), nil, nil, PasswordHistoryConfig{})
 	ctx := context.Background()
 	user := &domain.User{
 		Username: "testuser",
@@ -104,7 +176,7 @@ func TestUserService_GetUserByID(t *testing.T) {
 	logger := slog.Default()
 	mockStorage := new(mockUserStorage)
 	mockHasher := new(mockHasher)
-	service := NewUserService(logger, mockStorage, mockHasher)
+	service := NewUserService(logger, mockStorage, mockHasher, new(mockChecker), testValidator(t), testPageTokens(t), nil, nil, PasswordHistoryConfig{})
 	ctx := context.Background()
 
 	mockStorage.On("GetUserByID", ctx, int64(1)).Return(&domain.User{
@@ -126,7 +198,7 @@ func TestUserService_GetUserByID_Error(t *testing.T) {
 	logger := slog.Default()
 	mockStorage := new(mockUserStorage)
 	mockHasher := new(mockHasher)
-	service := NewUserService(logger, mockStorage, mockHasher)
+	service := NewUserService(logger, mockStorage, mockHasher, new(mockChecker), testValidator(t), testPageTokens(t), nil, nil, PasswordHistoryConfig{})
 	ctx := context.Background()
 
 	mockStorage.On("GetUserByID", ctx, int64(999)).Return(nil, errors.New("user not found"))
@@ -140,7 +212,7 @@ func TestUserService_UpdateUser(t *testing.T) {
 	logger := slog.Default()
 	mockStorage := new(mockUserStorage)
 	mockHasher := new(mockHasher)
-	service := NewUserService(logger, mockStorage, mockHasher)
+	service := NewUserService(logger, mockStorage, mockHasher, new(mockChecker), testValidator(t), testPageTokens(t), nil, nil, PasswordHistoryConfig{})
 	ctx := context.Background()
 	user := &domain.User{
 		ID:       1,
@@ -154,8 +226,8 @@ func TestUserService_UpdateUser(t *testing.T) {
 		ID:       1,
 		Username: "updateduser",
 		Email:    "updated@example.com",
-		Password: "new_hashed_password",
 	}).Return(nil)
+	mockStorage.On("UpdatePassword", ctx, int64(1), "new_hashed_password").Return(nil)
 
 	err := service.UpdateUser(ctx, user)
 	assert.NoError(t, err)
@@ -163,11 +235,82 @@ func TestUserService_UpdateUser(t *testing.T) {
 	mockStorage.AssertExpectations(t)
 }
 
+func TestUserService_UpdateUser_KeepsPassword(t *testing.T) {
+	mockStorage := new(mockUserStorage)
+	mockHasher := new(mockHasher)
+	service := NewUserService(slog.Default(), mockStorage, mockHasher, new(mockChecker), testValidator(t), testPageTokens(t), nil, nil, PasswordHistoryConfig{})
+	ctx := context.Background()
+	user := &domain.User{ID: 1, Username: "updateduser", Email: "updated@example.com"}
+
+	mockStorage.On("UpdateUser", ctx, user).Return(nil)
+
+	err := service.UpdateUser(ctx, user)
+	assert.NoError(t, err)
+	mockHasher.AssertNotCalled(t, "Hash", mock.Anything)
+	mockStorage.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUserService_UpdateUser_HashError(t *testing.T) {
+	mockStorage := new(mockUserStorage)
+	mockHasher := new(mockHasher)
+	service := NewUserService(slog.Default(), mockStorage, mockHasher, new(mockChecker), testValidator(t), testPageTokens(t), nil, nil, PasswordHistoryConfig{})
+	ctx := context.Background()
+
+	mockStorage.On("UpdateUser", ctx, mock.Anything).Return(nil)
+	mockHasher.On("Hash", "newpassword").Return("", errors.New("hash error"))
+
+	err := service.UpdateUser(ctx, &domain.User{ID: 1, Username: "updateduser", Email: "updated@example.com", Password: "newpassword"})
+	assert.Error(t, err)
+	mockStorage.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUserService_PatchUser(t *testing.T) {
+	mockStorage := new(mockUserStorage)
+	service := NewUserService(slog.Default(), mockStorage, new(mockHasher), new(mockChecker), testValidator(t), testPageTokens(t), nil, nil, PasswordHistoryConfig{})
+	ctx := context.Background()
+	email := "new@example.com"
+	patch := &domain.UserPatch{Email: &email}
+
+	mockStorage.On("PatchUser", ctx, int64(1), patch).Return(nil)
+	mockStorage.On("GetUserByID", ctx, int64(1)).Return(&domain.User{ID: 1, Username: "testuser", Email: email}, nil)
+
+	user, err := service.PatchUser(ctx, 1, patch)
+	require.NoError(t, err)
+	assert.Equal(t, email, user.Email)
+	assert.Equal(t, "testuser", user.Username)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestUserService_PatchUser_Empty(t *testing.T) {
+	mockStorage := new(mockUserStorage)
+	service := NewUserService(slog.Default(), mockStorage, new(mockHasher), new(mockChecker), testValidator(t), testPageTokens(t), nil, nil, PasswordHistoryConfig{})
+	ctx := context.Background()
+
+	mockStorage.On("GetUserByID", ctx, int64(1)).Return(&domain.User{ID: 1, Username: "testuser"}, nil)
+
+	_, err := service.PatchUser(ctx, 1, &domain.UserPatch{})
+	require.NoError(t, err)
+	mockStorage.AssertNotCalled(t, "PatchUser", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUserService_PatchUser_ValidationError(t *testing.T) {
+	mockStorage := new(mockUserStorage)
+	service := NewUserService(slog.Default(), mockStorage, new(mockHasher), new(mockChecker), testValidator(t), testPageTokens(t), nil, nil, PasswordHistoryConfig{})
+	bad := "not-an-email"
+
+	_, err := service.PatchUser(context.Background(), 1, &domain.UserPatch{Email: &bad})
+
+	var validationErr *domain.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "email", validationErr.Violations[0].Field)
+	mockStorage.AssertNotCalled(t, "PatchUser", mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestUserService_DeleteUser(t *testing.T) {
 	logger := slog.Default()
 	mockStorage := new(mockUserStorage)
 	mockHasher := new(mockHasher)
-	service := NewUserService(logger, mockStorage, mockHasher)
+	service := NewUserService(logger, mockStorage, mockHasher, new(mockChecker), testValidator(t), testPageTokens(t), nil, nil, PasswordHistoryConfig{})
 	ctx := context.Background()
 
 	mockStorage.On("DeleteUser", ctx, int64(1)).Return(nil)
@@ -180,7 +323,7 @@ func TestUserService_DeleteUser_Error(t *testing.T) {
 	logger := slog.Default()
 	mockStorage := new(mockUserStorage)
 	mockHasher := new(mockHasher)
-	service := NewUserService(logger, mockStorage, mockHasher)
+	service := NewUserService(logger, mockStorage, mockHasher, new(mockChecker), testValidator(t), testPageTokens(t), nil, nil, PasswordHistoryConfig{})
 	ctx := context.Background()
 
 	mockStorage.On("DeleteUser", ctx, int64(999)).Return(errors.New("user not found"))
@@ -188,3 +331,400 @@ func TestUserService_DeleteUser_Error(t *testing.T) {
 	assert.Error(t, err)
 	mockStorage.AssertExpectations(t)
 }
+
+func TestUserService_Authenticate(t *testing.T) {
+	logger := slog.Default()
+	mockStorage := new(mockUserStorage)
+	mockChecker := new(mockChecker)
+	mockHasher := new(mockHasher)
+	service := NewUserService(logger, mockStorage, mockHasher, mockChecker, testValidator(t), testPageTokens(t), nil, nil, PasswordHistoryConfig{})
+	ctx := context.Background()
+
+	mockStorage.On("GetUserByLogin", ctx, "test@example.com").Return(&domain.User{
+		ID:       1,
+		Username: "testuser",
+		Email:    "test@example.com",
+		Password: "hashed_password",
+	}, nil)
+	mockChecker.On("CompareHashAndPassword", "hashed_password", "password123").Return(nil)
+	mockHasher.On("NeedsRehash", "hashed_password").Return(false)
+
+	userRes, err := service.Authenticate(ctx, "test@example.com", "password123")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), userRes.ID)
+	assert.Equal(t, "testuser", userRes.Username)
+	mockStorage.AssertExpectations(t)
+	mockChecker.AssertExpectations(t)
+}
+
+func TestUserService_Authenticate_Rehash(t *testing.T) {
+	mockStorage := new(mockUserStorage)
+	mockHasher := new(mockHasher)
+	mockChecker := new(mockChecker)
+	service := NewUserService(slog.Default(), mockStorage, mockHasher, mockChecker, testValidator(t), testPageTokens(t), nil, nil, PasswordHistoryConfig{})
+	ctx := context.Background()
+
+	mockStorage.On("GetUserByLogin", ctx, "testuser").Return(&domain.User{ID: 1, Username: "testuser", Password: "legacy_hash"}, nil)
+	mockChecker.On("CompareHashAndPassword", "legacy_hash", "password123").Return(nil)
+	mockHasher.On("NeedsRehash", "legacy_hash").Return(true)
+	mockHasher.On("Hash", "password123").Return("current_hash", nil)
+	rehashed := make(chan struct{})
+	mockStorage.On("ReplacePasswordHash", mock.Anything, int64(1), "legacy_hash", "current_hash").Return(errors.New("connection reset")).
+		Run(func(mock.Arguments) { close(rehashed) })
+
+	userRes, err := service.Authenticate(ctx, "testuser", "password123")
+	require.NoError(t, err, "a failed rehash does not fail the login")
+	assert.Equal(t, int64(1), userRes.ID)
+	select {
+	case <-rehashed:
+	case <-time.After(time.Second):
+		t.Fatal("password was not rehashed")
+	}
+	mockStorage.AssertExpectations(t)
+	mockHasher.AssertExpectations(t)
+}
+
+func TestUserService_Authenticate_HashingBusy(t *testing.T) {
+	mockStorage := new(mockUserStorage)
+	mockChecker := new(mockChecker)
+	service := NewUserService(slog.Default(), mockStorage, new(mockHasher), mockChecker, testValidator(t), testPageTokens(t), nil, nil, PasswordHistoryConfig{})
+	ctx := context.Background()
+
+	mockStorage.On("GetUserByLogin", ctx, "testuser").Return(&domain.User{ID: 1, Username: "testuser", Password: "hashed_password"}, nil)
+	mockChecker.On("CompareHashAndPassword", "hashed_password", "password123").Return(hashx.ErrBusy)
+
+	_, err := service.Authenticate(ctx, "testuser", "password123")
+	assert.ErrorIs(t, err, domain.ErrServiceBusy)
+	assert.NotErrorIs(t, err, domain.ErrInvalidCredentials, "a shed check is not a failed login")
+}
+
+func TestUserService_Authenticate_WrongPassword(t *testing.T) {
+	logger := slog.Default()
+	mockStorage := new(mockUserStorage)
+	mockChecker := new(mockChecker)
+	service := NewUserService(logger, mockStorage, new(mockHasher), mockChecker, testValidator(t), testPageTokens(t), nil, nil, PasswordHistoryConfig{})
+	ctx := context.Background()
+
+	mockStorage.On("GetUserByLogin", ctx, "testuser").Return(&domain.User{
+		ID:       1,
+		Username: "testuser",
+		Password: "hashed_password",
+	}, nil)
+	mockChecker.On("CompareHashAndPassword", "hashed_password", "wrong").Return(errors.New("passwords do not match"))
+
+	userRes, err := service.Authenticate(ctx, "testuser", "wrong")
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	var invalidPassword *domain.InvalidPasswordError
+	if assert.ErrorAs(t, err, &invalidPassword) {
+		assert.Equal(t, int64(1), invalidPassword.UserID)
+	}
+	assert.Nil(t, userRes)
+	mockChecker.AssertExpectations(t)
+}
+
+func TestUserService_Authenticate_UnknownUser(t *testing.T) {
+	logger := slog.Default()
+	mockStorage := new(mockUserStorage)
+	mockHasher := new(mockHasher)
+	mockChecker := new(mockChecker)
+	service := NewUserService(logger, mockStorage, mockHasher, mockChecker, testValidator(t), testPageTokens(t), nil, nil, PasswordHistoryConfig{})
+	ctx := context.Background()
+
+	mockStorage.On("GetUserByLogin", ctx, "nobody").Return(nil, domain.ErrUserNotFound)
+	mockHasher.On("Hash", mock.Anything).Return("dummy_hash", nil).Once()
+	mockChecker.On("CompareHashAndPassword", "dummy_hash", "password123").Return(errors.New("passwords do not match"))
+
+	// Unknown accounts cost a password check like wrong passwords, with a hash made only once.
+	for i := 0; i < 2; i++ {
+		userRes, err := service.Authenticate(ctx, "nobody", "password123")
+		assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+		assert.Nil(t, userRes)
+	}
+	mockHasher.AssertExpectations(t)
+	mockChecker.AssertNumberOfCalls(t, "CompareHashAndPassword", 2)
+}
+
+func TestUserService_Authenticate_UnknownUserHashingBusy(t *testing.T) {
+	mockStorage := new(mockUserStorage)
+	mockHasher := new(mockHasher)
+	service := NewUserService(slog.Default(), mockStorage, mockHasher, new(mockChecker), testValidator(t), testPageTokens(t), nil, nil, PasswordHistoryConfig{})
+	ctx := context.Background()
+
+	mockStorage.On("GetUserByLogin", ctx, "nobody").Return(nil, domain.ErrUserNotFound)
+	mockHasher.On("Hash", mock.Anything).Return("", hashx.ErrBusy)
+
+	_, err := service.Authenticate(ctx, "nobody", "password123")
+	assert.ErrorIs(t, err, domain.ErrServiceBusy, "answered like a known account while the pool is full")
+}
+
+func TestUserService_CreateUser_ValidationError(t *testing.T) {
+	mockStorage := new(mockUserStorage)
+	mockHasher := new(mockHasher)
+	service := NewUserService(slog.Default(), mockStorage, mockHasher, new(mockChecker), testValidator(t), testPageTokens(t), nil, nil, PasswordHistoryConfig{})
+
+	_, err := service.CreateUser(context.Background(), &domain.User{Username: "x", Email: "bad", Password: "password123"})
+
+	var validationErr *domain.ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Len(t, validationErr.Violations, 2)
+	mockHasher.AssertNotCalled(t, "Hash", mock.Anything)
+	mockStorage.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything)
+}
+
+func TestUserService_ListUsers(t *testing.T) {
+	mockStorage := new(mockUserStorage)
+	service := NewUserService(slog.Default(), mockStorage, new(mockHasher), new(mockChecker), testValidator(t), testPageTokens(t), nil, nil, PasswordHistoryConfig{})
+	ctx := context.Background()
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	filter := domain.UserFilter{EmailPrefix: "a"}
+
+	mockStorage.On("ListUsers", ctx, domain.UserQuery{
+		Filter: filter, SortBy: domain.UserSortByCreatedAt, Desc: true, Limit: 3,
+	}).Return([]*domain.User{
+		{ID: 3, Username: "carol", CreatedAt: created.Add(2 * time.Hour)},
+		{ID: 2, Username: "bob", CreatedAt: created.Add(time.Hour)},
+		{ID: 1, Username: "alice", CreatedAt: created},
+	}, nil).Once()
+
+	page, err := service.ListUsers(ctx, domain.ListUsersParams{
+		Filter: filter, SortBy: domain.UserSortByCreatedAt, SortOrder: domain.SortOrderDesc, PageSize: 2,
+	})
+	require.NoError(t, err)
+	assert.Len(t, page.Users, 2)
+	assert.Equal(t, "bob", page.Users[1].Username)
+	require.NotEmpty(t, page.NextPageToken)
+
+	mockStorage.On("ListUsers", ctx, domain.UserQuery{
+		Filter: filter, SortBy: domain.UserSortByCreatedAt, Desc: true, Limit: 3,
+		After: &domain.UserCursor{ID: 2, CreatedAt: created.Add(time.Hour)},
+	}).Return([]*domain.User{{ID: 1, Username: "alice", CreatedAt: created}}, nil).Once()
+
+	page, err = service.ListUsers(ctx, domain.ListUsersParams{
+		Filter: filter, SortBy: domain.UserSortByCreatedAt, SortOrder: domain.SortOrderDesc, PageSize: 2,
+		PageToken: page.NextPageToken,
+	})
+	require.NoError(t, err)
+	assert.Len(t, page.Users, 1)
+	assert.Empty(t, page.NextPageToken)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestUserService_ListUsers_TokenBoundToQuery(t *testing.T) {
+	mockStorage := new(mockUserStorage)
+	service := NewUserService(slog.Default(), mockStorage, new(mockHasher), new(mockChecker), testValidator(t), testPageTokens(t), nil, nil, PasswordHistoryConfig{})
+	ctx := context.Background()
+
+	mockStorage.On("ListUsers", ctx, mock.Anything).Return([]*domain.User{{ID: 1}, {ID: 2}}, nil).Once()
+	page, err := service.ListUsers(ctx, domain.ListUsersParams{PageSize: 1})
+	require.NoError(t, err)
+
+	_, err = service.ListUsers(ctx, domain.ListUsersParams{PageSize: 1, SortOrder: domain.SortOrderDesc, PageToken: page.NextPageToken})
+	assert.ErrorIs(t, err, domain.ErrInvalidPageToken)
+
+	_, err = service.ListUsers(ctx, domain.ListUsersParams{PageToken: page.NextPageToken + "x"})
+	assert.ErrorIs(t, err, domain.ErrInvalidPageToken)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestUserService_ListUsers_InvalidParams(t *testing.T) {
+	mockStorage := new(mockUserStorage)
+	service := NewUserService(slog.Default(), mockStorage, new(mockHasher), new(mockChecker), testValidator(t), testPageTokens(t), nil, nil, PasswordHistoryConfig{})
+	now := time.Now()
+
+	_, err := service.ListUsers(context.Background(), domain.ListUsersParams{
+		PageSize:  -1,
+		SortBy:    "password",
+		SortOrder: "up",
+		Filter:    domain.UserFilter{CreatedAfter: now, CreatedBefore: now.Add(-time.Hour)},
+	})
+
+	var validationErr *domain.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Len(t, validationErr.Violations, 4)
+	mockStorage.AssertNotCalled(t, "ListUsers", mock.Anything, mock.Anything)
+}
+
+func TestUserService_ListUsers_PageSizeCapped(t *testing.T) {
+	mockStorage := new(mockUserStorage)
+	service := NewUserService(slog.Default(), mockStorage, new(mockHasher), new(mockChecker), testValidator(t), testPageTokens(t), nil, nil, PasswordHistoryConfig{})
+	ctx := context.Background()
+
+	mockStorage.On("ListUsers", ctx, domain.UserQuery{SortBy: domain.UserSortByID, Limit: maxPageSize + 1}).Return([]*domain.User{}, nil)
+
+	page, err := service.ListUsers(ctx, domain.ListUsersParams{PageSize: 1000})
+	require.NoError(t, err)
+	assert.Empty(t, page.Users)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestUserService_ChangePassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStorage := new(mockUserStorage)
+	mockHasher := new(mockHasher)
+	mockChecker := new(mockChecker)
+	sessions := domain.NewMockSessionService(ctrl)
+	service := NewUserService(slog.Default(), mockStorage, mockHasher, mockChecker, testValidator(t), testPageTokens(t), sessions, nil, PasswordHistoryConfig{})
+	ctx := domain.ContextWithPrincipal(context.Background(), &domain.Principal{UserID: 1, SessionID: "current"})
+
+	mockStorage.On("GetUserByID", ctx, int64(1)).Return(&domain.User{ID: 1, Password: "old_hash"}, nil)
+	mockChecker.On("CompareHashAndPassword", "old_hash", "oldpassword").Return(nil)
+	mockHasher.On("Hash", "newpassword").Return("new_hash", nil)
+	mockStorage.On("UpdatePassword", ctx, int64(1), "new_hash").Return(nil)
+	sessions.EXPECT().RevokeOtherSessions(ctx, int64(1), "current").Return(nil)
+
+	err := service.ChangePassword(ctx, 1, "oldpassword", "newpassword")
+	assert.NoError(t, err)
+	mockStorage.AssertExpectations(t)
+	mockHasher.AssertExpectations(t)
+}
+
+func TestUserService_ChangePassword_IncorrectPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStorage := new(mockUserStorage)
+	mockHasher := new(mockHasher)
+	mockChecker := new(mockChecker)
+	service := NewUserService(slog.Default(), mockStorage, mockHasher, mockChecker, testValidator(t), testPageTokens(t), domain.NewMockSessionService(ctrl), nil, PasswordHistoryConfig{})
+	ctx := context.Background()
+
+	mockStorage.On("GetUserByID", ctx, int64(1)).Return(&domain.User{ID: 1, Password: "old_hash"}, nil)
+	mockChecker.On("CompareHashAndPassword", "old_hash", "wrongpassword").Return(errors.New("mismatch"))
+
+	err := service.ChangePassword(ctx, 1, "wrongpassword", "newpassword")
+	assert.ErrorIs(t, err, domain.ErrIncorrectPassword)
+	mockHasher.AssertNotCalled(t, "Hash", mock.Anything)
+	mockStorage.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUserService_ChangePassword_InvalidNewPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStorage := new(mockUserStorage)
+	service := NewUserService(slog.Default(), mockStorage, new(mockHasher), new(mockChecker), testValidator(t), testPageTokens(t), domain.NewMockSessionService(ctrl), nil, PasswordHistoryConfig{})
+
+	for _, newPassword := range []string{"short", "oldpassword"} {
+		err := service.ChangePassword(context.Background(), 1, "oldpassword", newPassword)
+
+		var validationErr *domain.ValidationError
+		require.ErrorAs(t, err, &validationErr, newPassword)
+		assert.Equal(t, "new_password", validationErr.Violations[0].Field)
+	}
+	mockStorage.AssertNotCalled(t, "GetUserByID", mock.Anything, mock.Anything)
+}
+
+func TestUserService_ChangePassword_ContainsUsername(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStorage := new(mockUserStorage)
+	mockHasher := new(mockHasher)
+	mockChecker := new(mockChecker)
+	service := NewUserService(slog.Default(), mockStorage, mockHasher, mockChecker, testValidator(t), testPageTokens(t), domain.NewMockSessionService(ctrl), nil, PasswordHistoryConfig{})
+	ctx := context.Background()
+
+	mockStorage.On("GetUserByID", ctx, int64(1)).Return(&domain.User{ID: 1, Username: "alice", Email: "alice@example.com", Password: "old_hash"}, nil)
+	mockChecker.On("CompareHashAndPassword", "old_hash", "oldpassword").Return(nil)
+
+	err := service.ChangePassword(ctx, 1, "oldpassword", "Alice-2024")
+
+	var validationErr *domain.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "must not contain the username or email address", validationErr.Violations[0].Description)
+	mockHasher.AssertNotCalled(t, "Hash", mock.Anything)
+}
+
+func TestUserService_ResetPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStorage := new(mockUserStorage)
+	mockHasher := new(mockHasher)
+	sessions := domain.NewMockSessionService(ctrl)
+	service := NewUserService(slog.Default(), mockStorage, mockHasher, new(mockChecker), testValidator(t), testPageTokens(t), sessions, nil, PasswordHistoryConfig{})
+	ctx := context.Background()
+
+	mockStorage.On("GetUserByID", ctx, int64(1)).Return(&domain.User{ID: 1, Username: "alice", Email: "alice@example.com"}, nil)
+	mockHasher.On("Hash", "newpassword").Return("new_hash", nil)
+	mockStorage.On("UpdatePassword", ctx, int64(1), "new_hash").Return(nil)
+	sessions.EXPECT().RevokeOtherSessions(ctx, int64(1), "").Return(nil)
+
+	err := service.ResetPassword(ctx, 1, "newpassword", nil)
+	assert.NoError(t, err)
+	mockStorage.AssertExpectations(t)
+	mockHasher.AssertExpectations(t)
+}
+
+func TestUserService_ResetPassword_ClaimFails(t *testing.T) {
+	mockStorage := new(mockUserStorage)
+	mockHasher := new(mockHasher)
+	service := NewUserService(slog.Default(), mockStorage, mockHasher, new(mockChecker), testValidator(t), testPageTokens(t), nil, nil, PasswordHistoryConfig{})
+	ctx := context.Background()
+
+	mockStorage.On("GetUserByID", ctx, int64(1)).Return(&domain.User{ID: 1, Username: "alice", Email: "alice@example.com"}, nil)
+	mockHasher.On("Hash", "newpassword").Return("new_hash", nil)
+
+	err := service.ResetPassword(ctx, 1, "newpassword", func(context.Context) error {
+		return domain.ErrInvalidPasswordResetToken
+	})
+	assert.ErrorIs(t, err, domain.ErrInvalidPasswordResetToken)
+	mockStorage.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUserService_ChangePassword_ReusedPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStorage := new(mockUserStorage)
+	mockHasher := new(mockHasher)
+	mockChecker := new(mockChecker)
+	history := domain.NewMockPasswordHistoryStorage(ctrl)
+	service := NewUserService(slog.Default(), mockStorage, mockHasher, mockChecker, testValidator(t), testPageTokens(t), nil,
+		history, PasswordHistoryConfig{Size: 3})
+	ctx := context.Background()
+
+	mockStorage.On("GetUserByID", ctx, int64(1)).Return(&domain.User{ID: 1, Username: "alice", Email: "alice@example.com", Password: "old_hash"}, nil)
+	mockChecker.On("CompareHashAndPassword", "old_hash", "oldpassword").Return(nil)
+	mockChecker.On("CompareHashAndPassword", "old_hash", "newpassword").Return(errors.New("mismatch"))
+	mockChecker.On("CompareHashAndPassword", "older_hash", "newpassword").Return(nil)
+	history.EXPECT().ListPasswordHistory(ctx, int64(1), time.Time{}, 2).Return([]string{"older_hash", "oldest_hash"}, nil)
+
+	err := service.ChangePassword(ctx, 1, "oldpassword", "newpassword")
+
+	var validationErr *domain.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "must differ from the last 3 passwords", validationErr.Violations[0].Description)
+	mockHasher.AssertNotCalled(t, "Hash", mock.Anything)
+}
+
+func TestUserService_ResetPassword_RecordsHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStorage := new(mockUserStorage)
+	mockHasher := new(mockHasher)
+	mockChecker := new(mockChecker)
+	sessions := domain.NewMockSessionService(ctrl)
+	history := domain.NewMockPasswordHistoryStorage(ctrl)
+	service := NewUserService(slog.Default(), mockStorage, mockHasher, mockChecker, testValidator(t), testPageTokens(t), sessions,
+		history, PasswordHistoryConfig{Size: 3, Retention: 24 * time.Hour})
+	ctx := context.Background()
+
+	mockStorage.On("GetUserByID", ctx, int64(1)).Return(&domain.User{ID: 1, Username: "alice", Email: "alice@example.com", Password: "old_hash"}, nil)
+	mockChecker.On("CompareHashAndPassword", "old_hash", "newpassword").Return(errors.New("mismatch"))
+	history.EXPECT().ListPasswordHistory(ctx, int64(1), gomock.Any(), 2).
+		DoAndReturn(func(_ context.Context, _ int64, since time.Time, _ int) ([]string, error) {
+			assert.WithinDuration(t, time.Now().Add(-24*time.Hour), since, time.Minute, "expired entries are ignored")
+			return nil, nil
+		})
+	mockHasher.On("Hash", "newpassword").Return("new_hash", nil)
+	mockStorage.On("UpdatePassword", ctx, int64(1), "new_hash").Return(nil)
+	history.EXPECT().AddPasswordHistory(ctx, int64(1), "old_hash", gomock.Any()).Return(nil)
+	history.EXPECT().PrunePasswordHistory(ctx, int64(1), 2, gomock.Any()).Return(nil)
+	sessions.EXPECT().RevokeOtherSessions(ctx, int64(1), "").Return(nil)
+
+	require.NoError(t, service.ResetPassword(ctx, 1, "newpassword", nil))
+	mockStorage.AssertExpectations(t)
+	mockHasher.AssertExpectations(t)
+}
+
+func TestUserService_VerifyEmail(t *testing.T) {
+	mockStorage := new(mockUserStorage)
+	service := NewUserService(slog.Default(), mockStorage, new(mockHasher), new(mockChecker), testValidator(t), testPageTokens(t), nil, nil, PasswordHistoryConfig{})
+	ctx := context.Background()
+
+	mockStorage.On("MarkEmailVerified", ctx, int64(1), "old@example.com", mock.AnythingOfType("time.Time")).Return(domain.ErrUserNotFound)
+
+	err := service.VerifyEmail(ctx, 1, "old@example.com")
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+	mockStorage.AssertExpectations(t)
+}
@@ -0,0 +1,241 @@
+package services
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kerim-dauren/user-service/internal/domain"
+)
+
+// maxColumnLength is the size of the VARCHAR columns that store usernames and emails.
+const maxColumnLength = 100
+
+// UserRules configures the checks applied to user input before it is hashed or stored.
+type UserRules struct {
+	UsernameMinLength int
+	UsernameMaxLength int
+	UsernamePattern   string // Regular expression the whole username must match
+	EmailMaxLength    int
+	PasswordMinLength int
+	PasswordMaxLength int // Bounds the cost of hashing attacker-controlled input
+	PasswordMaxBytes  int // Longest password the hash algorithm accepts, in bytes; 0 when unbounded
+
+	PasswordRequireLower   bool
+	PasswordRequireUpper   bool
+	PasswordRequireDigit   bool
+	PasswordRequireSymbol  bool // Any character that is not a letter or a digit
+	PasswordRejectIdentity bool // Reject passwords containing the username or the email address
+}
+
+// BreachedPasswords reports whether a password is known from a data breach.
+type BreachedPasswords interface {
+	Contains(password string) bool
+}
+
+// DefaultUserRules returns the rules used when nothing is configured.
+func DefaultUserRules() UserRules {
+	return UserRules{
+		UsernameMinLength: 3,
+		UsernameMaxLength: 32,
+		UsernamePattern:   `^[a-zA-Z0-9._-]+$`,
+		EmailMaxLength:    maxColumnLength,
+		PasswordMinLength: 8,
+		PasswordMaxLength: 72,
+
+		PasswordRejectIdentity: true,
+	}
+}
+
+// UserValidator checks users against UserRules and reports every violated field at once.
+type UserValidator struct {
+	rules           UserRules
+	usernamePattern *regexp.Regexp
+	breached        BreachedPasswords
+}
+
+// NewUserValidator validates the rules themselves and compiles the username pattern.
+// Passwords are checked against breached unless it is nil.
+func NewUserValidator(rules UserRules, breached BreachedPasswords) (*UserValidator, error) {
+	if rules.UsernameMinLength < 1 || rules.UsernameMinLength > rules.UsernameMaxLength {
+		return nil, fmt.Errorf("invalid username length bounds: %d..%d", rules.UsernameMinLength, rules.UsernameMaxLength)
+	}
+	if rules.UsernameMaxLength > maxColumnLength || rules.EmailMaxLength > maxColumnLength {
+		return nil, fmt.Errorf("username and email length cannot exceed %d", maxColumnLength)
+	}
+	if rules.EmailMaxLength < 1 {
+		return nil, fmt.Errorf("invalid email max length: %d", rules.EmailMaxLength)
+	}
+	if rules.PasswordMinLength < 1 || rules.PasswordMinLength > rules.PasswordMaxLength {
+		return nil, fmt.Errorf("invalid password length bounds: %d..%d", rules.PasswordMinLength, rules.PasswordMaxLength)
+	}
+
+	v := &UserValidator{rules: rules, breached: breached}
+	if rules.UsernamePattern != "" {
+		pattern, err := regexp.Compile(rules.UsernamePattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid username pattern: %w", err)
+		}
+		v.usernamePattern = pattern
+	}
+	return v, nil
+}
+
+// ValidateUser checks every field of user and returns a *domain.ValidationError
+// listing all violations, or nil.
+func (v *UserValidator) ValidateUser(user *domain.User) error {
+	var violations []domain.FieldViolation
+	violations = append(violations, v.username(user.Username)...)
+	violations = append(violations, v.email(user.Email)...)
+	violations = append(violations, v.password(user.Password, user.Username, user.Email)...)
+	return validationError(violations)
+}
+
+// ValidateUserUpdate is ValidateUser for replacing a user, where an empty password keeps the current one.
+func (v *UserValidator) ValidateUserUpdate(user *domain.User) error {
+	var violations []domain.FieldViolation
+	violations = append(violations, v.username(user.Username)...)
+	violations = append(violations, v.email(user.Email)...)
+	if user.Password != "" {
+		violations = append(violations, v.password(user.Password, user.Username, user.Email)...)
+	}
+	return validationError(violations)
+}
+
+// ValidatePatch checks the fields present in patch.
+func (v *UserValidator) ValidatePatch(patch *domain.UserPatch) error {
+	var violations []domain.FieldViolation
+	if patch.Username != nil {
+		violations = append(violations, v.username(*patch.Username)...)
+	}
+	if patch.Email != nil {
+		violations = append(violations, v.email(*patch.Email)...)
+	}
+	return validationError(violations)
+}
+
+// ValidateEmail checks an email address on its own, e.g. one an invitation is sent to.
+func (v *UserValidator) ValidateEmail(email string) error {
+	return validationError(v.email(email))
+}
+
+// ValidatePassword checks a new password reported under field. The identity values,
+// typically the username and email of the account, must not appear in the password.
+func (v *UserValidator) ValidatePassword(field, password string, identity ...string) error {
+	violations := v.password(password, identity...)
+	for i := range violations {
+		violations[i].Field = field
+	}
+	return validationError(violations)
+}
+
+func (v *UserValidator) username(username string) []domain.FieldViolation {
+	if username == "" {
+		return violation("username", "is required")
+	}
+	if n := utf8.RuneCountInString(username); n < v.rules.UsernameMinLength || n > v.rules.UsernameMaxLength {
+		return violation("username", fmt.Sprintf("must be between %d and %d characters", v.rules.UsernameMinLength, v.rules.UsernameMaxLength))
+	}
+	if v.usernamePattern != nil && !v.usernamePattern.MatchString(username) {
+		return violation("username", "contains characters that are not allowed")
+	}
+	return nil
+}
+
+func (v *UserValidator) email(email string) []domain.FieldViolation {
+	if email == "" {
+		return violation("email", "is required")
+	}
+	if utf8.RuneCountInString(email) > v.rules.EmailMaxLength {
+		return violation("email", fmt.Sprintf("must be at most %d characters", v.rules.EmailMaxLength))
+	}
+	// ParseAddress also accepts "Name <addr>"; only a bare address is allowed.
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email || !strings.Contains(email[strings.LastIndex(email, "@")+1:], ".") {
+		return violation("email", "must be a valid email address")
+	}
+	return nil
+}
+
+func (v *UserValidator) password(password string, identity ...string) []domain.FieldViolation {
+	if password == "" {
+		return violation("password", "is required")
+	}
+	if n := utf8.RuneCountInString(password); n < v.rules.PasswordMinLength || n > v.rules.PasswordMaxLength {
+		return violation("password", fmt.Sprintf("must be between %d and %d characters", v.rules.PasswordMinLength, v.rules.PasswordMaxLength))
+	}
+	// Non-ASCII characters take several bytes each.
+	if v.rules.PasswordMaxBytes > 0 && len(password) > v.rules.PasswordMaxBytes {
+		return violation("password", fmt.Sprintf("must be at most %d bytes long; non-ASCII characters count as several", v.rules.PasswordMaxBytes))
+	}
+
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		case !unicode.IsLetter(r):
+			symbol = true
+		}
+	}
+	var violations []domain.FieldViolation
+	if v.rules.PasswordRequireLower && !lower {
+		violations = append(violations, violation("password", "must contain a lowercase letter")...)
+	}
+	if v.rules.PasswordRequireUpper && !upper {
+		violations = append(violations, violation("password", "must contain an uppercase letter")...)
+	}
+	if v.rules.PasswordRequireDigit && !digit {
+		violations = append(violations, violation("password", "must contain a digit")...)
+	}
+	if v.rules.PasswordRequireSymbol && !symbol {
+		violations = append(violations, violation("password", "must contain a symbol")...)
+	}
+	if v.rules.PasswordRejectIdentity && containsIdentity(password, identity) {
+		violations = append(violations, violation("password", "must not contain the username or email address")...)
+	}
+	if v.breached != nil && v.breached.Contains(password) {
+		violations = append(violations, violation("password", "has appeared in a data breach, choose a different one")...)
+	}
+	return violations
+}
+
+// minIdentityLength keeps short identity fragments from rejecting unrelated passwords.
+const minIdentityLength = 3
+
+// containsIdentity reports whether password contains, ignoring case, any of the identity
+// values or the local part of an email address among them.
+func containsIdentity(password string, identity []string) bool {
+	password = strings.ToLower(password)
+	for _, value := range identity {
+		value = strings.ToLower(value)
+		candidates := []string{value}
+		if local, _, ok := strings.Cut(value, "@"); ok {
+			candidates = append(candidates, local)
+		}
+		for _, c := range candidates {
+			if utf8.RuneCountInString(c) >= minIdentityLength && strings.Contains(password, c) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func validationError(violations []domain.FieldViolation) error {
+	if len(violations) > 0 {
+		return domain.NewValidationError(violations...)
+	}
+	return nil
+}
+
+func violation(field, description string) []domain.FieldViolation {
+	return []domain.FieldViolation{{Field: field, Description: description}}
+}
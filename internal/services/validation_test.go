@@ -0,0 +1,153 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testValidator(t *testing.T) *UserValidator {
+	t.Helper()
+	v, err := NewUserValidator(DefaultUserRules(), nil)
+	require.NoError(t, err)
+	return v
+}
+
+func TestNewUserValidator_InvalidRules(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(r *UserRules)
+	}{
+		{name: "UsernameBounds", modify: func(r *UserRules) { r.UsernameMinLength = 40 }},
+		{name: "UsernameTooLongForColumn", modify: func(r *UserRules) { r.UsernameMaxLength = 101 }},
+		{name: "EmailTooLongForColumn", modify: func(r *UserRules) { r.EmailMaxLength = 200 }},
+		{name: "PasswordBounds", modify: func(r *UserRules) { r.PasswordMinLength = 0 }},
+		{name: "Pattern", modify: func(r *UserRules) { r.UsernamePattern = "[" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := DefaultUserRules()
+			tt.modify(&rules)
+			_, err := NewUserValidator(rules, nil)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestUserValidator_ValidateUser(t *testing.T) {
+	v := testValidator(t)
+	valid := domain.User{Username: "alice", Email: "alice@example.com", Password: "password123"}
+
+	tests := []struct {
+		name   string
+		modify func(u *domain.User)
+		fields []string
+	}{
+		{name: "Valid", modify: func(u *domain.User) {}},
+		{name: "MissingEverything", modify: func(u *domain.User) { *u = domain.User{} }, fields: []string{"username", "email", "password"}},
+		{name: "UsernameTooShort", modify: func(u *domain.User) { u.Username = "al" }, fields: []string{"username"}},
+		{name: "UsernameTooLong", modify: func(u *domain.User) { u.Username = strings.Repeat("a", 33) }, fields: []string{"username"}},
+		{name: "UsernameCharacters", modify: func(u *domain.User) { u.Username = "al ice" }, fields: []string{"username"}},
+		{name: "EmailSyntax", modify: func(u *domain.User) { u.Email = "alice" }, fields: []string{"email"}},
+		{name: "EmailDisplayName", modify: func(u *domain.User) { u.Email = "Alice <alice@example.com>" }, fields: []string{"email"}},
+		{name: "EmailWithoutDomainDot", modify: func(u *domain.User) { u.Email = "alice@localhost" }, fields: []string{"email"}},
+		{name: "EmailTooLong", modify: func(u *domain.User) { u.Email = strings.Repeat("a", 95) + "@example.com" }, fields: []string{"email"}},
+		{name: "PasswordTooShort", modify: func(u *domain.User) { u.Password = "short" }, fields: []string{"password"}},
+		{name: "PasswordTooLong", modify: func(u *domain.User) { u.Password = strings.Repeat("p", 73) }, fields: []string{"password"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := valid
+			tt.modify(&user)
+
+			err := v.ValidateUser(&user)
+			if tt.fields == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			var validationErr *domain.ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			fields := make([]string, 0, len(validationErr.Violations))
+			for _, violation := range validationErr.Violations {
+				fields = append(fields, violation.Field)
+			}
+			assert.Equal(t, tt.fields, fields)
+		})
+	}
+}
+
+type breachedSet map[string]bool
+
+func (b breachedSet) Contains(password string) bool { return b[password] }
+
+func TestUserValidator_ValidatePassword(t *testing.T) {
+	rules := DefaultUserRules()
+	rules.PasswordRequireLower = true
+	rules.PasswordRequireUpper = true
+	rules.PasswordRequireDigit = true
+	rules.PasswordRequireSymbol = true
+	v, err := NewUserValidator(rules, breachedSet{"Passw0rd!": true})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		password     string
+		descriptions []string
+	}{
+		{name: "Valid", password: "Correct-h0rse"},
+		{name: "TooShort", password: "Ab1!", descriptions: []string{"must be between 8 and 72 characters"}},
+		{name: "NoLower", password: "CORRECT-H0RSE", descriptions: []string{"must contain a lowercase letter"}},
+		{name: "NoUpper", password: "correct-h0rse", descriptions: []string{"must contain an uppercase letter"}},
+		{name: "NoDigitOrSymbol", password: "CorrectHorse", descriptions: []string{"must contain a digit", "must contain a symbol"}},
+		{name: "Username", password: "xAlice-2024", descriptions: []string{"must not contain the username or email address"}},
+		{name: "EmailLocalPart", password: "Al.Smith-99", descriptions: []string{"must not contain the username or email address"}},
+		{name: "Breached", password: "Passw0rd!", descriptions: []string{"has appeared in a data breach, choose a different one"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.ValidatePassword("new_password", tt.password, "alice", "al.smith@example.com")
+			if tt.descriptions == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			var validationErr *domain.ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			descriptions := make([]string, 0, len(validationErr.Violations))
+			for _, violation := range validationErr.Violations {
+				assert.Equal(t, "new_password", violation.Field)
+				descriptions = append(descriptions, violation.Description)
+			}
+			assert.Equal(t, tt.descriptions, descriptions)
+		})
+	}
+}
+
+func TestUserValidator_ValidatePassword_MaxBytes(t *testing.T) {
+	rules := DefaultUserRules()
+	rules.PasswordMaxBytes = 72
+	v, err := NewUserValidator(rules, nil)
+	require.NoError(t, err)
+
+	assert.NoError(t, v.ValidatePassword("password", strings.Repeat("p", 72)))
+	err = v.ValidatePassword("password", strings.Repeat("п", 72))
+	var validationErr *domain.ValidationError
+	require.ErrorAs(t, err, &validationErr, "72 Cyrillic letters take 144 bytes")
+	assert.Equal(t, "must be at most 72 bytes long; non-ASCII characters count as several", validationErr.Violations[0].Description)
+}
+
+func TestUserValidator_ValidatePassword_IdentityDisabled(t *testing.T) {
+	rules := DefaultUserRules()
+	rules.PasswordRejectIdentity = false
+	v, err := NewUserValidator(rules, nil)
+	require.NoError(t, err)
+
+	assert.NoError(t, v.ValidatePassword("password", "alice-password", "alice"))
+}
@@ -0,0 +1,63 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/kerim-dauren/user-service/pkg/postgresx"
+)
+
+type emailVerificationStorage struct {
+	db *postgresx.Postgres
+}
+
+func NewEmailVerificationStorage(db *postgresx.Postgres) domain.EmailVerificationStorage {
+	return &emailVerificationStorage{db: db}
+}
+
+const (
+	createEmailVerificationTokenQuery = `INSERT INTO email_verification_tokens (user_id, email, token_hash, expires_at, created_at)
+		SELECT $1, $2, $3, $4, $5
+		WHERE (SELECT count(*) FROM email_verification_tokens WHERE email=$2 AND created_at > $6) < $7
+		RETURNING id`
+	consumeEmailVerificationTokenQuery = `UPDATE email_verification_tokens SET used_at=$2
+		WHERE token_hash=$1 AND used_at IS NULL AND expires_at > $2
+		RETURNING id, user_id, email, token_hash, expires_at, used_at, created_at`
+	deleteUserEmailVerificationTokensQuery = `DELETE FROM email_verification_tokens WHERE user_id=$1`
+)
+
+func (r *emailVerificationStorage) CreateEmailVerificationToken(ctx context.Context, t *domain.EmailVerificationToken, limit int, since time.Time) (int64, error) {
+	var id int64
+	err := pgx.BeginFunc(ctx, r.db.Pool, func(tx pgx.Tx) error {
+		if err := lockSendLimit(ctx, tx, "email_verification", t.Email); err != nil {
+			return err
+		}
+		return tx.QueryRow(ctx, createEmailVerificationTokenQuery, t.UserID, t.Email, t.TokenHash, t.ExpiresAt, t.CreatedAt, since, limit).Scan(&id)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, domain.ErrEmailThrottled
+	}
+	return id, err
+}
+
+func (r *emailVerificationStorage) ConsumeEmailVerificationToken(ctx context.Context, tokenHash string, now time.Time) (*domain.EmailVerificationToken, error) {
+	var t domain.EmailVerificationToken
+	err := r.db.Pool.QueryRow(ctx, consumeEmailVerificationTokenQuery, tokenHash, now).
+		Scan(&t.ID, &t.UserID, &t.Email, &t.TokenHash, &t.ExpiresAt, &t.UsedAt, &t.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrInvalidEmailVerificationToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *emailVerificationStorage) DeleteUserEmailVerificationTokens(ctx context.Context, userID int64) error {
+	_, err := r.db.Pool.Exec(ctx, deleteUserEmailVerificationTokensQuery, userID)
+	return err
+}
@@ -0,0 +1,87 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/kerim-dauren/user-service/pkg/postgresx"
+)
+
+type invitationStorage struct {
+	db *postgresx.Postgres
+}
+
+func NewInvitationStorage(db *postgresx.Postgres) domain.InvitationStorage {
+	return &invitationStorage{db: db}
+}
+
+const (
+	invitationColumns     = `id, organization_id, email, role, COALESCE(inviter_id, 0), status, token_hash, expires_at, responded_at, created_at`
+	createInvitationQuery = `INSERT INTO organization_invitations (organization_id, email, role, inviter_id, status, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, NULLIF($4::BIGINT, 0), '` + domain.InvitationPending + `', $5, $6, $7) RETURNING id`
+	listInvitationsQuery = `SELECT ` + invitationColumns + ` FROM organization_invitations
+		WHERE organization_id=$1 ORDER BY created_at DESC, id DESC`
+	getPendingInvitationQuery = `SELECT ` + invitationColumns + ` FROM organization_invitations
+		WHERE token_hash=$1 AND status='` + domain.InvitationPending + `' AND expires_at > $2`
+	respondToInvitationQuery = `UPDATE organization_invitations SET status=$2, responded_at=$3
+		WHERE token_hash=$1 AND status='` + domain.InvitationPending + `' AND expires_at > $3 RETURNING ` + invitationColumns
+	revokeInvitationQuery = `UPDATE organization_invitations SET status='` + domain.InvitationRevoked + `', responded_at=$3
+		WHERE organization_id=$1 AND id=$2 AND status='` + domain.InvitationPending + `'`
+)
+
+func (r *invitationStorage) CreateInvitation(ctx context.Context, inv *domain.Invitation) (int64, error) {
+	var id int64
+	err := r.db.Pool.QueryRow(ctx, createInvitationQuery,
+		inv.OrganizationID, inv.Email, inv.Role, inv.InviterID, inv.TokenHash, inv.ExpiresAt, time.Now()).Scan(&id)
+	return id, err
+}
+
+func (r *invitationStorage) ListInvitations(ctx context.Context, orgID int64) ([]*domain.Invitation, error) {
+	rows, err := r.db.Pool.Query(ctx, listInvitationsQuery, orgID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, scanInvitation)
+}
+
+func (r *invitationStorage) GetPendingInvitation(ctx context.Context, tokenHash string, now time.Time) (*domain.Invitation, error) {
+	return r.getInvitation(ctx, getPendingInvitationQuery, tokenHash, now)
+}
+
+func (r *invitationStorage) RespondToInvitation(ctx context.Context, tokenHash, status string, now time.Time) (*domain.Invitation, error) {
+	return r.getInvitation(ctx, respondToInvitationQuery, tokenHash, status, now)
+}
+
+func (r *invitationStorage) RevokeInvitation(ctx context.Context, orgID, id int64, now time.Time) error {
+	tag, err := r.db.Pool.Exec(ctx, revokeInvitationQuery, orgID, id, now)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrInvitationNotFound
+	}
+	return nil
+}
+
+func (r *invitationStorage) getInvitation(ctx context.Context, query string, args ...any) (*domain.Invitation, error) {
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	inv, err := pgx.CollectExactlyOneRow(rows, scanInvitation)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrInvalidInvitationToken
+	}
+	return inv, err
+}
+
+func scanInvitation(row pgx.CollectableRow) (*domain.Invitation, error) {
+	var inv domain.Invitation
+	err := row.Scan(&inv.ID, &inv.OrganizationID, &inv.Email, &inv.Role, &inv.InviterID, &inv.Status,
+		&inv.TokenHash, &inv.ExpiresAt, &inv.RespondedAt, &inv.CreatedAt)
+	return &inv, err
+}
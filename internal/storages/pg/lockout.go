@@ -0,0 +1,69 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/kerim-dauren/user-service/pkg/postgresx"
+)
+
+type lockoutStorage struct {
+	db *postgresx.Postgres
+}
+
+func NewLockoutStorage(db *postgresx.Postgres) domain.LockoutStorage {
+	return &lockoutStorage{db: db}
+}
+
+const (
+	getLoginFailuresQuery = `SELECT f.scope, f.key, f.failures, f.last_failure_at, f.locked_until
+		FROM login_failures f JOIN unnest($1::text[], $2::text[]) AS s(scope, key) ON f.scope=s.scope AND f.key=s.key`
+	recordLoginFailureQuery = `INSERT INTO login_failures AS f (scope, key, failures, last_failure_at) VALUES ($1, $2, 1, $3)
+		ON CONFLICT (scope, key) DO UPDATE SET
+			failures = CASE WHEN f.last_failure_at < $4 THEN 1 ELSE f.failures + 1 END,
+			last_failure_at = $3
+		RETURNING failures`
+	lockLoginSubjectQuery    = `UPDATE login_failures SET locked_until=$3 WHERE scope=$1 AND key=$2`
+	deleteLoginFailuresQuery = `DELETE FROM login_failures WHERE scope=$1 AND key=$2`
+)
+
+func (r *lockoutStorage) GetLoginFailures(ctx context.Context, subjects []domain.LoginSubject) ([]*domain.LoginFailures, error) {
+	scopes := make([]string, 0, len(subjects))
+	keys := make([]string, 0, len(subjects))
+	for _, s := range subjects {
+		scopes = append(scopes, string(s.Scope))
+		keys = append(keys, s.Key)
+	}
+	rows, err := r.db.Pool.Query(ctx, getLoginFailuresQuery, scopes, keys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var failures []*domain.LoginFailures
+	for rows.Next() {
+		var f domain.LoginFailures
+		if err = rows.Scan(&f.Subject.Scope, &f.Subject.Key, &f.Failures, &f.LastFailureAt, &f.LockedUntil); err != nil {
+			return nil, err
+		}
+		failures = append(failures, &f)
+	}
+	return failures, rows.Err()
+}
+
+func (r *lockoutStorage) RecordLoginFailure(ctx context.Context, subject domain.LoginSubject, now, resetBefore time.Time) (int, error) {
+	var failures int
+	err := r.db.Pool.QueryRow(ctx, recordLoginFailureQuery, string(subject.Scope), subject.Key, now, resetBefore).Scan(&failures)
+	return failures, err
+}
+
+func (r *lockoutStorage) LockLoginSubject(ctx context.Context, subject domain.LoginSubject, until time.Time) error {
+	_, err := r.db.Pool.Exec(ctx, lockLoginSubjectQuery, string(subject.Scope), subject.Key, until)
+	return err
+}
+
+func (r *lockoutStorage) DeleteLoginFailures(ctx context.Context, subject domain.LoginSubject) error {
+	_, err := r.db.Pool.Exec(ctx, deleteLoginFailuresQuery, string(subject.Scope), subject.Key)
+	return err
+}
@@ -0,0 +1,67 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/kerim-dauren/user-service/pkg/postgresx"
+)
+
+type magicLinkStorage struct {
+	db *postgresx.Postgres
+}
+
+func NewMagicLinkStorage(db *postgresx.Postgres) domain.MagicLinkStorage {
+	return &magicLinkStorage{db: db}
+}
+
+const (
+	createMagicLinkTokenQuery = `INSERT INTO magic_link_tokens (user_id, email, token_hash, expires_at, created_at)
+		SELECT $1, $2, $3, $4, $5
+		WHERE (SELECT count(*) FROM magic_link_tokens WHERE email=$2 AND created_at > $6) < $7
+		RETURNING id`
+	consumeMagicLinkTokenQuery = `UPDATE magic_link_tokens SET used_at=$2
+		WHERE token_hash=$1 AND used_at IS NULL AND expires_at > $2
+		RETURNING id, user_id, email, token_hash, expires_at, used_at, created_at`
+	invalidateUserMagicLinkTokensQuery = `UPDATE magic_link_tokens SET used_at=$2 WHERE user_id=$1 AND used_at IS NULL`
+	pruneUserMagicLinkTokensQuery      = `DELETE FROM magic_link_tokens WHERE user_id=$1 AND created_at <= $2`
+)
+
+func (r *magicLinkStorage) CreateMagicLinkToken(ctx context.Context, t *domain.MagicLinkToken, limit int, since time.Time) (int64, error) {
+	var id int64
+	err := pgx.BeginFunc(ctx, r.db.Pool, func(tx pgx.Tx) error {
+		if err := lockSendLimit(ctx, tx, "magic_link", t.Email); err != nil {
+			return err
+		}
+		return tx.QueryRow(ctx, createMagicLinkTokenQuery, t.UserID, t.Email, t.TokenHash, t.ExpiresAt, t.CreatedAt, since, limit).Scan(&id)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, domain.ErrEmailThrottled
+	}
+	return id, err
+}
+
+func (r *magicLinkStorage) ConsumeMagicLinkToken(ctx context.Context, tokenHash string, now time.Time) (*domain.MagicLinkToken, error) {
+	var t domain.MagicLinkToken
+	err := r.db.Pool.QueryRow(ctx, consumeMagicLinkTokenQuery, tokenHash, now).
+		Scan(&t.ID, &t.UserID, &t.Email, &t.TokenHash, &t.ExpiresAt, &t.UsedAt, &t.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrInvalidMagicLinkToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *magicLinkStorage) InvalidateUserMagicLinkTokens(ctx context.Context, userID int64, now, prunedBefore time.Time) error {
+	if _, err := r.db.Pool.Exec(ctx, invalidateUserMagicLinkTokensQuery, userID, now); err != nil {
+		return err
+	}
+	_, err := r.db.Pool.Exec(ctx, pruneUserMagicLinkTokensQuery, userID, prunedBefore)
+	return err
+}
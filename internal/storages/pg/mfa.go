@@ -0,0 +1,169 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/kerim-dauren/user-service/pkg/postgresx"
+)
+
+type mfaStorage struct {
+	db *postgresx.Postgres
+}
+
+func NewMFAStorage(db *postgresx.Postgres) domain.MFAStorage {
+	return &mfaStorage{db: db}
+}
+
+const (
+	getTOTPCredentialQuery = `SELECT user_id, secret_encrypted, enabled_at, last_used_step, created_at
+		FROM user_totp_credentials WHERE user_id=$1`
+	savePendingTOTPCredentialQuery = `INSERT INTO user_totp_credentials (user_id, secret_encrypted, created_at) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE SET secret_encrypted=EXCLUDED.secret_encrypted, last_used_step=0, created_at=EXCLUDED.created_at
+		WHERE user_totp_credentials.enabled_at IS NULL`
+	enableTOTPCredentialQuery = `UPDATE user_totp_credentials SET enabled_at=$2, last_used_step=$3
+		WHERE user_id=$1 AND enabled_at IS NULL`
+	useTOTPStepQuery = `UPDATE user_totp_credentials SET last_used_step=$2
+		WHERE user_id=$1 AND enabled_at IS NOT NULL AND last_used_step < $2`
+	deleteTOTPCredentialQuery = `DELETE FROM user_totp_credentials WHERE user_id=$1`
+
+	deleteRecoveryCodesQuery = `DELETE FROM mfa_recovery_codes WHERE user_id=$1`
+	insertRecoveryCodeQuery  = `INSERT INTO mfa_recovery_codes (user_id, code_hash, created_at) VALUES ($1, $2, $3)`
+	useRecoveryCodeQuery     = `UPDATE mfa_recovery_codes SET used_at=$3 WHERE user_id=$1 AND code_hash=$2 AND used_at IS NULL`
+
+	createMFAChallengeQuery = `INSERT INTO mfa_challenges (user_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4) RETURNING id`
+	getMFAChallengeQuery          = `SELECT id, user_id, token_hash, expires_at, attempts FROM mfa_challenges WHERE token_hash=$1`
+	countMFAChallengeAttemptQuery = `UPDATE mfa_challenges SET attempts=attempts+1 WHERE id=$1 AND attempts < $2 RETURNING attempts`
+	deleteMFAChallengeQuery       = `DELETE FROM mfa_challenges WHERE id=$1`
+)
+
+func (r *mfaStorage) GetTOTPCredential(ctx context.Context, userID int64) (*domain.TOTPCredential, error) {
+	var c domain.TOTPCredential
+	err := r.db.Pool.QueryRow(ctx, getTOTPCredentialQuery, userID).
+		Scan(&c.UserID, &c.SecretEncrypted, &c.EnabledAt, &c.LastUsedStep, &c.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrMFANotEnabled
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *mfaStorage) SavePendingTOTPCredential(ctx context.Context, c *domain.TOTPCredential) error {
+	tag, err := r.db.Pool.Exec(ctx, savePendingTOTPCredentialQuery, c.UserID, c.SecretEncrypted, time.Now())
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrMFAAlreadyEnabled
+	}
+	return nil
+}
+
+func (r *mfaStorage) EnableTOTPCredential(ctx context.Context, userID int64, step int64, recoveryCodeHashes []string, now time.Time) error {
+	return pgx.BeginFunc(ctx, r.db.Pool, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, enableTOTPCredentialQuery, userID, now, step)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			// Lost a race against another confirmation.
+			return domain.ErrMFAAlreadyEnabled
+		}
+		return replaceRecoveryCodes(ctx, tx, userID, recoveryCodeHashes, now)
+	})
+}
+
+func (r *mfaStorage) UseTOTPStep(ctx context.Context, userID int64, step int64) error {
+	tag, err := r.db.Pool.Exec(ctx, useTOTPStepQuery, userID, step)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrInvalidMFACode
+	}
+	return nil
+}
+
+func (r *mfaStorage) DeleteMFA(ctx context.Context, userID int64) error {
+	return pgx.BeginFunc(ctx, r.db.Pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, deleteRecoveryCodesQuery, userID); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, deleteTOTPCredentialQuery, userID)
+		return err
+	})
+}
+
+func (r *mfaStorage) ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error {
+	return pgx.BeginFunc(ctx, r.db.Pool, func(tx pgx.Tx) error {
+		return replaceRecoveryCodes(ctx, tx, userID, codeHashes, time.Now())
+	})
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx pgx.Tx, userID int64, codeHashes []string, now time.Time) error {
+	if _, err := tx.Exec(ctx, deleteRecoveryCodesQuery, userID); err != nil {
+		return err
+	}
+	batch := &pgx.Batch{}
+	for _, hash := range codeHashes {
+		batch.Queue(insertRecoveryCodeQuery, userID, hash, now)
+	}
+	return tx.SendBatch(ctx, batch).Close()
+}
+
+func (r *mfaStorage) UseRecoveryCode(ctx context.Context, userID int64, codeHash string, now time.Time) error {
+	tag, err := r.db.Pool.Exec(ctx, useRecoveryCodeQuery, userID, codeHash, now)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrInvalidMFACode
+	}
+	return nil
+}
+
+func (r *mfaStorage) CreateMFAChallenge(ctx context.Context, c *domain.MFAChallengeRecord) (int64, error) {
+	var id int64
+	err := r.db.Pool.QueryRow(ctx, createMFAChallengeQuery, c.UserID, c.TokenHash, c.ExpiresAt, time.Now()).Scan(&id)
+	return id, err
+}
+
+func (r *mfaStorage) GetMFAChallenge(ctx context.Context, tokenHash string) (*domain.MFAChallengeRecord, error) {
+	var c domain.MFAChallengeRecord
+	err := r.db.Pool.QueryRow(ctx, getMFAChallengeQuery, tokenHash).Scan(&c.ID, &c.UserID, &c.TokenHash, &c.ExpiresAt, &c.Attempts)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrInvalidMFAChallenge
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *mfaStorage) CountMFAChallengeAttempt(ctx context.Context, id int64, maxAttempts int) error {
+	var attempts int
+	err := r.db.Pool.QueryRow(ctx, countMFAChallengeAttemptQuery, id, maxAttempts).Scan(&attempts)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrInvalidMFAChallenge
+	}
+	return err
+}
+
+func (r *mfaStorage) DeleteMFAChallenge(ctx context.Context, id int64) error {
+	tag, err := r.db.Pool.Exec(ctx, deleteMFAChallengeQuery, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrInvalidMFAChallenge
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/kerim-dauren/user-service/pkg/postgresx"
+)
+
+type organizationStorage struct {
+	db *postgresx.Postgres
+}
+
+func NewOrganizationStorage(db *postgresx.Postgres) domain.OrganizationStorage {
+	return &organizationStorage{db: db}
+}
+
+const (
+	createOrganizationQuery = `WITH created AS (
+			INSERT INTO organizations (slug, name, created_at) VALUES ($1, $2, now())
+			ON CONFLICT (slug) DO NOTHING RETURNING id
+		), owner AS (
+			INSERT INTO organization_members (organization_id, user_id, role, joined_at)
+			SELECT id, $3, '` + domain.OrganizationRoleOwner + `', now() FROM created
+		)
+		SELECT id FROM created`
+	listOrganizationsQuery = `SELECT id, slug, name, created_at FROM organizations ORDER BY id`
+	getOrganizationQuery   = `SELECT id, slug, name, created_at FROM organizations WHERE id=$1`
+	selectMembersQuery     = `SELECT m.user_id, u.username, u.email, m.role, m.joined_at
+		FROM organization_members m JOIN users u ON u.id = m.user_id`
+	listMembersQuery   = selectMembersQuery + ` WHERE m.organization_id=$1 ORDER BY m.user_id`
+	getMemberQuery     = selectMembersQuery + ` WHERE m.organization_id=$1 AND m.user_id=$2`
+	setMemberRoleQuery = `UPDATE organization_members SET role=$3 WHERE organization_id=$1 AND user_id=$2`
+	addMemberQuery     = `INSERT INTO organization_members (organization_id, user_id, role, joined_at) VALUES ($1, $2, $3, now())
+		ON CONFLICT (organization_id, user_id) DO NOTHING`
+)
+
+func (r *organizationStorage) CreateOrganization(ctx context.Context, org *domain.Organization, ownerID int64) (int64, error) {
+	var id int64
+	err := r.db.Pool.QueryRow(ctx, createOrganizationQuery, org.Slug, org.Name, ownerID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, domain.ErrOrganizationExists
+	}
+	return id, err
+}
+
+func (r *organizationStorage) ListOrganizations(ctx context.Context) ([]*domain.Organization, error) {
+	rows, err := r.db.Pool.Query(ctx, listOrganizationsQuery)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*domain.Organization, error) {
+		var org domain.Organization
+		err := row.Scan(&org.ID, &org.Slug, &org.Name, &org.CreatedAt)
+		return &org, err
+	})
+}
+
+func (r *organizationStorage) GetOrganization(ctx context.Context, id int64) (*domain.Organization, error) {
+	var org domain.Organization
+	err := r.db.Pool.QueryRow(ctx, getOrganizationQuery, id).Scan(&org.ID, &org.Slug, &org.Name, &org.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrOrganizationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &org, nil
+}
+
+func (r *organizationStorage) ListMembers(ctx context.Context, orgID int64) ([]*domain.Member, error) {
+	rows, err := r.db.Pool.Query(ctx, listMembersQuery, orgID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, scanMember)
+}
+
+func (r *organizationStorage) GetMember(ctx context.Context, orgID, userID int64) (*domain.Member, error) {
+	rows, err := r.db.Pool.Query(ctx, getMemberQuery, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	member, err := pgx.CollectExactlyOneRow(rows, scanMember)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrUserNotFound
+	}
+	return member, err
+}
+
+func (r *organizationStorage) SetMemberRole(ctx context.Context, orgID, userID int64, role string) error {
+	tag, err := r.db.Pool.Exec(ctx, setMemberRoleQuery, orgID, userID, role)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *organizationStorage) AddMember(ctx context.Context, orgID, userID int64, role string) error {
+	_, err := r.db.Pool.Exec(ctx, addMemberQuery, orgID, userID, role)
+	return err
+}
+
+func scanMember(row pgx.CollectableRow) (*domain.Member, error) {
+	var m domain.Member
+	err := row.Scan(&m.UserID, &m.Username, &m.Email, &m.Role, &m.JoinedAt)
+	return &m, err
+}
@@ -0,0 +1,135 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/kerim-dauren/user-service/pkg/postgresx"
+)
+
+type passkeyStorage struct {
+	db *postgresx.Postgres
+}
+
+func NewPasskeyStorage(db *postgresx.Postgres) domain.PasskeyStorage {
+	return &passkeyStorage{db: db}
+}
+
+const (
+	passkeyColumns = `id, user_id, credential_id, name, public_key, attestation_type, transports, aaguid,
+		sign_count, backup_eligible, backup_state, created_at, last_used_at`
+	createPasskeyQuery = `INSERT INTO webauthn_credentials (user_id, credential_id, name, public_key, attestation_type,
+		transports, aaguid, sign_count, backup_eligible, backup_state, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (credential_id) DO NOTHING RETURNING id`
+	listPasskeysQuery             = `SELECT ` + passkeyColumns + ` FROM webauthn_credentials WHERE user_id=$1 ORDER BY id`
+	getPasskeyByCredentialIDQuery = `SELECT ` + passkeyColumns + ` FROM webauthn_credentials WHERE credential_id=$1`
+	updatePasskeyUsageQuery       = `UPDATE webauthn_credentials SET sign_count=$2, backup_state=$3, last_used_at=$4 WHERE id=$1`
+	deletePasskeyQuery            = `DELETE FROM webauthn_credentials WHERE id=$1 AND user_id=$2`
+	createPasskeyCeremonyQuery    = `INSERT INTO webauthn_ceremonies (user_id, kind, token_hash, data, expires_at, created_at) VALUES ($1, $2, $3, $4, $5, $6)`
+	consumePasskeyCeremonyQuery   = `DELETE FROM webauthn_ceremonies WHERE token_hash=$1 AND kind=$2 AND expires_at > $3
+		RETURNING id, user_id, kind, token_hash, data, expires_at`
+	deleteExpiredCeremoniesQuery = `DELETE FROM webauthn_ceremonies WHERE expires_at <= $1`
+)
+
+func (r *passkeyStorage) CreatePasskey(ctx context.Context, p *domain.Passkey) (int64, error) {
+	var id int64
+	err := r.db.Pool.QueryRow(ctx, createPasskeyQuery, p.UserID, p.CredentialID, p.Name, p.PublicKey, p.AttestationType,
+		p.Transports, p.AAGUID, int64(p.SignCount), p.BackupEligible, p.BackupState, time.Now()).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, domain.ErrPasskeyExists
+	}
+	return id, err
+}
+
+func (r *passkeyStorage) ListPasskeys(ctx context.Context, userID int64) ([]*domain.Passkey, error) {
+	rows, err := r.db.Pool.Query(ctx, listPasskeysQuery, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var passkeys []*domain.Passkey
+	for rows.Next() {
+		p, err := scanPasskey(rows)
+		if err != nil {
+			return nil, err
+		}
+		passkeys = append(passkeys, p)
+	}
+	return passkeys, rows.Err()
+}
+
+func (r *passkeyStorage) GetPasskeyByCredentialID(ctx context.Context, credentialID []byte) (*domain.Passkey, error) {
+	p, err := scanPasskey(r.db.Pool.QueryRow(ctx, getPasskeyByCredentialIDQuery, credentialID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrPasskeyNotFound
+	}
+	return p, err
+}
+
+func (r *passkeyStorage) UpdatePasskeyUsage(ctx context.Context, id int64, signCount uint32, backupState bool, usedAt time.Time) error {
+	tag, err := r.db.Pool.Exec(ctx, updatePasskeyUsageQuery, id, int64(signCount), backupState, usedAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrPasskeyNotFound
+	}
+	return nil
+}
+
+func (r *passkeyStorage) DeletePasskey(ctx context.Context, userID, id int64) error {
+	tag, err := r.db.Pool.Exec(ctx, deletePasskeyQuery, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrPasskeyNotFound
+	}
+	return nil
+}
+
+func (r *passkeyStorage) CreatePasskeyCeremony(ctx context.Context, c *domain.PasskeyCeremonyRecord) error {
+	now := time.Now()
+	return pgx.BeginFunc(ctx, r.db.Pool, func(tx pgx.Tx) error {
+		// Abandoned ceremonies are never consumed, so they are cleaned up here.
+		if _, err := tx.Exec(ctx, deleteExpiredCeremoniesQuery, now); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, createPasskeyCeremonyQuery, c.UserID, string(c.Kind), c.TokenHash, c.Data, c.ExpiresAt, now)
+		return err
+	})
+}
+
+func (r *passkeyStorage) ConsumePasskeyCeremony(ctx context.Context, tokenHash string, kind domain.PasskeyCeremonyKind, now time.Time) (*domain.PasskeyCeremonyRecord, error) {
+	var c domain.PasskeyCeremonyRecord
+	err := r.db.Pool.QueryRow(ctx, consumePasskeyCeremonyQuery, tokenHash, string(kind), now).
+		Scan(&c.ID, &c.UserID, &c.Kind, &c.TokenHash, &c.Data, &c.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrInvalidPasskeySession
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func scanPasskey(row pgx.Row) (*domain.Passkey, error) {
+	var (
+		p         domain.Passkey
+		signCount int64
+	)
+	err := row.Scan(&p.ID, &p.UserID, &p.CredentialID, &p.Name, &p.PublicKey, &p.AttestationType, &p.Transports,
+		&p.AAGUID, &signCount, &p.BackupEligible, &p.BackupState, &p.CreatedAt, &p.LastUsedAt)
+	if err != nil {
+		return nil, err
+	}
+	p.SignCount = uint32(signCount)
+	return &p, nil
+}
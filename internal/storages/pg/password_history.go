@@ -0,0 +1,53 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/kerim-dauren/user-service/pkg/postgresx"
+)
+
+type passwordHistoryStorage struct {
+	db *postgresx.Postgres
+}
+
+func NewPasswordHistoryStorage(db *postgresx.Postgres) domain.PasswordHistoryStorage {
+	return &passwordHistoryStorage{db: db}
+}
+
+const (
+	addPasswordHistoryQuery  = `INSERT INTO password_history (user_id, password_hash, created_at) VALUES ($1, $2, $3)`
+	listPasswordHistoryQuery = `SELECT password_hash FROM password_history
+		WHERE user_id=$1 AND created_at > $2 ORDER BY created_at DESC, id DESC LIMIT $3`
+	prunePasswordHistoryQuery = `DELETE FROM password_history WHERE user_id=$1 AND (created_at < $3 OR id NOT IN (
+		SELECT id FROM password_history WHERE user_id=$1 ORDER BY created_at DESC, id DESC LIMIT $2))`
+)
+
+func (r *passwordHistoryStorage) AddPasswordHistory(ctx context.Context, userID int64, passwordHash string, createdAt time.Time) error {
+	_, err := r.db.Pool.Exec(ctx, addPasswordHistoryQuery, userID, passwordHash, createdAt)
+	return err
+}
+
+func (r *passwordHistoryStorage) ListPasswordHistory(ctx context.Context, userID int64, since time.Time, limit int) ([]string, error) {
+	rows, err := r.db.Pool.Query(ctx, listPasswordHistoryQuery, userID, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hashes []string
+	for rows.Next() {
+		var hash string
+		if err = rows.Scan(&hash); err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, hash)
+	}
+	return hashes, rows.Err()
+}
+
+func (r *passwordHistoryStorage) PrunePasswordHistory(ctx context.Context, userID int64, keep int, before time.Time) error {
+	_, err := r.db.Pool.Exec(ctx, prunePasswordHistoryQuery, userID, keep, before)
+	return err
+}
@@ -0,0 +1,69 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/kerim-dauren/user-service/pkg/postgresx"
+)
+
+type passwordResetStorage struct {
+	db *postgresx.Postgres
+}
+
+func NewPasswordResetStorage(db *postgresx.Postgres) domain.PasswordResetStorage {
+	return &passwordResetStorage{db: db}
+}
+
+const (
+	createPasswordResetTokenQuery = `INSERT INTO password_reset_tokens (user_id, token_hash, expires_at, created_at)
+		SELECT $1, $2, $3, $4
+		WHERE (SELECT count(*) FROM password_reset_tokens WHERE user_id=$1 AND created_at > $5) < $6
+		RETURNING id`
+	getPasswordResetTokenUserQuery = `SELECT user_id FROM password_reset_tokens
+		WHERE token_hash=$1 AND used_at IS NULL AND expires_at > $2`
+	consumePasswordResetTokenQuery = `UPDATE password_reset_tokens SET used_at=$2
+		WHERE token_hash=$1 AND used_at IS NULL AND expires_at > $2 RETURNING user_id`
+	deleteUserPasswordResetTokensQuery = `DELETE FROM password_reset_tokens WHERE user_id=$1`
+)
+
+func (r *passwordResetStorage) CreatePasswordResetToken(ctx context.Context, t *domain.PasswordResetToken, limit int, since time.Time) (int64, error) {
+	var id int64
+	err := pgx.BeginFunc(ctx, r.db.Pool, func(tx pgx.Tx) error {
+		if err := lockSendLimit(ctx, tx, "password_reset", t.UserID); err != nil {
+			return err
+		}
+		return tx.QueryRow(ctx, createPasswordResetTokenQuery, t.UserID, t.TokenHash, t.ExpiresAt, t.CreatedAt, since, limit).Scan(&id)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, domain.ErrEmailThrottled
+	}
+	return id, err
+}
+
+func (r *passwordResetStorage) GetPasswordResetTokenUser(ctx context.Context, tokenHash string, now time.Time) (int64, error) {
+	var userID int64
+	err := r.db.Pool.QueryRow(ctx, getPasswordResetTokenUserQuery, tokenHash, now).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, domain.ErrInvalidPasswordResetToken
+	}
+	return userID, err
+}
+
+func (r *passwordResetStorage) ConsumePasswordResetToken(ctx context.Context, tokenHash string, now time.Time) (int64, error) {
+	var userID int64
+	err := r.db.Pool.QueryRow(ctx, consumePasswordResetTokenQuery, tokenHash, now).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, domain.ErrInvalidPasswordResetToken
+	}
+	return userID, err
+}
+
+func (r *passwordResetStorage) DeleteUserPasswordResetTokens(ctx context.Context, userID int64) error {
+	_, err := r.db.Pool.Exec(ctx, deleteUserPasswordResetTokensQuery, userID)
+	return err
+}
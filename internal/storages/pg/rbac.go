@@ -0,0 +1,86 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/kerim-dauren/user-service/pkg/postgresx"
+)
+
+type roleStorage struct {
+	db *postgresx.Postgres
+}
+
+func NewRoleStorage(db *postgresx.Postgres) domain.RoleStorage {
+	return &roleStorage{db: db}
+}
+
+const (
+	selectRolesQuery = `SELECT r.id, r.name, r.description, r.created_at,
+		COALESCE(array_agg(rp.permission ORDER BY rp.permission) FILTER (WHERE rp.permission IS NOT NULL), '{}')
+		FROM roles r LEFT JOIN role_permissions rp ON rp.role_id = r.id`
+	listRolesQuery     = selectRolesQuery + ` GROUP BY r.id ORDER BY r.name`
+	getRoleByNameQuery = selectRolesQuery + ` WHERE r.name=$1 GROUP BY r.id`
+	listUserRolesQuery = selectRolesQuery + ` JOIN user_roles ur ON ur.role_id = r.id
+		WHERE ur.user_id=$1 GROUP BY r.id ORDER BY r.name`
+	assignRoleQuery = `INSERT INTO user_roles (user_id, role_id, assigned_at) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, role_id) DO NOTHING`
+	revokeRoleQuery = `DELETE FROM user_roles WHERE user_id=$1 AND role_id=$2`
+)
+
+func (r *roleStorage) ListRoles(ctx context.Context) ([]*domain.Role, error) {
+	rows, err := r.db.Pool.Query(ctx, listRolesQuery)
+	if err != nil {
+		return nil, err
+	}
+	return scanRoles(rows)
+}
+
+func (r *roleStorage) GetRoleByName(ctx context.Context, name string) (*domain.Role, error) {
+	var role domain.Role
+	err := r.db.Pool.QueryRow(ctx, getRoleByNameQuery, name).
+		Scan(&role.ID, &role.Name, &role.Description, &role.CreatedAt, &role.Permissions)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrRoleNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *roleStorage) ListUserRoles(ctx context.Context, userID int64) ([]*domain.Role, error) {
+	rows, err := r.db.Pool.Query(ctx, listUserRolesQuery, userID)
+	if err != nil {
+		return nil, err
+	}
+	return scanRoles(rows)
+}
+
+func (r *roleStorage) AssignRole(ctx context.Context, userID, roleID int64, assignedAt time.Time) error {
+	_, err := r.db.Pool.Exec(ctx, assignRoleQuery, userID, roleID, assignedAt)
+	return err
+}
+
+func (r *roleStorage) RevokeRole(ctx context.Context, userID, roleID int64) error {
+	_, err := r.db.Pool.Exec(ctx, revokeRoleQuery, userID, roleID)
+	return err
+}
+
+func scanRoles(rows pgx.Rows) ([]*domain.Role, error) {
+	defer rows.Close()
+
+	var roles []*domain.Role
+	for rows.Next() {
+		var role domain.Role
+		if err := rows.Scan(&role.ID, &role.Name, &role.Description, &role.CreatedAt, &role.Permissions); err != nil {
+			return nil, err
+		}
+		roles = append(roles, &role)
+	}
+	return roles, rows.Err()
+}
@@ -0,0 +1,68 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/kerim-dauren/user-service/pkg/postgresx"
+)
+
+type refreshTokenStorage struct {
+	db *postgresx.Postgres
+}
+
+func NewRefreshTokenStorage(db *postgresx.Postgres) domain.RefreshTokenStorage {
+	return &refreshTokenStorage{db: db}
+}
+
+const (
+	createRefreshTokenQuery = `INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id`
+	getRefreshTokenByHashQuery = `SELECT id, user_id, family_id, token_hash, expires_at, used_at, revoked_at, created_at
+		FROM refresh_tokens WHERE token_hash=$1`
+	markRefreshTokenUsedQuery = `UPDATE refresh_tokens SET used_at=$1
+		WHERE id=$2 AND used_at IS NULL AND revoked_at IS NULL`
+	revokeRefreshTokenFamilyQuery = `UPDATE refresh_tokens SET revoked_at=$1 WHERE family_id=$2 AND revoked_at IS NULL`
+	revokeUserRefreshTokensQuery  = `UPDATE refresh_tokens SET revoked_at=$1 WHERE user_id=$2 AND revoked_at IS NULL`
+)
+
+func (r *refreshTokenStorage) CreateRefreshToken(ctx context.Context, t *domain.RefreshToken) (int64, error) {
+	var id int64
+	err := r.db.Pool.QueryRow(ctx, createRefreshTokenQuery,
+		t.UserID, t.FamilyID, t.TokenHash, t.ExpiresAt, time.Now()).Scan(&id)
+	return id, err
+}
+
+func (r *refreshTokenStorage) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	var t domain.RefreshToken
+	err := r.db.Pool.QueryRow(ctx, getRefreshTokenByHashQuery, tokenHash).Scan(
+		&t.ID, &t.UserID, &t.FamilyID, &t.TokenHash, &t.ExpiresAt, &t.UsedAt, &t.RevokedAt, &t.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrRefreshTokenNotFound
+	}
+	return &t, err
+}
+
+func (r *refreshTokenStorage) MarkRefreshTokenUsed(ctx context.Context, id int64) error {
+	tag, err := r.db.Pool.Exec(ctx, markRefreshTokenUsedQuery, time.Now(), id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrRefreshTokenReused
+	}
+	return nil
+}
+
+func (r *refreshTokenStorage) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	_, err := r.db.Pool.Exec(ctx, revokeRefreshTokenFamilyQuery, time.Now(), familyID)
+	return err
+}
+
+func (r *refreshTokenStorage) RevokeUserRefreshTokens(ctx context.Context, userID int64) error {
+	_, err := r.db.Pool.Exec(ctx, revokeUserRefreshTokensQuery, time.Now(), userID)
+	return err
+}
@@ -0,0 +1,94 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kerim-dauren/user-service/internal/domain"
+	"github.com/kerim-dauren/user-service/pkg/postgresx"
+)
+
+type sessionStorage struct {
+	db *postgresx.Postgres
+}
+
+func NewSessionStorage(db *postgresx.Postgres) domain.SessionStorage {
+	return &sessionStorage{db: db}
+}
+
+const (
+	sessionColumns      = `id, user_id, organization_id, device, ip, user_agent, created_at, last_seen_at, revoked_at`
+	createSessionQuery  = `INSERT INTO sessions (id, user_id, organization_id, device, ip, user_agent, created_at, last_seen_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $7)`
+	getSessionQuery     = `SELECT ` + sessionColumns + ` FROM sessions WHERE id=$1`
+	listSessionsQuery   = `SELECT ` + sessionColumns + ` FROM sessions WHERE user_id=$1 AND revoked_at IS NULL ORDER BY last_seen_at DESC`
+	touchSessionQuery   = `UPDATE sessions SET ip=$1, last_seen_at=$2 WHERE id=$3`
+	revokeSessionQuery  = `UPDATE sessions SET revoked_at=$1 WHERE id=$2 AND user_id=$3 AND revoked_at IS NULL`
+	revokeSessionsQuery = `UPDATE sessions SET revoked_at=$1 WHERE user_id=$2 AND id != $3 AND revoked_at IS NULL RETURNING id`
+)
+
+func (r *sessionStorage) CreateSession(ctx context.Context, s *domain.Session) error {
+	_, err := r.db.Pool.Exec(ctx, createSessionQuery, s.ID, s.UserID, s.OrganizationID, s.Device, s.IP, s.UserAgent, time.Now())
+	return err
+}
+
+func (r *sessionStorage) GetSession(ctx context.Context, id string) (*domain.Session, error) {
+	s, err := scanSession(r.db.Pool.QueryRow(ctx, getSessionQuery, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrSessionNotFound
+	}
+	return s, err
+}
+
+func (r *sessionStorage) ListUserSessions(ctx context.Context, userID int64) ([]*domain.Session, error) {
+	rows, err := r.db.Pool.Query(ctx, listSessionsQuery, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []*domain.Session
+	for rows.Next() {
+		s, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
+
+func (r *sessionStorage) TouchSession(ctx context.Context, id string, ip string, lastSeenAt time.Time) error {
+	_, err := r.db.Pool.Exec(ctx, touchSessionQuery, ip, lastSeenAt, id)
+	return err
+}
+
+func (r *sessionStorage) RevokeSession(ctx context.Context, userID int64, id string) error {
+	tag, err := r.db.Pool.Exec(ctx, revokeSessionQuery, time.Now(), id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrSessionNotFound
+	}
+	return nil
+}
+
+func (r *sessionStorage) RevokeUserSessions(ctx context.Context, userID int64, exceptID string) ([]string, error) {
+	rows, err := r.db.Pool.Query(ctx, revokeSessionsQuery, time.Now(), userID, exceptID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+func scanSession(row pgx.Row) (*domain.Session, error) {
+	var s domain.Session
+	err := row.Scan(&s.ID, &s.UserID, &s.OrganizationID, &s.Device, &s.IP, &s.UserAgent, &s.CreatedAt, &s.LastSeenAt, &s.RevokedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
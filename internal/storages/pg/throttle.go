@@ -0,0 +1,18 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// lockSendLimitQuery takes a lock on one recipient of one kind of mail until the transaction
+// ends. Without it, concurrent requests would all count the same recent mails before any of
+// them inserts its own, and together exceed the send limit.
+const lockSendLimitQuery = `SELECT pg_advisory_xact_lock(hashtext($1))`
+
+func lockSendLimit(ctx context.Context, tx pgx.Tx, kind string, recipient any) error {
+	_, err := tx.Exec(ctx, lockSendLimitQuery, fmt.Sprintf("%s:%v", kind, recipient))
+	return err
+}
@@ -4,8 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+
 	"github.com/kerim-dauren/user-service/internal/domain"
 	"github.com/kerim-dauren/user-service/pkg/postgresx"
 )
@@ -18,25 +22,80 @@ func NewUserStorage(db *postgresx.Postgres) domain.UserStorage {
 	return &userStorage{db: db}
 }
 
+// Users are read and changed through their membership in the organization of the request,
+// passed as organization_id; accounts of other organizations are out of reach.
 const (
-	createUserQuery  = `INSERT INTO users (username, email, password, created_at) VALUES ($1, $2, $3, $4) RETURNING id`
-	getUserByIDQuery = `SELECT id, username, email, password FROM users WHERE id=$1`
-	updateUserQuery  = `UPDATE users SET username=$1, email=$2, password=$3, updated_at=$4 WHERE id=$5`
-	deleteUserQuery  = `DELETE FROM users WHERE id=$1`
+	selectUserQuery = `SELECT u.id, u.username, u.email, u.password, u.email_verified_at, u.created_at, m.organization_id, m.role
+		FROM users u JOIN organization_members m ON m.user_id = u.id`
+	createUserQuery = `WITH created AS (
+			INSERT INTO users (username, email, password, created_at) VALUES ($2, $3, $4, $5) RETURNING id
+		)
+		INSERT INTO organization_members (organization_id, user_id, role, joined_at)
+		SELECT $1, id, $6, $5 FROM created RETURNING user_id`
+	getUserByIDQuery = selectUserQuery + ` WHERE m.organization_id=$1 AND u.id=$2`
+	// A login or an email names one account, read through its membership in the organization of
+	// the request if there is one and through the one it joined first otherwise.
+	inTenantFirst       = ` ORDER BY m.organization_id=$1 DESC, m.joined_at, m.organization_id LIMIT 1`
+	getUserByLoginQuery = selectUserQuery + ` WHERE (u.email=$2 OR u.username=$2)` +
+		` ORDER BY u.email=$2 DESC, m.organization_id=$1 DESC, m.joined_at, m.organization_id LIMIT 1`
+	getUserByEmailQuery = selectUserQuery + ` WHERE u.email=$2` + inTenantFirst
+	isMember            = `id IN (SELECT user_id FROM organization_members WHERE organization_id=`
+	// A CASE without ELSE yields NULL, so a new email starts out unverified.
+	updateUserQuery        = `UPDATE users SET username=$1, email=$2, email_verified_at=CASE WHEN email=$2 THEN email_verified_at END, updated_at=$3 WHERE id=$5 AND ` + isMember + `$4)`
+	updatePasswordQuery    = `UPDATE users SET password=$1, updated_at=$2 WHERE id=$4 AND ` + isMember + `$3)`
+	replacePasswordQuery   = `UPDATE users SET password=$1, updated_at=$2 WHERE id=$4 AND password=$5 AND ` + isMember + `$3)`
+	markEmailVerifiedQuery = `UPDATE users SET email_verified_at=$4, updated_at=$4 WHERE id=$2 AND email=$3 AND ` + isMember + `$1)`
+	// The account goes with its last membership. The statement sees the memberships from before
+	// the removal, hence the check for another organization.
+	deleteUserQuery = `WITH removed AS (
+			DELETE FROM organization_members WHERE organization_id=$1 AND user_id=$2 RETURNING user_id
+		)
+		DELETE FROM users WHERE id IN (SELECT user_id FROM removed)
+			AND NOT EXISTS (SELECT 1 FROM organization_members WHERE user_id=$2 AND organization_id != $1)`
 )
 
 func (r *userStorage) CreateUser(ctx context.Context, u *domain.User) (int64, error) {
+	if err := r.checkOrganizationExists(ctx, u.OrganizationID); err != nil {
+		return 0, err
+	}
 	if err := r.checkEmailExists(ctx, u.Email, 0); err != nil {
 		return 0, err
 	}
+	if err := r.checkUsernameExists(ctx, u.Username, 0); err != nil {
+		return 0, err
+	}
 	var id int64
-	err := r.db.Pool.QueryRow(ctx, createUserQuery, u.Username, u.Email, u.Password, time.Now()).Scan(&id)
+	err := r.db.Pool.QueryRow(ctx, createUserQuery, u.OrganizationID, u.Username, u.Email, u.Password, time.Now(), u.OrganizationRole).Scan(&id)
 	return id, err
 }
 
 func (r *userStorage) GetUserByID(ctx context.Context, id int64) (*domain.User, error) {
+	return r.getUser(ctx, getUserByIDQuery, domain.TenantFromContext(ctx), id)
+}
+
+func (r *userStorage) GetUserByLogin(ctx context.Context, login string) (*domain.User, error) {
+	return r.getUser(ctx, getUserByLoginQuery, domain.TenantFromContext(ctx), login)
+}
+
+func (r *userStorage) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
+	return r.getUser(ctx, getUserByEmailQuery, domain.TenantFromContext(ctx), email)
+}
+
+const getUserOrganizationIDQuery = `SELECT m.organization_id FROM organization_members m WHERE m.user_id=$2` + inTenantFirst
+
+func (r *userStorage) GetUserOrganizationID(ctx context.Context, id int64) (int64, error) {
+	var orgID int64
+	err := r.db.Pool.QueryRow(ctx, getUserOrganizationIDQuery, domain.TenantFromContext(ctx), id).Scan(&orgID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, domain.ErrUserNotFound
+	}
+	return orgID, err
+}
+
+func (r *userStorage) getUser(ctx context.Context, query string, args ...any) (*domain.User, error) {
 	var u domain.User
-	err := r.db.Pool.QueryRow(ctx, getUserByIDQuery, id).Scan(&u.ID, &u.Username, &u.Email, &u.Password)
+	err := r.db.Pool.QueryRow(ctx, query, args...).
+		Scan(&u.ID, &u.Username, &u.Email, &u.Password, &u.EmailVerifiedAt, &u.CreatedAt, &u.OrganizationID, &u.OrganizationRole)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, domain.ErrUserNotFound
 	}
@@ -47,15 +106,179 @@ func (r *userStorage) UpdateUser(ctx context.Context, u *domain.User) error {
 	if err := r.checkEmailExists(ctx, u.Email, u.ID); err != nil {
 		return err
 	}
-	_, err := r.db.Pool.Exec(ctx, updateUserQuery, u.Username, u.Email, u.Password, time.Now(), u.ID)
-	return err
+	if err := r.checkUsernameExists(ctx, u.Username, u.ID); err != nil {
+		return err
+	}
+	tag, err := r.db.Pool.Exec(ctx, updateUserQuery, u.Username, u.Email, time.Now(), domain.TenantFromContext(ctx), u.ID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *userStorage) PatchUser(ctx context.Context, id int64, patch *domain.UserPatch) error {
+	var (
+		sets []string
+		args []any
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	if patch.Username != nil {
+		if err := r.checkUsernameExists(ctx, *patch.Username, id); err != nil {
+			return err
+		}
+		sets = append(sets, `username=`+arg(*patch.Username))
+	}
+	if patch.Email != nil {
+		if err := r.checkEmailExists(ctx, *patch.Email, id); err != nil {
+			return err
+		}
+		email := arg(*patch.Email)
+		sets = append(sets, `email=`+email, `email_verified_at=CASE WHEN email=`+email+` THEN email_verified_at END`)
+	}
+	sets = append(sets, `updated_at=`+arg(time.Now()))
+
+	query := `UPDATE users SET ` + strings.Join(sets, `, `) +
+		` WHERE id=` + arg(id) + ` AND ` + isMember + arg(domain.TenantFromContext(ctx)) + `)`
+	tag, err := r.db.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *userStorage) UpdatePassword(ctx context.Context, id int64, passwordHash string) error {
+	tag, err := r.db.Pool.Exec(ctx, updatePasswordQuery, passwordHash, time.Now(), domain.TenantFromContext(ctx), id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *userStorage) ReplacePasswordHash(ctx context.Context, id int64, oldHash, newHash string) error {
+	tag, err := r.db.Pool.Exec(ctx, replacePasswordQuery, newHash, time.Now(), domain.TenantFromContext(ctx), id, oldHash)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *userStorage) MarkEmailVerified(ctx context.Context, id int64, email string, verifiedAt time.Time) error {
+	tag, err := r.db.Pool.Exec(ctx, markEmailVerifiedQuery, domain.TenantFromContext(ctx), id, email, verifiedAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
 }
 
 func (r *userStorage) DeleteUser(ctx context.Context, id int64) error {
-	_, err := r.db.Pool.Exec(ctx, deleteUserQuery, id)
+	_, err := r.db.Pool.Exec(ctx, deleteUserQuery, domain.TenantFromContext(ctx), id)
 	return err
 }
 
+const listUsersQuery = `SELECT u.id, u.username, u.email, u.email_verified_at, u.created_at, m.organization_id, m.role
+	FROM users u JOIN organization_members m ON m.user_id = u.id`
+
+// ListUsers builds a keyset query: the cursor condition compares the (sort column, id)
+// pair so that pages stay stable while rows are inserted or deleted.
+func (r *userStorage) ListUsers(ctx context.Context, q domain.UserQuery) ([]*domain.User, error) {
+	var (
+		conditions []string
+		args       []any
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	conditions = append(conditions, `m.organization_id = `+arg(domain.TenantFromContext(ctx)))
+	if q.Filter.EmailPrefix != "" {
+		conditions = append(conditions, `email LIKE `+arg(escapeLike(q.Filter.EmailPrefix)+"%"))
+	}
+	if q.Filter.UsernameContains != "" {
+		conditions = append(conditions, `username ILIKE `+arg("%"+escapeLike(q.Filter.UsernameContains)+"%"))
+	}
+	if !q.Filter.CreatedAfter.IsZero() {
+		conditions = append(conditions, `created_at >= `+arg(q.Filter.CreatedAfter))
+	}
+	if !q.Filter.CreatedBefore.IsZero() {
+		conditions = append(conditions, `created_at < `+arg(q.Filter.CreatedBefore))
+	}
+
+	direction, op := "ASC", ">"
+	if q.Desc {
+		direction, op = "DESC", "<"
+	}
+	// The sort column comes from a fixed set, never from the caller.
+	var column string
+	var cursorValue any
+	switch q.SortBy {
+	case domain.UserSortByCreatedAt:
+		column = "created_at"
+		if q.After != nil {
+			cursorValue = q.After.CreatedAt
+		}
+	case domain.UserSortByUsername:
+		column = "username"
+		if q.After != nil {
+			cursorValue = q.After.Username
+		}
+	case domain.UserSortByEmail:
+		column = "email"
+		if q.After != nil {
+			cursorValue = q.After.Email
+		}
+	}
+	if q.After != nil {
+		if column == "" {
+			conditions = append(conditions, `id `+op+` `+arg(q.After.ID))
+		} else {
+			conditions = append(conditions, `(`+column+`, id) `+op+` (`+arg(cursorValue)+`, `+arg(q.After.ID)+`)`)
+		}
+	}
+
+	sqlQuery := listUsersQuery + ` WHERE ` + strings.Join(conditions, ` AND `)
+	sqlQuery += ` ORDER BY `
+	if column != "" {
+		sqlQuery += column + ` ` + direction + `, `
+	}
+	sqlQuery += `id ` + direction + ` LIMIT ` + arg(q.Limit)
+
+	rows, err := r.db.Pool.Query(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*domain.User, error) {
+		var u domain.User
+		err := row.Scan(&u.ID, &u.Username, &u.Email, &u.EmailVerifiedAt, &u.CreatedAt, &u.OrganizationID, &u.OrganizationRole)
+		return &u, err
+	})
+}
+
+// escapeLike escapes the LIKE wildcards in s so that it matches literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// Usernames and emails are unique across organizations, so the checks look at every account.
 const checkEmailExistsQuery = `SELECT 1 FROM users WHERE email = $1 AND id != $2 LIMIT 1`
 
 func (r *userStorage) checkEmailExists(ctx context.Context, email string, userID int64) error {
@@ -68,3 +291,29 @@ func (r *userStorage) checkEmailExists(ctx context.Context, email string, userID
 	}
 	return domain.ErrUserMailAlreadyExists
 }
+
+const checkUsernameExistsQuery = `SELECT 1 FROM users WHERE username = $1 AND id != $2 LIMIT 1`
+
+func (r *userStorage) checkUsernameExists(ctx context.Context, username string, userID int64) error {
+	var exists int
+	if err := r.db.Pool.QueryRow(ctx, checkUsernameExistsQuery, username, userID).Scan(&exists); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+	return domain.ErrUsernameAlreadyExists
+}
+
+const checkOrganizationExistsQuery = `SELECT 1 FROM organizations WHERE id = $1`
+
+func (r *userStorage) checkOrganizationExists(ctx context.Context, orgID int64) error {
+	var exists int
+	if err := r.db.Pool.QueryRow(ctx, checkOrganizationExistsQuery, orgID).Scan(&exists); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrOrganizationNotFound
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+// Package breachx checks passwords against a local list of breached passwords.
+package breachx
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+type digest = [sha1.Size]byte
+
+// List is a sorted set of SHA-1 password digests, safe for concurrent use.
+type List struct {
+	digests []digest
+}
+
+// Load reads a list from the file at path, see Read.
+func Load(path string) (*List, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// Read reads one upper- or lowercase hex SHA-1 digest per line, optionally followed by
+// ":count" as in the Have I Been Pwned downloads. Blank lines and lines starting with # are skipped.
+func Read(r io.Reader) (*List, error) {
+	var digests []digest
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		hexDigest, _, _ := strings.Cut(text, ":")
+		var d digest
+		if len(hexDigest) != hex.EncodedLen(sha1.Size) {
+			return nil, fmt.Errorf("line %d: invalid SHA-1 digest", line)
+		}
+		if _, err := hex.Decode(d[:], []byte(hexDigest)); err != nil {
+			return nil, fmt.Errorf("line %d: invalid SHA-1 digest", line)
+		}
+		digests = append(digests, d)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(digests, func(a, b digest) int { return bytes.Compare(a[:], b[:]) })
+	return &List{digests: slices.Compact(digests)}, nil
+}
+
+// Contains reports whether password is on the list.
+func (l *List) Contains(password string) bool {
+	d := sha1.Sum([]byte(password))
+	_, found := slices.BinarySearchFunc(l.digests, d, func(a, b digest) int { return bytes.Compare(a[:], b[:]) })
+	return found
+}
+
+// Len returns the number of distinct passwords on the list.
+func (l *List) Len() int {
+	return len(l.digests)
+}
@@ -0,0 +1,49 @@
+package breachx
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// SHA-1 of "password" and "123456".
+const testList = `# breached passwords
+5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:9545824
+
+7c4a8d09ca3762af61e59520943dc26494f8941b
+5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:1
+`
+
+func TestRead(t *testing.T) {
+	list, err := Read(strings.NewReader(testList))
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, list.Len(), "duplicates are collapsed")
+	assert.True(t, list.Contains("password"))
+	assert.True(t, list.Contains("123456"))
+	assert.False(t, list.Contains("Password"))
+	assert.False(t, list.Contains("correct horse battery staple"))
+}
+
+func TestRead_InvalidLine(t *testing.T) {
+	for _, line := range []string{"not-hex", "5BAA61E4C9B93F3F0682250B6CF8331B7EE68F", "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8AA"} {
+		_, err := Read(strings.NewReader(line))
+		assert.ErrorContains(t, err, "line 1", line)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	require.NoError(t, os.WriteFile(path, []byte(testList), 0o600))
+
+	list, err := Load(path)
+	require.NoError(t, err)
+	assert.True(t, list.Contains("password"))
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.txt"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,75 @@
+// Package cryptox encrypts small secrets for storage.
+package cryptox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+const KeySize = 32
+
+var ErrDecrypt = errors.New("failed to decrypt")
+
+// Box encrypts and authenticates values with AES-256-GCM.
+// Additional data, such as the ID of the owning row, binds a ciphertext to its context
+// so that it cannot be copied to another row.
+type Box struct {
+	aead cipher.AEAD
+}
+
+// NewBox builds a Box from a base64-encoded 32-byte key.
+// An empty key generates an ephemeral one, which is only suitable for local development
+// since nothing encrypted with it can be decrypted after a restart.
+func NewBox(encodedKey string) (*Box, error) {
+	key := make([]byte, KeySize)
+	if encodedKey == "" {
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("failed to generate encryption key: %w", err)
+		}
+	} else {
+		var err error
+		key, err = base64.StdEncoding.DecodeString(encodedKey)
+		if err != nil || len(key) != KeySize {
+			return nil, fmt.Errorf("encryption key must be %d base64-encoded bytes", KeySize)
+		}
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Box{aead: aead}, nil
+}
+
+// Seal encrypts plaintext and returns the base64-encoded nonce and ciphertext.
+func (b *Box) Seal(plaintext, additionalData []byte) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := b.aead.Seal(nonce, nonce, plaintext, additionalData)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a value produced by Seal with the same additional data.
+// It returns ErrDecrypt for malformed or tampered values and for a different key.
+func (b *Box) Open(sealed string, additionalData []byte) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(data) < b.aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	nonce, ciphertext := data[:b.aead.NonceSize()], data[b.aead.NonceSize():]
+	plaintext, err := b.aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
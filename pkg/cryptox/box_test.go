@@ -0,0 +1,64 @@
+package cryptox
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestKey(t *testing.T) string {
+	t.Helper()
+	key := make([]byte, KeySize)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+func TestBox_SealOpen(t *testing.T) {
+	key := newTestKey(t)
+	box, err := NewBox(key)
+	require.NoError(t, err)
+
+	sealed, err := box.Seal([]byte("secret"), []byte("user:1"))
+	require.NoError(t, err)
+	assert.NotContains(t, sealed, "secret")
+
+	again, err := box.Seal([]byte("secret"), []byte("user:1"))
+	require.NoError(t, err)
+	assert.NotEqual(t, sealed, again, "every seal uses a fresh nonce")
+
+	reopened, err := NewBox(key)
+	require.NoError(t, err)
+	plaintext, err := reopened.Open(sealed, []byte("user:1"))
+	require.NoError(t, err)
+	assert.Equal(t, "secret", string(plaintext))
+}
+
+func TestBox_Open_Rejects(t *testing.T) {
+	box, err := NewBox(newTestKey(t))
+	require.NoError(t, err)
+	other, err := NewBox("")
+	require.NoError(t, err)
+
+	sealed, err := box.Seal([]byte("secret"), []byte("user:1"))
+	require.NoError(t, err)
+
+	_, err = box.Open(sealed, []byte("user:2"))
+	assert.ErrorIs(t, err, ErrDecrypt, "bound to its additional data")
+	_, err = other.Open(sealed, []byte("user:1"))
+	assert.ErrorIs(t, err, ErrDecrypt, "bound to its key")
+	_, err = box.Open("not base64!", nil)
+	assert.ErrorIs(t, err, ErrDecrypt)
+	_, err = box.Open(base64.StdEncoding.EncodeToString([]byte("short")), nil)
+	assert.ErrorIs(t, err, ErrDecrypt)
+}
+
+func TestNewBox_InvalidKey(t *testing.T) {
+	_, err := NewBox("c2hvcnQ=")
+	assert.Error(t, err)
+	_, err = NewBox("not base64!")
+	assert.Error(t, err)
+}
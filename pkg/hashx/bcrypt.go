@@ -0,0 +1,57 @@
+package hashx
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// BcryptMaxPasswordBytes is the longest password bcrypt hashes, in bytes.
+const BcryptMaxPasswordBytes = 72
+
+// ErrPasswordTooLong is returned by the bcrypt hasher for passwords longer than
+// BcryptMaxPasswordBytes.
+var ErrPasswordTooLong = errors.New("hashx: password too long for bcrypt")
+
+type bcryptHasher struct {
+	cost int
+}
+
+// NewBcryptHasher returns a bcrypt hasher. bcrypt rejects passwords longer than 72 bytes.
+func NewBcryptHasher(cost int) (Hasher, error) {
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+	return bcryptHasher{cost: cost}, nil
+}
+
+func (b bcryptHasher) Hash(password string) (string, error) {
+	if password == "" {
+		return "", fmt.Errorf("password cannot be empty")
+	}
+	if len(password) > BcryptMaxPasswordBytes {
+		return "", ErrPasswordTooLong
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), b.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+func (b bcryptHasher) NeedsRehash(hashedPassword string) bool {
+	cost, err := bcrypt.Cost([]byte(hashedPassword))
+	return err != nil || cost != b.cost
+}
+
+type bcryptChecker struct{}
+
+// NewBcryptChecker checks $2a$, $2b$ and $2y$ hashes.
+func NewBcryptChecker() Checker {
+	return bcryptChecker{}
+}
+
+func (bcryptChecker) CompareHashAndPassword(hashedPassword, password string) error {
+	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+}
@@ -1,10 +1,24 @@
 package hashx
 
+import "context"
+
 type (
 	Hasher interface {
 		Hash(password string) (string, error)
+		// NeedsRehash reports whether hashedPassword was made with another algorithm or
+		// other parameters than Hash uses now.
+		NeedsRehash(hashedPassword string) bool
 	}
 	Checker interface {
 		CompareHashAndPassword(hashedPassword, password string) error
 	}
+
+	// ContextHasher and ContextChecker may wait for capacity and give up when ctx ends; see Pool.
+	ContextHasher interface {
+		Hash(ctx context.Context, password string) (string, error)
+		NeedsRehash(hashedPassword string) bool
+	}
+	ContextChecker interface {
+		CompareHashAndPassword(ctx context.Context, hashedPassword, password string) error
+	}
 )
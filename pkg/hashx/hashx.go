@@ -0,0 +1,60 @@
+// Package hashx hashes and checks passwords with Argon2id, bcrypt or scrypt.
+package hashx
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+	AlgorithmScrypt   = "scrypt"
+)
+
+// Config selects the algorithm new hashes are made with and its parameters.
+type Config struct {
+	Algorithm  string // "argon2id", "bcrypt" or "scrypt"
+	Argon2     Argon2Params
+	BcryptCost int
+	Scrypt     ScryptParams
+}
+
+// NewHasher builds the hasher for cfg.Algorithm.
+func NewHasher(cfg Config) (Hasher, error) {
+	switch cfg.Algorithm {
+	case AlgorithmArgon2id:
+		return NewArgon2HasherWithParams(cfg.Argon2)
+	case AlgorithmBcrypt:
+		return NewBcryptHasher(cfg.BcryptCost)
+	case AlgorithmScrypt:
+		return NewScryptHasher(cfg.Scrypt)
+	default:
+		return nil, fmt.Errorf("unsupported hash algorithm: %s", cfg.Algorithm)
+	}
+}
+
+type checker struct {
+	argon2 Checker
+	bcrypt Checker
+	scrypt Checker
+}
+
+// NewChecker returns a Checker for hashes of every supported algorithm, selected by
+// the prefix of the hash: $argon2id$, $2a$, $2b$, $2y$ or $scrypt$.
+func NewChecker() Checker {
+	return checker{argon2: NewArgon2HashChecker(), bcrypt: NewBcryptChecker(), scrypt: NewScryptChecker()}
+}
+
+func (c checker) CompareHashAndPassword(hashedPassword, password string) error {
+	switch {
+	case strings.HasPrefix(hashedPassword, argon2Prefix):
+		return c.argon2.CompareHashAndPassword(hashedPassword, password)
+	case strings.HasPrefix(hashedPassword, "$2a$"), strings.HasPrefix(hashedPassword, "$2b$"), strings.HasPrefix(hashedPassword, "$2y$"):
+		return c.bcrypt.CompareHashAndPassword(hashedPassword, password)
+	case strings.HasPrefix(hashedPassword, scryptPrefix):
+		return c.scrypt.CompareHashAndPassword(hashedPassword, password)
+	default:
+		return fmt.Errorf("unsupported hash format")
+	}
+}
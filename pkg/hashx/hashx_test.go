@@ -0,0 +1,127 @@
+package hashx_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kerim-dauren/user-service/pkg/hashx"
+)
+
+func testConfigs() map[string]hashx.Config {
+	argon2 := hashx.Argon2Params{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+	scrypt := hashx.ScryptParams{LogN: 4, R: 8, P: 1, SaltLength: 16, KeyLength: 32}
+	return map[string]hashx.Config{
+		"$argon2id$": {Algorithm: hashx.AlgorithmArgon2id, Argon2: argon2},
+		"$2a$":       {Algorithm: hashx.AlgorithmBcrypt, BcryptCost: 4},
+		"$scrypt$":   {Algorithm: hashx.AlgorithmScrypt, Scrypt: scrypt},
+	}
+}
+
+func TestNewHasher(t *testing.T) {
+	checker := hashx.NewChecker()
+
+	for prefix, cfg := range testConfigs() {
+		t.Run(cfg.Algorithm, func(t *testing.T) {
+			hasher, err := hashx.NewHasher(cfg)
+			if err != nil {
+				t.Fatalf("failed to create hasher: %v", err)
+			}
+
+			hashedPassword, err := hasher.Hash("securepassword123")
+			if err != nil {
+				t.Fatalf("failed to hash password: %v", err)
+			}
+			if !strings.HasPrefix(hashedPassword, prefix) {
+				t.Errorf("expected a %s hash, got %s", prefix, hashedPassword)
+			}
+			if err = checker.CompareHashAndPassword(hashedPassword, "securepassword123"); err != nil {
+				t.Errorf("failed to compare password: %v", err)
+			}
+			if err = checker.CompareHashAndPassword(hashedPassword, "wrongpassword"); err == nil {
+				t.Error("expected an error for a wrong password, but got none")
+			}
+			if hasher.NeedsRehash(hashedPassword) {
+				t.Error("a hash with the current parameters does not need a rehash")
+			}
+			if _, err = hasher.Hash(""); err == nil {
+				t.Error("expected an error for empty password, but got none")
+			}
+		})
+	}
+}
+
+func TestBcryptHasher_PasswordTooLong(t *testing.T) {
+	hasher, err := hashx.NewBcryptHasher(4)
+	if err != nil {
+		t.Fatalf("failed to create hasher: %v", err)
+	}
+	if _, err = hasher.Hash(strings.Repeat("п", 40)); !errors.Is(err, hashx.ErrPasswordTooLong) {
+		t.Errorf("expected ErrPasswordTooLong for 80 bytes, got %v", err)
+	}
+}
+
+func TestNewHasher_Invalid(t *testing.T) {
+	configs := []hashx.Config{
+		{Algorithm: "md5"},
+		{Algorithm: hashx.AlgorithmArgon2id, Argon2: hashx.Argon2Params{Memory: 64, Iterations: 0, Parallelism: 1, SaltLength: 16, KeyLength: 32}},
+		{Algorithm: hashx.AlgorithmBcrypt, BcryptCost: 32},
+		{Algorithm: hashx.AlgorithmScrypt, Scrypt: hashx.ScryptParams{LogN: 0, R: 8, P: 1, SaltLength: 16, KeyLength: 32}},
+	}
+	for _, cfg := range configs {
+		if _, err := hashx.NewHasher(cfg); err == nil {
+			t.Errorf("expected an error for %+v, but got none", cfg)
+		}
+	}
+}
+
+func TestHasher_NeedsRehash(t *testing.T) {
+	configs := testConfigs()
+	hashes := map[string]string{}
+	for prefix, cfg := range configs {
+		hasher, err := hashx.NewHasher(cfg)
+		if err != nil {
+			t.Fatalf("failed to create hasher: %v", err)
+		}
+		if hashes[prefix], err = hasher.Hash("securepassword123"); err != nil {
+			t.Fatalf("failed to hash password: %v", err)
+		}
+	}
+
+	stronger := map[string]hashx.Config{}
+	for prefix, cfg := range configs {
+		cfg.Argon2.Iterations++
+		cfg.BcryptCost++
+		cfg.Scrypt.LogN++
+		stronger[prefix] = cfg
+	}
+
+	for prefix, cfg := range stronger {
+		hasher, _ := hashx.NewHasher(cfg)
+		for hashPrefix, hashedPassword := range hashes {
+			if !hasher.NeedsRehash(hashedPassword) {
+				t.Errorf("%s hasher: expected %s hash to need a rehash", prefix, hashPrefix)
+			}
+		}
+	}
+}
+
+func TestChecker_BcryptVersions(t *testing.T) {
+	hasher, _ := hashx.NewBcryptHasher(4)
+	checker := hashx.NewChecker()
+	hashedPassword, err := hasher.Hash("password")
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+
+	// $2b$ and $2y$ differ from $2a$ only in how other implementations treated edge cases.
+	for _, prefix := range []string{"$2b$", "$2y$"} {
+		if err = checker.CompareHashAndPassword(prefix+hashedPassword[4:], "password"); err != nil {
+			t.Errorf("%s: failed to compare password: %v", prefix, err)
+		}
+	}
+
+	if err = checker.CompareHashAndPassword("$1$salt$hash", "password"); err == nil {
+		t.Error("expected an error for an unsupported hash, but got none")
+	}
+}
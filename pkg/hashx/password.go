@@ -9,10 +9,45 @@ import (
 	"strings"
 )
 
-type argon2Hasher struct{}
+const argon2Prefix = "$argon2id$"
+
+// Argon2Params are the Argon2id cost parameters.
+type Argon2Params struct {
+	Memory      uint32 // KiB
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2Params returns 32 MB of memory, one iteration and one thread per CPU, at most 8.
+func DefaultArgon2Params() Argon2Params {
+	return Argon2Params{
+		Memory:      32 * 1024,
+		Iterations:  1,
+		Parallelism: getMaxCPUCores(8),
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+}
+
+type argon2Hasher struct {
+	params Argon2Params
+}
 
 func NewArgon2Hasher() Hasher {
-	return argon2Hasher{}
+	return argon2Hasher{params: DefaultArgon2Params()}
+}
+
+// NewArgon2HasherWithParams returns an Argon2id hasher using params.
+func NewArgon2HasherWithParams(params Argon2Params) (Hasher, error) {
+	if params.Memory < 8*uint32(params.Parallelism) || params.Iterations < 1 || params.Parallelism < 1 {
+		return nil, fmt.Errorf("invalid argon2 parameters: m=%d,t=%d,p=%d", params.Memory, params.Iterations, params.Parallelism)
+	}
+	if params.SaltLength < 8 || params.KeyLength < 16 {
+		return nil, fmt.Errorf("argon2 salt and key must be at least 8 and 16 bytes")
+	}
+	return argon2Hasher{params: params}, nil
 }
 
 func (a argon2Hasher) Hash(password string) (string, error) {
@@ -20,26 +55,31 @@ func (a argon2Hasher) Hash(password string) (string, error) {
 		return "", fmt.Errorf("password cannot be empty")
 	}
 
-	salt := make([]byte, 16)
+	salt := make([]byte, a.params.SaltLength)
 	_, err := rand.Read(salt)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate salt: %w", err)
 	}
 
-	// Argon2id params
-	threads := getMaxCPUCores(8) // Limit the number of threads
-	memory := uint32(32 * 1024)  // 32 MB of memory
-	time := uint32(1)            // 1 iteration
-	keyLen := uint32(32)         // Key length of 32 bytes
-	hash := argon2.IDKey([]byte(password), salt, time, memory, threads, keyLen)
+	p := a.params
+	hash := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
 
 	b64Salt := base64.RawStdEncoding.EncodeToString(salt)
 	b64Hash := base64.RawStdEncoding.EncodeToString(hash)
 
-	result := fmt.Sprintf("$argon2id$v=19$m=%d,t=%d,p=%d$%s$%s", memory, time, threads, b64Salt, b64Hash)
+	result := fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, p.Memory, p.Iterations, p.Parallelism, b64Salt, b64Hash)
 	return result, nil
 }
 
+func (a argon2Hasher) NeedsRehash(hashedPassword string) bool {
+	h, err := parseArgon2(hashedPassword)
+	if err != nil {
+		return true
+	}
+	return h.memory != a.params.Memory || h.time != a.params.Iterations || h.threads != a.params.Parallelism ||
+		len(h.salt) != int(a.params.SaltLength) || len(h.hash) != int(a.params.KeyLength)
+}
+
 type argon2HashChecker struct{}
 
 func NewArgon2HashChecker() Checker {
@@ -47,37 +87,49 @@ func NewArgon2HashChecker() Checker {
 }
 
 func (a argon2HashChecker) CompareHashAndPassword(hashedPassword, password string) error {
+	h, err := parseArgon2(hashedPassword)
+	if err != nil {
+		return err
+	}
+
+	// Generate a hash with the same parameters
+	calculatedHash := argon2.IDKey([]byte(password), h.salt, h.time, h.memory, h.threads, uint32(len(h.hash)))
+
+	if isValid := subtleCompare(h.hash, calculatedHash); !isValid {
+		return fmt.Errorf("passwords do not match")
+	}
+	return nil
+}
+
+type argon2Hash struct {
+	memory, time uint32
+	threads      uint8
+	salt, hash   []byte
+}
+
+func parseArgon2(hashedPassword string) (*argon2Hash, error) {
 	parts := strings.Split(hashedPassword, "$")
-	if len(parts) != 6 {
-		return fmt.Errorf("invalid hash format")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, fmt.Errorf("invalid hash format")
 	}
 
 	// read the params
-	var memory, time uint32
-	var threads uint8
-	_, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads)
+	var h argon2Hash
+	_, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &h.memory, &h.time, &h.threads)
 	if err != nil {
-		return fmt.Errorf("failed to parse parameters: %w", err)
+		return nil, fmt.Errorf("failed to parse parameters: %w", err)
 	}
 
 	// Decode the salt and hash
-	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	h.salt, err = base64.RawStdEncoding.DecodeString(parts[4])
 	if err != nil {
-		return fmt.Errorf("failed to decode salt: %w", err)
+		return nil, fmt.Errorf("failed to decode salt: %w", err)
 	}
-	expectedHash, err := base64.RawStdEncoding.DecodeString(parts[5])
+	h.hash, err = base64.RawStdEncoding.DecodeString(parts[5])
 	if err != nil {
-		return fmt.Errorf("failed to decode hash: %w", err)
-	}
-
-	// Generate a hash with the same parameters
-	keyLen := uint32(len(expectedHash))
-	calculatedHash := argon2.IDKey([]byte(password), salt, time, memory, threads, keyLen)
-
-	if isValid := subtleCompare(expectedHash, calculatedHash); !isValid {
-		return fmt.Errorf("passwords do not match")
+		return nil, fmt.Errorf("failed to decode hash: %w", err)
 	}
-	return nil
+	return &h, nil
 }
 
 func subtleCompare(a, b []byte) bool {
@@ -0,0 +1,168 @@
+package hashx
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const (
+	pepperPrefix = "$pepper$"
+	// PepperKeySize is the minimum size of a pepper key in bytes.
+	PepperKeySize = 32
+)
+
+var pepperKeyID = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,32}$`)
+
+// Pepper holds the secret HMAC keys mixed into passwords before hashing, so that
+// hashes leaked without the keys cannot be cracked. New hashes use the current key;
+// older keys are kept to check the hashes made with them until they are rehashed.
+type Pepper struct {
+	current string
+	keys    map[string][]byte
+}
+
+// NewPepper builds a Pepper from keys by ID. The current key may be empty when there is a single key.
+func NewPepper(current string, keys map[string][]byte) (*Pepper, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no pepper keys")
+	}
+	for id, key := range keys {
+		if !pepperKeyID.MatchString(id) {
+			return nil, fmt.Errorf("invalid pepper key ID %q", id)
+		}
+		if len(key) < PepperKeySize {
+			return nil, fmt.Errorf("pepper key %q must be at least %d bytes", id, PepperKeySize)
+		}
+		if current == "" && len(keys) == 1 {
+			current = id
+		}
+	}
+	if _, ok := keys[current]; !ok {
+		return nil, fmt.Errorf("current pepper key %q is not among the keys", current)
+	}
+	return &Pepper{current: current, keys: keys}, nil
+}
+
+// ParsePepperKeys reads "id:base64key" entries separated by commas or newlines.
+// Blank entries and lines starting with # are skipped.
+func ParsePepperKeys(spec string) (map[string][]byte, error) {
+	keys := map[string][]byte{}
+	scanner := bufio.NewScanner(strings.NewReader(strings.ReplaceAll(spec, ",", "\n")))
+	for scanner.Scan() {
+		entry := strings.TrimSpace(scanner.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		id, encodedKey, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("pepper key entries must be id:base64key")
+		}
+		key, err := base64.StdEncoding.DecodeString(encodedKey)
+		if err != nil {
+			return nil, fmt.Errorf("pepper key %q is not valid base64", id)
+		}
+		if _, ok = keys[id]; ok {
+			return nil, fmt.Errorf("duplicate pepper key %q", id)
+		}
+		keys[id] = key
+	}
+	return keys, scanner.Err()
+}
+
+// LoadPepperKeys reads a secrets file in the ParsePepperKeys format.
+func LoadPepperKeys(path string) (map[string][]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePepperKeys(string(data))
+}
+
+// apply returns the HMAC of password, encoded so that hashers which stop at a NUL byte or
+// at 72 bytes, such as bcrypt, see all of it.
+func (p *Pepper) apply(keyID, password string) (string, error) {
+	key, ok := p.keys[keyID]
+	if !ok {
+		return "", fmt.Errorf("unknown pepper key %q", keyID)
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(password))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// splitPeppered splits $pepper$kid=<id>$<inner hash> into the key ID and the inner hash.
+func splitPeppered(hashedPassword string) (keyID, inner string, ok bool) {
+	rest, ok := strings.CutPrefix(hashedPassword, pepperPrefix)
+	if !ok {
+		return "", "", false
+	}
+	param, inner, ok := strings.Cut(rest, "$")
+	if !ok {
+		return "", "", false
+	}
+	keyID, ok = strings.CutPrefix(param, "kid=")
+	return keyID, "$" + inner, ok
+}
+
+type pepperedHasher struct {
+	pepper *Pepper
+	inner  Hasher
+}
+
+// NewPepperedHasher peppers passwords with the current key before hashing them with inner.
+func NewPepperedHasher(pepper *Pepper, inner Hasher) Hasher {
+	return pepperedHasher{pepper: pepper, inner: inner}
+}
+
+func (h pepperedHasher) Hash(password string) (string, error) {
+	if password == "" {
+		return "", fmt.Errorf("password cannot be empty")
+	}
+	peppered, err := h.pepper.apply(h.pepper.current, password)
+	if err != nil {
+		return "", err
+	}
+	hash, err := h.inner.Hash(peppered)
+	if err != nil {
+		return "", err
+	}
+	return pepperPrefix + "kid=" + h.pepper.current + hash, nil
+}
+
+// NeedsRehash also reports hashes without pepper and those peppered with an older key.
+func (h pepperedHasher) NeedsRehash(hashedPassword string) bool {
+	keyID, inner, ok := splitPeppered(hashedPassword)
+	return !ok || keyID != h.pepper.current || h.inner.NeedsRehash(inner)
+}
+
+type pepperedChecker struct {
+	pepper *Pepper
+	inner  Checker
+}
+
+// NewPepperedChecker checks peppered hashes with the key they name, and hashes
+// made before the pepper was introduced as they are.
+func NewPepperedChecker(pepper *Pepper, inner Checker) Checker {
+	return pepperedChecker{pepper: pepper, inner: inner}
+}
+
+func (c pepperedChecker) CompareHashAndPassword(hashedPassword, password string) error {
+	if !strings.HasPrefix(hashedPassword, pepperPrefix) {
+		return c.inner.CompareHashAndPassword(hashedPassword, password)
+	}
+	keyID, inner, ok := splitPeppered(hashedPassword)
+	if !ok {
+		return fmt.Errorf("invalid hash format")
+	}
+	peppered, err := c.pepper.apply(keyID, password)
+	if err != nil {
+		return err
+	}
+	return c.inner.CompareHashAndPassword(inner, peppered)
+}
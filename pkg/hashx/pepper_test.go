@@ -0,0 +1,123 @@
+package hashx_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kerim-dauren/user-service/pkg/hashx"
+)
+
+func pepperKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, hashx.PepperKeySize)
+}
+
+func newTestPepper(t *testing.T, current string) *hashx.Pepper {
+	t.Helper()
+	pepper, err := hashx.NewPepper(current, map[string][]byte{"k1": pepperKey(1), "k2": pepperKey(2)})
+	if err != nil {
+		t.Fatalf("failed to create pepper: %v", err)
+	}
+	return pepper
+}
+
+func TestPepperedHasher(t *testing.T) {
+	bcrypt, _ := hashx.NewBcryptHasher(4)
+	hasher := hashx.NewPepperedHasher(newTestPepper(t, "k1"), bcrypt)
+	checker := hashx.NewPepperedChecker(newTestPepper(t, "k1"), hashx.NewChecker())
+
+	hashedPassword, err := hasher.Hash("securepassword123")
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+	if !strings.HasPrefix(hashedPassword, "$pepper$kid=k1$2a$") {
+		t.Errorf("expected the key ID before the bcrypt hash, got %s", hashedPassword)
+	}
+	if err = checker.CompareHashAndPassword(hashedPassword, "securepassword123"); err != nil {
+		t.Errorf("failed to compare password: %v", err)
+	}
+	if err = checker.CompareHashAndPassword(hashedPassword, "wrongpassword"); err == nil {
+		t.Error("expected an error for a wrong password, but got none")
+	}
+	if hasher.NeedsRehash(hashedPassword) {
+		t.Error("a hash with the current key does not need a rehash")
+	}
+
+	if err = hashx.NewChecker().CompareHashAndPassword(hashedPassword, "securepassword123"); err == nil {
+		t.Error("a peppered hash cannot be checked without the pepper")
+	}
+	stripped := strings.TrimPrefix(hashedPassword, "$pepper$kid=k1")
+	if err = checker.CompareHashAndPassword(stripped, "securepassword123"); err == nil {
+		t.Error("the inner hash alone does not match the password")
+	}
+}
+
+func TestPepperedHasher_Rotation(t *testing.T) {
+	argon2, _ := hashx.NewArgon2HasherWithParams(hashx.Argon2Params{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32})
+	oldHasher := hashx.NewPepperedHasher(newTestPepper(t, "k1"), argon2)
+	newHasher := hashx.NewPepperedHasher(newTestPepper(t, "k2"), argon2)
+	checker := hashx.NewPepperedChecker(newTestPepper(t, "k2"), hashx.NewChecker())
+
+	legacy, _ := argon2.Hash("securepassword123")
+	old, _ := oldHasher.Hash("securepassword123")
+	current, _ := newHasher.Hash("securepassword123")
+
+	for name, hashedPassword := range map[string]string{"legacy": legacy, "old key": old, "current key": current} {
+		if err := checker.CompareHashAndPassword(hashedPassword, "securepassword123"); err != nil {
+			t.Errorf("%s: failed to compare password: %v", name, err)
+		}
+	}
+	if !newHasher.NeedsRehash(legacy) || !newHasher.NeedsRehash(old) || newHasher.NeedsRehash(current) {
+		t.Error("only hashes with the current key are up to date")
+	}
+
+	retired, _ := hashx.NewPepper("", map[string][]byte{"k2": pepperKey(2)})
+	if err := hashx.NewPepperedChecker(retired, hashx.NewChecker()).CompareHashAndPassword(old, "securepassword123"); err == nil {
+		t.Error("expected an error for a hash with a removed key, but got none")
+	}
+}
+
+func TestNewPepper_Invalid(t *testing.T) {
+	tests := map[string]struct {
+		current string
+		keys    map[string][]byte
+	}{
+		"NoKeys":         {keys: nil},
+		"ShortKey":       {keys: map[string][]byte{"k1": []byte("short")}},
+		"BadID":          {keys: map[string][]byte{"k$1": pepperKey(1)}},
+		"UnknownCurrent": {current: "k3", keys: map[string][]byte{"k1": pepperKey(1)}},
+		"NoCurrent":      {keys: map[string][]byte{"k1": pepperKey(1), "k2": pepperKey(2)}},
+	}
+	for name, tt := range tests {
+		if _, err := hashx.NewPepper(tt.current, tt.keys); err == nil {
+			t.Errorf("%s: expected an error, but got none", name)
+		}
+	}
+}
+
+func TestLoadPepperKeys(t *testing.T) {
+	k1 := base64.StdEncoding.EncodeToString(pepperKey(1))
+	k2 := base64.StdEncoding.EncodeToString(pepperKey(2))
+
+	keys, err := hashx.ParsePepperKeys("k1:" + k1 + ",k2:" + k2)
+	if err != nil || len(keys) != 2 || !bytes.Equal(keys["k2"], pepperKey(2)) {
+		t.Errorf("failed to parse pepper keys: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "pepper")
+	if err = os.WriteFile(path, []byte("# rotated 2026-01\nk1:"+k1+"\n\nk2:"+k2+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if keys, err = hashx.LoadPepperKeys(path); err != nil || len(keys) != 2 {
+		t.Errorf("failed to load pepper keys: %v", err)
+	}
+
+	for _, spec := range []string{"k1", "k1:not base64!", "k1:" + k1 + ",k1:" + k2} {
+		if _, err = hashx.ParsePepperKeys(spec); err == nil {
+			t.Errorf("expected an error for %q, but got none", spec)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package hashx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ErrBusy is returned when the hashing queue is full and the call is shed without waiting.
+var ErrBusy = errors.New("hashx: too many passwords waiting to be hashed")
+
+// PoolConfig bounds the hashing done at once and the calls allowed to wait for it.
+type PoolConfig struct {
+	Workers   int // Hashes computed at once, each costing the memory of one hash
+	QueueSize int // Calls waiting for a worker before further ones get ErrBusy
+
+	// Registerer receives the pool metrics; nil leaves them unregistered.
+	Registerer prometheus.Registerer
+}
+
+// Pool runs the hashes and checks of a Hasher and a Checker with bounded concurrency,
+// so that a burst of logins or sign-ups cannot exhaust the memory and CPU of the process.
+// The work runs on the calling goroutine once a worker slot is free.
+type Pool struct {
+	hasher  Hasher
+	checker Checker
+	admit   chan struct{} // Held from admission until the call returns
+	workers chan struct{} // Held while hashing
+
+	queueDepth prometheus.Gauge
+	inFlight   prometheus.Gauge
+	wait       prometheus.Histogram
+	shed       prometheus.Counter
+}
+
+func NewPool(cfg PoolConfig, hasher Hasher, checker Checker) (*Pool, error) {
+	if cfg.Workers < 1 || cfg.QueueSize < 0 {
+		return nil, fmt.Errorf("invalid hashing pool size: %d workers, queue of %d", cfg.Workers, cfg.QueueSize)
+	}
+	p := &Pool{
+		hasher:  hasher,
+		checker: checker,
+		admit:   make(chan struct{}, cfg.Workers+cfg.QueueSize),
+		workers: make(chan struct{}, cfg.Workers),
+		queueDepth: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "password_hash_queue_depth",
+			Help: "Password hashes and checks waiting for a worker",
+		}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "password_hash_in_flight",
+			Help: "Password hashes and checks being computed",
+		}),
+		wait: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "password_hash_wait_seconds",
+			Help:    "Time password hashes and checks waited for a worker",
+			Buckets: []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+		}),
+		shed: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "password_hash_rejected_total",
+			Help: "Password hashes and checks rejected because the queue was full",
+		}),
+	}
+	if cfg.Registerer != nil {
+		for _, c := range []prometheus.Collector{p.queueDepth, p.inFlight, p.wait, p.shed} {
+			if err := cfg.Registerer.Register(c); err != nil {
+				return nil, fmt.Errorf("register hashing pool metrics: %w", err)
+			}
+		}
+	}
+	return p, nil
+}
+
+// Hash hashes password once a worker is free. It returns ErrBusy if the queue is full
+// and the context error if ctx ends while waiting.
+func (p *Pool) Hash(ctx context.Context, password string) (string, error) {
+	var hash string
+	var err error
+	if poolErr := p.do(ctx, func() { hash, err = p.hasher.Hash(password) }); poolErr != nil {
+		return "", poolErr
+	}
+	return hash, err
+}
+
+// CompareHashAndPassword checks password once a worker is free, failing like Hash
+// when it cannot run.
+func (p *Pool) CompareHashAndPassword(ctx context.Context, hashedPassword, password string) error {
+	var err error
+	if poolErr := p.do(ctx, func() { err = p.checker.CompareHashAndPassword(hashedPassword, password) }); poolErr != nil {
+		return poolErr
+	}
+	return err
+}
+
+// NeedsRehash is Hasher.NeedsRehash, which only parses the hash and runs unbounded.
+func (p *Pool) NeedsRehash(hashedPassword string) bool {
+	return p.hasher.NeedsRehash(hashedPassword)
+}
+
+func (p *Pool) do(ctx context.Context, fn func()) error {
+	select {
+	case p.admit <- struct{}{}:
+	default:
+		p.shed.Inc()
+		return ErrBusy
+	}
+	defer func() { <-p.admit }()
+
+	start := time.Now()
+	p.queueDepth.Inc()
+	select {
+	case p.workers <- struct{}{}:
+		p.queueDepth.Dec()
+	case <-ctx.Done():
+		p.queueDepth.Dec()
+		return ctx.Err()
+	}
+	p.wait.Observe(time.Since(start).Seconds())
+
+	p.inFlight.Inc()
+	defer func() {
+		p.inFlight.Dec()
+		<-p.workers
+	}()
+	fn()
+	return nil
+}
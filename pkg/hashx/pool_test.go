@@ -0,0 +1,123 @@
+package hashx_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kerim-dauren/user-service/pkg/hashx"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// blockingHasher hashes once release is closed, after reporting each call on started.
+type blockingHasher struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b blockingHasher) Hash(password string) (string, error) {
+	b.started <- struct{}{}
+	<-b.release
+	return "hash:" + password, nil
+}
+
+func (b blockingHasher) NeedsRehash(string) bool { return false }
+
+func (b blockingHasher) CompareHashAndPassword(hashedPassword, password string) error {
+	if hashedPassword != "hash:"+password {
+		return errors.New("passwords do not match")
+	}
+	return nil
+}
+
+func TestPool(t *testing.T) {
+	hasher := blockingHasher{started: make(chan struct{}, 2), release: make(chan struct{})}
+	registry := prometheus.NewRegistry()
+	pool, err := hashx.NewPool(hashx.PoolConfig{Workers: 1, QueueSize: 1, Registerer: registry}, hasher, hasher)
+	if err != nil {
+		t.Fatalf("failed to create pool: %v", err)
+	}
+	ctx := context.Background()
+
+	results := make(chan error, 2)
+	hash := func(password string) {
+		_, err := pool.Hash(ctx, password)
+		results <- err
+	}
+	go hash("first")
+	<-hasher.started
+	go hash("queued")
+	waitFor(t, func() bool { return gaugeValue(t, registry, "password_hash_queue_depth") == 1 })
+
+	if _, err = pool.Hash(ctx, "shed"); !errors.Is(err, hashx.ErrBusy) {
+		t.Errorf("expected ErrBusy with a full queue, got %v", err)
+	}
+
+	close(hasher.release)
+	for i := 0; i < 2; i++ {
+		if err = <-results; err != nil {
+			t.Errorf("failed to hash password: %v", err)
+		}
+	}
+
+	if err = pool.CompareHashAndPassword(ctx, "hash:secret", "secret"); err != nil {
+		t.Errorf("failed to compare password: %v", err)
+	}
+	if err = pool.CompareHashAndPassword(ctx, "hash:secret", "wrong"); err == nil {
+		t.Error("expected an error for a wrong password, but got none")
+	}
+}
+
+func TestPool_ContextDone(t *testing.T) {
+	hasher := blockingHasher{started: make(chan struct{}, 1), release: make(chan struct{})}
+	defer close(hasher.release)
+	pool, err := hashx.NewPool(hashx.PoolConfig{Workers: 1, QueueSize: 1}, hasher, hasher)
+	if err != nil {
+		t.Fatalf("failed to create pool: %v", err)
+	}
+
+	go pool.Hash(context.Background(), "first")
+	<-hasher.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err = pool.Hash(ctx, "waiting"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the context error while waiting, got %v", err)
+	}
+}
+
+func TestNewPool_Invalid(t *testing.T) {
+	hasher := hashx.NewArgon2Hasher()
+	for _, cfg := range []hashx.PoolConfig{{Workers: 0}, {Workers: 1, QueueSize: -1}} {
+		if _, err := hashx.NewPool(cfg, hasher, hashx.NewChecker()); err == nil {
+			t.Errorf("expected an error for %+v, but got none", cfg)
+		}
+	}
+}
+
+func gaugeValue(t *testing.T, registry *prometheus.Registry, name string) float64 {
+	t.Helper()
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() == name {
+			return family.GetMetric()[0].GetGauge().GetValue()
+		}
+	}
+	t.Fatalf("metric %s is not registered", name)
+	return 0
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition was not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,119 @@
+package hashx
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"golang.org/x/crypto/scrypt"
+	"strings"
+)
+
+const scryptPrefix = "$scrypt$"
+
+// ScryptParams are the scrypt cost parameters. The hashes are written as
+// $scrypt$ln=<log2 N>,r=<r>,p=<p>$<salt>$<hash>.
+type ScryptParams struct {
+	LogN       uint8
+	R          int
+	P          int
+	SaltLength int
+	KeyLength  int
+}
+
+// DefaultScryptParams returns N=2^15, r=8, p=1, about 32 MB of memory.
+func DefaultScryptParams() ScryptParams {
+	return ScryptParams{LogN: 15, R: 8, P: 1, SaltLength: 16, KeyLength: 32}
+}
+
+type scryptHasher struct {
+	params ScryptParams
+}
+
+func NewScryptHasher(params ScryptParams) (Hasher, error) {
+	if params.LogN < 1 || params.LogN > 30 || params.R < 1 || params.P < 1 {
+		return nil, fmt.Errorf("invalid scrypt parameters: ln=%d,r=%d,p=%d", params.LogN, params.R, params.P)
+	}
+	if params.SaltLength < 8 || params.KeyLength < 16 {
+		return nil, fmt.Errorf("scrypt salt and key must be at least 8 and 16 bytes")
+	}
+	return scryptHasher{params: params}, nil
+}
+
+func (s scryptHasher) Hash(password string) (string, error) {
+	if password == "" {
+		return "", fmt.Errorf("password cannot be empty")
+	}
+
+	salt := make([]byte, s.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+	p := s.params
+	hash, err := scrypt.Key([]byte(password), salt, 1<<p.LogN, p.R, p.P, p.KeyLength)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("$scrypt$ln=%d,r=%d,p=%d$%s$%s", p.LogN, p.R, p.P,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(hash)), nil
+}
+
+func (s scryptHasher) NeedsRehash(hashedPassword string) bool {
+	h, err := parseScrypt(hashedPassword)
+	if err != nil {
+		return true
+	}
+	return h.logN != s.params.LogN || h.r != s.params.R || h.p != s.params.P ||
+		len(h.salt) != s.params.SaltLength || len(h.hash) != s.params.KeyLength
+}
+
+type scryptChecker struct{}
+
+func NewScryptChecker() Checker {
+	return scryptChecker{}
+}
+
+func (scryptChecker) CompareHashAndPassword(hashedPassword, password string) error {
+	h, err := parseScrypt(hashedPassword)
+	if err != nil {
+		return err
+	}
+	calculatedHash, err := scrypt.Key([]byte(password), h.salt, 1<<h.logN, h.r, h.p, len(h.hash))
+	if err != nil {
+		return err
+	}
+	if !subtleCompare(h.hash, calculatedHash) {
+		return fmt.Errorf("passwords do not match")
+	}
+	return nil
+}
+
+type scryptHash struct {
+	logN       uint8
+	r, p       int
+	salt, hash []byte
+}
+
+func parseScrypt(hashedPassword string) (*scryptHash, error) {
+	parts := strings.Split(hashedPassword, "$")
+	if len(parts) != 5 || parts[1] != "scrypt" {
+		return nil, fmt.Errorf("invalid hash format")
+	}
+
+	var h scryptHash
+	if _, err := fmt.Sscanf(parts[2], "ln=%d,r=%d,p=%d", &h.logN, &h.r, &h.p); err != nil {
+		return nil, fmt.Errorf("failed to parse parameters: %w", err)
+	}
+	if h.logN < 1 || h.logN > 30 {
+		return nil, fmt.Errorf("invalid scrypt cost: ln=%d", h.logN)
+	}
+
+	var err error
+	if h.salt, err = base64.RawStdEncoding.DecodeString(parts[3]); err != nil {
+		return nil, fmt.Errorf("failed to decode salt: %w", err)
+	}
+	if h.hash, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, fmt.Errorf("failed to decode hash: %w", err)
+	}
+	return &h, nil
+}
@@ -0,0 +1,9 @@
+package mailx
+
+import "context"
+
+type (
+	Sender interface {
+		Send(ctx context.Context, msg *Message) error
+	}
+)
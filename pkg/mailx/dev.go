@@ -0,0 +1,67 @@
+package mailx
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// FileSender writes every message as an .eml file into a directory.
+// It is meant for local development, where the files can be opened in a mail client.
+type FileSender struct {
+	dir string
+	now func() time.Time
+}
+
+// NewFileSender creates dir if needed and builds a sender writing into it.
+func NewFileSender(dir string) (*FileSender, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("mail directory is required")
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create mail directory: %w", err)
+	}
+	return &FileSender{dir: dir, now: time.Now}, nil
+}
+
+func (s *FileSender) Send(_ context.Context, msg *Message) error {
+	now := s.now()
+	data, err := msg.Bytes(now)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(s.dir, now.UTC().Format("20060102T150405.000")+"-*.eml")
+	if err != nil {
+		return fmt.Errorf("failed to create mail file: %w", err)
+	}
+	if _, err = f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write mail file: %w", err)
+	}
+	return f.Close()
+}
+
+// LogSender writes every message, bodies included, to the log.
+// It is meant for local development only since the bodies may contain secrets.
+type LogSender struct {
+	logger *slog.Logger
+}
+
+func NewLogSender(logger *slog.Logger) *LogSender {
+	return &LogSender{logger: logger}
+}
+
+func (s *LogSender) Send(ctx context.Context, msg *Message) error {
+	if err := msg.Validate(); err != nil {
+		return err
+	}
+	s.logger.InfoContext(ctx, "mail",
+		slog.String("from", msg.From),
+		slog.Any("to", msg.To),
+		slog.String("subject", msg.Subject),
+		slog.String("text", msg.Text),
+	)
+	return nil
+}
@@ -0,0 +1,29 @@
+package mailx
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSender_Send(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	sender, err := NewFileSender(dir)
+	require.NoError(t, err)
+	sender.now = func() time.Time { return time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC) }
+
+	require.NoError(t, sender.Send(context.Background(), testMessage()))
+
+	files, err := filepath.Glob(filepath.Join(dir, "20240501T120000.000-*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "Subject: Reset your password\r\n")
+	assert.Contains(t, string(data), "Date: Wed, 01 May 2024 12:00:00 +0000\r\n")
+}
@@ -0,0 +1,34 @@
+// Package mailx sends email through pluggable drivers and renders localized templates.
+package mailx
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+const (
+	DriverSMTP = "smtp"
+	DriverFile = "file"
+	DriverLog  = "log"
+)
+
+// Config selects and configures a driver.
+type Config struct {
+	Driver string // "smtp", "file" or "log"
+	SMTP   SMTPConfig
+	Dir    string // Output directory of the file driver
+}
+
+// NewSender builds the sender for cfg.Driver. The log driver writes to logger.
+func NewSender(cfg Config, logger *slog.Logger) (Sender, error) {
+	switch cfg.Driver {
+	case DriverSMTP:
+		return NewSMTPSender(cfg.SMTP)
+	case DriverFile:
+		return NewFileSender(cfg.Dir)
+	case DriverLog:
+		return NewLogSender(logger), nil
+	default:
+		return nil, fmt.Errorf("unsupported mail driver: %s", cfg.Driver)
+	}
+}
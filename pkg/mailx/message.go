@@ -0,0 +1,144 @@
+package mailx
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+var ErrInvalidMessage = errors.New("invalid message")
+
+// Message is an email with a plain text body and an optional HTML alternative.
+type Message struct {
+	From    string   // Sender address, optionally with a display name
+	To      []string // Recipient addresses
+	Subject string
+	Text    string // Plain text body
+	HTML    string // HTML body; sent as a multipart/alternative part when set
+}
+
+// Validate checks that the message has a sender, recipients and a body,
+// and that every address is well-formed.
+func (m *Message) Validate() error {
+	if _, err := mail.ParseAddress(m.From); err != nil {
+		return fmt.Errorf("%w: from: %v", ErrInvalidMessage, err)
+	}
+	if len(m.To) == 0 {
+		return fmt.Errorf("%w: no recipients", ErrInvalidMessage)
+	}
+	for _, to := range m.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			return fmt.Errorf("%w: to: %v", ErrInvalidMessage, err)
+		}
+	}
+	if m.Text == "" && m.HTML == "" {
+		return fmt.Errorf("%w: empty body", ErrInvalidMessage)
+	}
+	return nil
+}
+
+// envelope returns the bare sender and recipient addresses for the SMTP envelope.
+func (m *Message) envelope() (string, []string, error) {
+	if err := m.Validate(); err != nil {
+		return "", nil, err
+	}
+	from, _ := mail.ParseAddress(m.From)
+	to := make([]string, len(m.To))
+	for i, addr := range m.To {
+		parsed, _ := mail.ParseAddress(addr)
+		to[i] = parsed.Address
+	}
+	return from.Address, to, nil
+}
+
+// Bytes renders the message in RFC 5322 format with quoted-printable UTF-8 bodies.
+func (m *Message) Bytes(date time.Time) ([]byte, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	from, _ := mail.ParseAddress(m.From)
+	messageID, err := newMessageID(from.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	header := func(key, value string) {
+		buf.WriteString(key + ": " + value + "\r\n")
+	}
+	header("From", from.String())
+	to := make([]string, len(m.To))
+	for i, addr := range m.To {
+		parsed, _ := mail.ParseAddress(addr)
+		to[i] = parsed.String()
+	}
+	header("To", strings.Join(to, ", "))
+	// Q-encoding also escapes CR and LF, so the subject cannot inject headers.
+	header("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	header("Date", date.Format(time.RFC1123Z))
+	header("Message-ID", messageID)
+	header("MIME-Version", "1.0")
+
+	if m.HTML == "" {
+		header("Content-Type", "text/plain; charset=utf-8")
+		header("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		if err := writeQuotedPrintable(&buf, m.Text); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	parts := multipart.NewWriter(&buf)
+	header("Content-Type", "multipart/alternative; boundary="+parts.Boundary())
+	buf.WriteString("\r\n")
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", m.Text},
+		{"text/html; charset=utf-8", m.HTML},
+	} {
+		if part.body == "" {
+			continue
+		}
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err = writeQuotedPrintable(w, part.body); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeQuotedPrintable(w io.Writer, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+func newMessageID(from string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate message id: %w", err)
+	}
+	domain := from[strings.LastIndex(from, "@")+1:]
+	return "<" + hex.EncodeToString(b) + "@" + domain + ">", nil
+}
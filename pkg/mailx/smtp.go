@@ -0,0 +1,137 @@
+package mailx
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+const (
+	TLSModeStartTLS = "starttls" // Upgrade a plain connection, required to succeed
+	TLSModeTLS      = "tls"      // Implicit TLS, usually on port 465
+	TLSModeNone     = "none"     // Plain connection, for local relays only
+
+	defaultSMTPTimeout = 10 * time.Second
+)
+
+// SMTPConfig holds the SMTP relay configuration.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string // PLAIN authentication is used when set
+	Password string
+	TLSMode  string        // TLSModeStartTLS (default), TLSModeTLS or TLSModeNone
+	Timeout  time.Duration // Bounds a whole delivery, including the context deadline
+}
+
+// SMTPSender delivers messages through an SMTP relay, one connection per message.
+type SMTPSender struct {
+	cfg       SMTPConfig
+	tlsConfig *tls.Config
+	now       func() time.Time
+}
+
+// NewSMTPSender validates cfg and builds a sender.
+func NewSMTPSender(cfg SMTPConfig) (*SMTPSender, error) {
+	if cfg.Host == "" {
+		return nil, errors.New("smtp host is required")
+	}
+	if cfg.Port <= 0 {
+		return nil, fmt.Errorf("invalid smtp port: %d", cfg.Port)
+	}
+	switch cfg.TLSMode {
+	case "":
+		cfg.TLSMode = TLSModeStartTLS
+	case TLSModeStartTLS, TLSModeTLS, TLSModeNone:
+	default:
+		return nil, fmt.Errorf("unsupported smtp tls mode: %s", cfg.TLSMode)
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultSMTPTimeout
+	}
+	return &SMTPSender{
+		cfg:       cfg,
+		tlsConfig: &tls.Config{ServerName: cfg.Host, MinVersion: tls.VersionTLS12},
+		now:       time.Now,
+	}, nil
+}
+
+func (s *SMTPSender) Send(ctx context.Context, msg *Message) error {
+	from, to, err := msg.envelope()
+	if err != nil {
+		return err
+	}
+	data, err := msg.Bytes(s.now())
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+	conn, err := s.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("smtp dial: %w", err)
+	}
+	// net/smtp does not take a context; the deadline bounds every exchange instead.
+	deadline, _ := ctx.Deadline()
+	if err = conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("smtp handshake: %w", err)
+	}
+	defer client.Close()
+
+	if s.cfg.TLSMode == TLSModeStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return errors.New("smtp server does not support STARTTLS")
+		}
+		if err = client.StartTLS(s.tlsConfig); err != nil {
+			return fmt.Errorf("smtp starttls: %w", err)
+		}
+	}
+	if s.cfg.Username != "" {
+		// PlainAuth refuses to send credentials over an unencrypted connection to a remote host.
+		if err = client.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+			return fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+	if err = client.Mail(from); err != nil {
+		return fmt.Errorf("smtp mail from: %w", err)
+	}
+	for _, rcpt := range to {
+		if err = client.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("smtp rcpt to: %w", err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp data: %w", err)
+	}
+	if _, err = w.Write(data); err != nil {
+		return fmt.Errorf("smtp data: %w", err)
+	}
+	if err = w.Close(); err != nil {
+		return fmt.Errorf("smtp data: %w", err)
+	}
+	return client.Quit()
+}
+
+func (s *SMTPSender) dial(ctx context.Context) (net.Conn, error) {
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	if s.cfg.TLSMode == TLSModeTLS {
+		dialer := &tls.Dialer{Config: s.tlsConfig}
+		return dialer.DialContext(ctx, "tcp", addr)
+	}
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, "tcp", addr)
+}
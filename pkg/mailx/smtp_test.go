@@ -0,0 +1,157 @@
+package mailx
+
+import (
+	"context"
+	"encoding/base64"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// smtpStandIn is a minimal in-process SMTP server that records what it receives.
+type smtpStandIn struct {
+	listener net.Listener
+	wg       sync.WaitGroup
+
+	mu    sync.Mutex
+	auth  string
+	from  string
+	rcpts []string
+	data  string
+}
+
+func newSMTPStandIn(t *testing.T) *smtpStandIn {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &smtpStandIn{listener: l}
+	s.wg.Add(1)
+	go s.serve()
+	t.Cleanup(func() {
+		l.Close()
+		s.wg.Wait()
+	})
+	return s
+}
+
+func (s *smtpStandIn) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpStandIn) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.handle(textproto.NewConn(conn))
+	}
+}
+
+func (s *smtpStandIn) handle(c *textproto.Conn) {
+	defer c.Close()
+	_ = c.PrintfLine("220 localhost ready")
+	for {
+		line, err := c.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		s.mu.Lock()
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			_ = c.PrintfLine("250-localhost\r\n250 AUTH PLAIN")
+		case "AUTH":
+			s.auth = arg
+			_ = c.PrintfLine("235 authenticated")
+		case "MAIL":
+			s.from = arg
+			_ = c.PrintfLine("250 ok")
+		case "RCPT":
+			s.rcpts = append(s.rcpts, arg)
+			_ = c.PrintfLine("250 ok")
+		case "DATA":
+			_ = c.PrintfLine("354 go ahead")
+			data, _ := c.ReadDotBytes()
+			s.data = string(data)
+			_ = c.PrintfLine("250 queued")
+		case "QUIT":
+			_ = c.PrintfLine("221 bye")
+			s.mu.Unlock()
+			return
+		default:
+			_ = c.PrintfLine("502 not implemented")
+		}
+		s.mu.Unlock()
+	}
+}
+
+func testMessage() *Message {
+	return &Message{
+		From:    "User Service <no-reply@example.com>",
+		To:      []string{"Alice <alice@example.com>", "bob@example.com"},
+		Subject: "Reset your password",
+		Text:    "Open https://example.com/reset?token=abc",
+		HTML:    `<a href="https://example.com/reset?token=abc">Reset</a>`,
+	}
+}
+
+func TestSMTPSender_Send(t *testing.T) {
+	server := newSMTPStandIn(t)
+	sender, err := NewSMTPSender(SMTPConfig{
+		Host:     "127.0.0.1",
+		Port:     server.port(),
+		Username: "user",
+		Password: "secret",
+		TLSMode:  TLSModeNone,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, sender.Send(context.Background(), testMessage()))
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	assert.Equal(t, "PLAIN "+base64.StdEncoding.EncodeToString([]byte("\x00user\x00secret")), server.auth)
+	assert.Equal(t, "FROM:<no-reply@example.com>", server.from)
+	assert.Equal(t, []string{"TO:<alice@example.com>", "TO:<bob@example.com>"}, server.rcpts)
+	assert.Contains(t, server.data, "Subject: Reset your password\n")
+	assert.Contains(t, server.data, `To: "Alice" <alice@example.com>, <bob@example.com>`)
+	assert.Contains(t, server.data, "Content-Type: multipart/alternative")
+	assert.Contains(t, server.data, "https://example.com/reset?token=3Dabc")
+}
+
+func TestSMTPSender_RequiresStartTLS(t *testing.T) {
+	server := newSMTPStandIn(t)
+	sender, err := NewSMTPSender(SMTPConfig{Host: "127.0.0.1", Port: server.port()})
+	require.NoError(t, err)
+
+	err = sender.Send(context.Background(), testMessage())
+	assert.ErrorContains(t, err, "STARTTLS")
+	assert.Empty(t, server.from, "nothing is sent over a connection that was meant to be encrypted")
+}
+
+func TestSMTPSender_InvalidMessage(t *testing.T) {
+	sender, err := NewSMTPSender(SMTPConfig{Host: "127.0.0.1", Port: 1, TLSMode: TLSModeNone})
+	require.NoError(t, err)
+
+	msg := testMessage()
+	msg.To = []string{"not an address"}
+	assert.ErrorIs(t, sender.Send(context.Background(), msg), ErrInvalidMessage)
+}
+
+func TestNewSMTPSender_InvalidConfig(t *testing.T) {
+	for _, cfg := range []SMTPConfig{
+		{Port: 25},
+		{Host: "localhost"},
+		{Host: "localhost", Port: 25, TLSMode: "ssl"},
+	} {
+		_, err := NewSMTPSender(cfg)
+		assert.Error(t, err, cfg)
+	}
+}
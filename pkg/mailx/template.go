@@ -0,0 +1,133 @@
+package mailx
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strings"
+	texttemplate "text/template"
+)
+
+var ErrTemplateNotFound = errors.New("mail template not found")
+
+const (
+	subjectSuffix = ".subject.txt"
+	textSuffix    = ".txt"
+	htmlSuffix    = ".html"
+)
+
+type templateSet struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template // Nil for text-only messages
+}
+
+// Templates renders localized messages from a tree of files laid out as
+//
+//	<locale>/<name>.subject.txt  subject, text/template (required)
+//	<locale>/<name>.txt          plain text body, text/template (required)
+//	<locale>/<name>.html         HTML body, html/template (optional)
+//
+// Locales are lowercase language tags such as "en" or "pt-br".
+type Templates struct {
+	defaultLocale string
+	locales       map[string]map[string]*templateSet
+}
+
+// NewTemplates parses every template in fsys up front so that mistakes surface on startup.
+// Messages in a locale without the requested template fall back to defaultLocale.
+func NewTemplates(fsys fs.FS, defaultLocale string) (*Templates, error) {
+	t := &Templates{
+		defaultLocale: normalizeLocale(defaultLocale),
+		locales:       map[string]map[string]*templateSet{},
+	}
+	subjects, err := fs.Glob(fsys, "*/*"+subjectSuffix)
+	if err != nil {
+		return nil, err
+	}
+	for _, subjectFile := range subjects {
+		locale := normalizeLocale(path.Dir(subjectFile))
+		name := strings.TrimSuffix(path.Base(subjectFile), subjectSuffix)
+		base := strings.TrimSuffix(subjectFile, subjectSuffix)
+
+		set := &templateSet{}
+		if set.subject, err = parseText(fsys, subjectFile); err != nil {
+			return nil, err
+		}
+		if set.text, err = parseText(fsys, base+textSuffix); err != nil {
+			return nil, err
+		}
+		if _, err = fs.Stat(fsys, base+htmlSuffix); err == nil {
+			set.html, err = htmltemplate.New(path.Base(base+htmlSuffix)).Option("missingkey=error").ParseFS(fsys, base+htmlSuffix)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse mail template: %w", err)
+			}
+		}
+
+		if t.locales[locale] == nil {
+			t.locales[locale] = map[string]*templateSet{}
+		}
+		t.locales[locale][name] = set
+	}
+	if len(t.locales[t.defaultLocale]) == 0 {
+		return nil, fmt.Errorf("no mail templates for the default locale %q", defaultLocale)
+	}
+	return t, nil
+}
+
+// Render executes the template name for locale with data.
+// The locale falls back from "pt-br" to "pt" and then to the default locale.
+// The returned message has no sender or recipients.
+func (t *Templates) Render(name, locale string, data any) (*Message, error) {
+	set := t.lookup(name, normalizeLocale(locale))
+	if set == nil {
+		return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := set.subject.Execute(&subject, data); err != nil {
+		return nil, fmt.Errorf("failed to render mail subject: %w", err)
+	}
+	if err := set.text.Execute(&text, data); err != nil {
+		return nil, fmt.Errorf("failed to render mail text: %w", err)
+	}
+	if set.html != nil {
+		if err := set.html.Execute(&html, data); err != nil {
+			return nil, fmt.Errorf("failed to render mail html: %w", err)
+		}
+	}
+	return &Message{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+func (t *Templates) lookup(name, locale string) *templateSet {
+	candidates := []string{locale}
+	if language, _, ok := strings.Cut(locale, "-"); ok {
+		candidates = append(candidates, language)
+	}
+	candidates = append(candidates, t.defaultLocale)
+	for _, candidate := range candidates {
+		if set, ok := t.locales[candidate][name]; ok {
+			return set
+		}
+	}
+	return nil
+}
+
+func parseText(fsys fs.FS, file string) (*texttemplate.Template, error) {
+	tmpl, err := texttemplate.New(path.Base(file)).Option("missingkey=error").ParseFS(fsys, file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse mail template: %w", err)
+	}
+	return tmpl, nil
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+}
@@ -0,0 +1,92 @@
+package mailx
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testTemplates(t *testing.T) *Templates {
+	t.Helper()
+	templates, err := NewTemplates(fstest.MapFS{
+		"en/welcome.subject.txt": {Data: []byte("Welcome, {{.Name}}\n")},
+		"en/welcome.txt":         {Data: []byte("Hello {{.Name}}, open {{.Link}}")},
+		"en/welcome.html":        {Data: []byte(`<a href="{{.Link}}">Hello {{.Name}}</a>`)},
+		"ru/welcome.subject.txt": {Data: []byte("Добро пожаловать, {{.Name}}")},
+		"ru/welcome.txt":         {Data: []byte("Привет, {{.Name}}")},
+		"en/notice.subject.txt":  {Data: []byte("Notice")},
+		"en/notice.txt":          {Data: []byte("Something happened")},
+	}, "en")
+	require.NoError(t, err)
+	return templates
+}
+
+type welcomeData struct {
+	Name string
+	Link string
+}
+
+func TestTemplates_Render(t *testing.T) {
+	templates := testTemplates(t)
+
+	msg, err := templates.Render("welcome", "en", welcomeData{Name: "<Alice>", Link: "https://example.com/?a=1&b=2"})
+	require.NoError(t, err)
+	assert.Equal(t, "Welcome, <Alice>", msg.Subject)
+	assert.Equal(t, "Hello <Alice>, open https://example.com/?a=1&b=2", msg.Text)
+	assert.Equal(t, `<a href="https://example.com/?a=1&amp;b=2">Hello &lt;Alice&gt;</a>`, msg.HTML)
+
+	msg, err = templates.Render("notice", "en", nil)
+	require.NoError(t, err)
+	assert.Empty(t, msg.HTML, "the HTML body is optional")
+}
+
+func TestTemplates_Render_LocaleFallback(t *testing.T) {
+	templates := testTemplates(t)
+
+	for locale, subject := range map[string]string{
+		"ru":    "Добро пожаловать, Alice",
+		"ru_RU": "Добро пожаловать, Alice",
+		"RU-ru": "Добро пожаловать, Alice",
+		"de":    "Welcome, Alice",
+		"":      "Welcome, Alice",
+	} {
+		msg, err := templates.Render("welcome", locale, welcomeData{Name: "Alice"})
+		require.NoError(t, err, locale)
+		assert.Equal(t, subject, msg.Subject, locale)
+	}
+
+	msg, err := templates.Render("notice", "ru", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Notice", msg.Subject, "templates missing in a locale come from the default one")
+}
+
+func TestTemplates_Render_Errors(t *testing.T) {
+	templates := testTemplates(t)
+
+	_, err := templates.Render("missing", "en", nil)
+	assert.ErrorIs(t, err, ErrTemplateNotFound)
+
+	_, err = templates.Render("welcome", "en", map[string]string{})
+	assert.Error(t, err, "missing keys fail instead of rendering <no value>")
+}
+
+func TestNewTemplates_Invalid(t *testing.T) {
+	_, err := NewTemplates(fstest.MapFS{
+		"en/welcome.subject.txt": {Data: []byte("Welcome")},
+	}, "en")
+	assert.Error(t, err, "the text body is required")
+
+	_, err = NewTemplates(fstest.MapFS{
+		"en/welcome.subject.txt": {Data: []byte("{{.Name")},
+		"en/welcome.txt":         {Data: []byte("Hello")},
+	}, "en")
+	assert.Error(t, err)
+
+	_, err = NewTemplates(fstest.MapFS{
+		"ru/welcome.subject.txt": {Data: []byte("Привет")},
+		"ru/welcome.txt":         {Data: []byte("Привет")},
+	}, "en")
+	assert.Error(t, err, "the default locale must exist")
+}
@@ -0,0 +1,72 @@
+package otpx
+
+import (
+	"bytes"
+	"image/png"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTOTP_Code_RFC6238(t *testing.T) {
+	secret := []byte("12345678901234567890")
+	totp := TOTP{Digits: 8, Period: 30 * time.Second}
+
+	for unix, code := range map[int64]string{
+		59:          "94287082",
+		1111111109:  "07081804",
+		1111111111:  "14050471",
+		1234567890:  "89005924",
+		2000000000:  "69279037",
+		20000000000: "65353130",
+	} {
+		assert.Equal(t, code, totp.Code(secret, time.Unix(unix, 0)), unix)
+	}
+}
+
+func TestTOTP_Validate(t *testing.T) {
+	secret, err := NewSecret()
+	require.NoError(t, err)
+	totp := NewTOTP()
+	now := time.Unix(1_700_000_000, 0)
+
+	step, ok := totp.Validate(secret, totp.Code(secret, now), now)
+	assert.True(t, ok)
+	assert.Equal(t, totp.Step(now), step)
+
+	previous := now.Add(-totp.Period)
+	step, ok = totp.Validate(secret, " "+totp.Code(secret, previous)+" ", now)
+	assert.True(t, ok, "one period of clock skew is tolerated")
+	assert.Equal(t, totp.Step(previous), step)
+
+	_, ok = totp.Validate(secret, totp.Code(secret, now.Add(-2*totp.Period)), now)
+	assert.False(t, ok)
+	_, ok = totp.Validate(secret, "12345", now)
+	assert.False(t, ok)
+}
+
+func TestTOTP_KeyURI(t *testing.T) {
+	secret := []byte("12345678901234567890")
+
+	uri, err := url.Parse(NewTOTP().KeyURI("User Service", "alice", secret))
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, "totp", uri.Host)
+	assert.Equal(t, "/User Service:alice", uri.Path)
+	assert.Equal(t, "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", uri.Query().Get("secret"))
+	assert.Equal(t, "User Service", uri.Query().Get("issuer"))
+	assert.Equal(t, "6", uri.Query().Get("digits"))
+	assert.Equal(t, "30", uri.Query().Get("period"))
+}
+
+func TestQRCode(t *testing.T) {
+	data, err := QRCode("otpauth://totp/test?secret=ABC", 128)
+	require.NoError(t, err)
+
+	img, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, 128, img.Bounds().Dx())
+}
@@ -0,0 +1,35 @@
+package tokenx
+
+import (
+	"strconv"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Claims is the payload of an access token.
+// The user ID is carried in the standard "sub" claim.
+type Claims struct {
+	Username         string   `json:"username"`
+	OrganizationID   int64    `json:"org,omitempty"`
+	OrganizationRole string   `json:"org_role,omitempty"`
+	Roles            []string `json:"roles,omitempty"`
+	Permissions      []string `json:"permissions,omitempty"`
+	SessionID        string   `json:"sid,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// UserID parses the subject claim as a numeric user ID.
+func (c *Claims) UserID() (int64, error) {
+	return strconv.ParseInt(c.Subject, 10, 64)
+}
+
+// NewClaims returns claims for the given user.
+func NewClaims(userID int64, username string, roles []string) Claims {
+	return Claims{
+		Username: username,
+		Roles:    roles,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: strconv.FormatInt(userID, 10),
+		},
+	}
+}
@@ -0,0 +1,10 @@
+package tokenx
+
+type (
+	Issuer interface {
+		Issue(claims Claims) (string, error)
+	}
+	Verifier interface {
+		Verify(token string) (*Claims, error)
+	}
+)
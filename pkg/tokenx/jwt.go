@@ -0,0 +1,216 @@
+package tokenx
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgorithmEdDSA = "EdDSA"
+	AlgorithmRS256 = "RS256"
+	AlgorithmHS256 = "HS256"
+
+	defaultTTL = 15 * time.Minute
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrExpiredToken = errors.New("token is expired")
+)
+
+// Config holds the signing configuration.
+type Config struct {
+	Algorithm      string        // "EdDSA", "RS256" or "HS256"
+	Secret         string        // Shared secret, HS256 only
+	PrivateKeyFile string        // PEM-encoded PKCS#8 private key, EdDSA and RS256
+	KeyID          string        // Published "kid"; derived from the public key if empty
+	Issuer         string        // "iss" claim
+	TTL            time.Duration // Lifetime of issued tokens
+}
+
+// Manager issues and verifies signed JWTs.
+type Manager struct {
+	method    jwt.SigningMethod
+	signKey   any
+	verifyKey any
+	keyID     string
+	issuer    string
+	ttl       time.Duration
+	now       func() time.Time
+}
+
+// NewManager builds a Manager from cfg.
+// For asymmetric algorithms without a key file an ephemeral key is generated,
+// which is only suitable for local development since tokens do not survive restarts.
+func NewManager(cfg Config) (*Manager, error) {
+	m := &Manager{
+		issuer: cfg.Issuer,
+		ttl:    cfg.TTL,
+		now:    time.Now,
+	}
+	if m.ttl <= 0 {
+		m.ttl = defaultTTL
+	}
+
+	switch cfg.Algorithm {
+	case AlgorithmEdDSA, "":
+		m.method = jwt.SigningMethodEdDSA
+		key, err := loadEd25519Key(cfg.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		m.signKey, m.verifyKey = key, key.Public()
+	case AlgorithmRS256:
+		m.method = jwt.SigningMethodRS256
+		key, err := loadRSAKey(cfg.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		m.signKey, m.verifyKey = key, key.Public()
+	case AlgorithmHS256:
+		if len(cfg.Secret) < 32 {
+			return nil, fmt.Errorf("HS256 secret must be at least 32 bytes")
+		}
+		m.method = jwt.SigningMethodHS256
+		m.signKey, m.verifyKey = []byte(cfg.Secret), []byte(cfg.Secret)
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm: %s", cfg.Algorithm)
+	}
+
+	m.keyID = cfg.KeyID
+	if m.keyID == "" && m.method != jwt.SigningMethodHS256 {
+		kid, err := thumbprint(m.verifyKey)
+		if err != nil {
+			return nil, err
+		}
+		m.keyID = kid
+	}
+	return m, nil
+}
+
+// TTL returns the lifetime of issued tokens.
+func (m *Manager) TTL() time.Duration {
+	return m.ttl
+}
+
+// Issue signs claims, filling in the issuer, timestamps and ID when they are empty.
+func (m *Manager) Issue(claims Claims) (string, error) {
+	now := m.now()
+	if claims.Issuer == "" {
+		claims.Issuer = m.issuer
+	}
+	if claims.IssuedAt == nil {
+		claims.IssuedAt = jwt.NewNumericDate(now)
+	}
+	if claims.ExpiresAt == nil {
+		claims.ExpiresAt = jwt.NewNumericDate(now.Add(m.ttl))
+	}
+	if claims.ID == "" {
+		id, err := randomID()
+		if err != nil {
+			return "", err
+		}
+		claims.ID = id
+	}
+
+	token := jwt.NewWithClaims(m.method, claims)
+	if m.keyID != "" {
+		token.Header["kid"] = m.keyID
+	}
+	signed, err := token.SignedString(m.signKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return signed, nil
+}
+
+// Verify checks the signature, algorithm, issuer and expiry of token.
+func (m *Manager) Verify(token string) (*Claims, error) {
+	var claims Claims
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{m.method.Alg()}),
+		jwt.WithTimeFunc(m.now),
+		jwt.WithExpirationRequired(),
+	}
+	if m.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(m.issuer))
+	}
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (any, error) {
+		return m.verifyKey, nil
+	}, opts...)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrExpiredToken
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return &claims, nil
+}
+
+func loadEd25519Key(path string) (ed25519.PrivateKey, error) {
+	if path == "" {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+	}
+	key, err := loadPrivateKey(path)
+	if err != nil {
+		return nil, err
+	}
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("key in %s is not an Ed25519 private key", path)
+	}
+	return edKey, nil
+}
+
+func loadRSAKey(path string) (*rsa.PrivateKey, error) {
+	if path == "" {
+		return rsa.GenerateKey(rand.Reader, 2048)
+	}
+	key, err := loadPrivateKey(path)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("key in %s is not an RSA private key", path)
+	}
+	return rsaKey, nil
+}
+
+func loadPrivateKey(path string) (any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found in %s", path)
+	}
+	if block.Type == "RSA PRIVATE KEY" {
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	return key, nil
+}
+
+func randomID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token id: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,147 @@
+package tokenx_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/kerim-dauren/user-service/pkg/tokenx"
+)
+
+func TestManager(t *testing.T) {
+	configs := map[string]tokenx.Config{
+		"EdDSA": {Algorithm: tokenx.AlgorithmEdDSA, Issuer: "test"},
+		"RS256": {Algorithm: tokenx.AlgorithmRS256, Issuer: "test"},
+		"HS256": {Algorithm: tokenx.AlgorithmHS256, Issuer: "test", Secret: "0123456789abcdef0123456789abcdef"},
+	}
+
+	for name, cfg := range configs {
+		t.Run(name+" issue and verify", func(t *testing.T) {
+			m, err := tokenx.NewManager(cfg)
+			if err != nil {
+				t.Fatalf("failed to create manager: %v", err)
+			}
+
+			token, err := m.Issue(tokenx.NewClaims(42, "alice", []string{"admin"}))
+			if err != nil {
+				t.Fatalf("failed to issue token: %v", err)
+			}
+
+			claims, err := m.Verify(token)
+			if err != nil {
+				t.Fatalf("failed to verify token: %v", err)
+			}
+			id, err := claims.UserID()
+			if err != nil || id != 42 {
+				t.Errorf("expected user id 42, got %d (%v)", id, err)
+			}
+			if claims.Username != "alice" || len(claims.Roles) != 1 || claims.Roles[0] != "admin" {
+				t.Errorf("unexpected claims: %+v", claims)
+			}
+		})
+	}
+
+	t.Run("Reject token signed by another key", func(t *testing.T) {
+		m1, _ := tokenx.NewManager(configs["EdDSA"])
+		m2, _ := tokenx.NewManager(configs["EdDSA"])
+
+		token, err := m1.Issue(tokenx.NewClaims(1, "alice", nil))
+		if err != nil {
+			t.Fatalf("failed to issue token: %v", err)
+		}
+		if _, err := m2.Verify(token); !errors.Is(err, tokenx.ErrInvalidToken) {
+			t.Errorf("expected ErrInvalidToken, got %v", err)
+		}
+	})
+
+	t.Run("Reject expired token", func(t *testing.T) {
+		m, _ := tokenx.NewManager(configs["HS256"])
+		claims := tokenx.NewClaims(1, "alice", nil)
+		claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+
+		token, err := m.Issue(claims)
+		if err != nil {
+			t.Fatalf("failed to issue token: %v", err)
+		}
+		if _, err := m.Verify(token); !errors.Is(err, tokenx.ErrExpiredToken) {
+			t.Errorf("expected ErrExpiredToken, got %v", err)
+		}
+	})
+
+	t.Run("Reject algorithm substitution", func(t *testing.T) {
+		m, _ := tokenx.NewManager(configs["EdDSA"])
+		forged := jwt.NewWithClaims(jwt.SigningMethodNone, tokenx.NewClaims(1, "mallory", nil))
+		token, err := forged.SignedString(jwt.UnsafeAllowNoneSignatureType)
+		if err != nil {
+			t.Fatalf("failed to forge token: %v", err)
+		}
+		if _, err := m.Verify(token); err == nil {
+			t.Error("expected an error for unsigned token, but got none")
+		}
+	})
+
+	t.Run("Short HS256 secret", func(t *testing.T) {
+		_, err := tokenx.NewManager(tokenx.Config{Algorithm: tokenx.AlgorithmHS256, Secret: "short"})
+		if err == nil {
+			t.Error("expected an error for short secret, but got none")
+		}
+	})
+
+	t.Run("Load key from file", func(t *testing.T) {
+		_, key, _ := ed25519.GenerateKey(rand.Reader)
+		der, _ := x509.MarshalPKCS8PrivateKey(key)
+		path := filepath.Join(t.TempDir(), "key.pem")
+		if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		m, err := tokenx.NewManager(tokenx.Config{Algorithm: tokenx.AlgorithmEdDSA, PrivateKeyFile: path, KeyID: "k1"})
+		if err != nil {
+			t.Fatalf("failed to create manager: %v", err)
+		}
+		jwks := m.JWKS()
+		if len(jwks.Keys) != 1 || jwks.Keys[0].Kid != "k1" || jwks.Keys[0].Crv != "Ed25519" {
+			t.Errorf("unexpected JWKS: %+v", jwks)
+		}
+	})
+}
+
+func TestJWKS(t *testing.T) {
+	t.Run("Publishes derived key ID matching token header", func(t *testing.T) {
+		m, _ := tokenx.NewManager(tokenx.Config{Algorithm: tokenx.AlgorithmRS256})
+		token, err := m.Issue(tokenx.NewClaims(1, "alice", nil))
+		if err != nil {
+			t.Fatalf("failed to issue token: %v", err)
+		}
+		parsed, _, err := jwt.NewParser().ParseUnverified(token, &tokenx.Claims{})
+		if err != nil {
+			t.Fatalf("failed to parse token: %v", err)
+		}
+
+		jwks := m.JWKS()
+		if len(jwks.Keys) != 1 {
+			t.Fatalf("expected one key, got %d", len(jwks.Keys))
+		}
+		key := jwks.Keys[0]
+		if key.Kty != "RSA" || key.Alg != "RS256" || key.N == "" || key.E == "" {
+			t.Errorf("unexpected JWK: %+v", key)
+		}
+		if key.Kid == "" || parsed.Header["kid"] != key.Kid {
+			t.Errorf("kid mismatch: header %v, jwk %s", parsed.Header["kid"], key.Kid)
+		}
+	})
+
+	t.Run("Does not publish symmetric keys", func(t *testing.T) {
+		m, _ := tokenx.NewManager(tokenx.Config{Algorithm: tokenx.AlgorithmHS256, Secret: "0123456789abcdef0123456789abcdef"})
+		if keys := m.JWKS().Keys; len(keys) != 0 {
+			t.Errorf("expected no keys, got %+v", keys)
+		}
+	})
+}
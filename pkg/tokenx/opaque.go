@@ -0,0 +1,30 @@
+package tokenx
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// NewOpaque returns a random URL-safe token with 256 bits of entropy.
+// Only its HashOpaque digest should be persisted.
+func NewOpaque() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashOpaque returns the hex-encoded SHA-256 digest of an opaque token.
+func HashOpaque(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewID returns a random 128-bit identifier.
+func NewID() (string, error) {
+	return randomID()
+}
@@ -0,0 +1,76 @@
+package tokenx
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidPageToken = errors.New("invalid page token")
+
+const minPageTokenSecretLength = 32
+
+// PageTokenCodec turns pagination cursors into opaque tokens that are
+// HMAC-SHA256 signed, so clients cannot forge or alter them.
+type PageTokenCodec struct {
+	key []byte
+}
+
+// NewPageTokenCodec builds a codec keyed with secret.
+// An empty secret generates an ephemeral key, which is only suitable for a single
+// instance since tokens do not survive restarts.
+func NewPageTokenCodec(secret string) (*PageTokenCodec, error) {
+	if secret == "" {
+		key := make([]byte, minPageTokenSecretLength)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("failed to generate page token key: %w", err)
+		}
+		return &PageTokenCodec{key: key}, nil
+	}
+	if len(secret) < minPageTokenSecretLength {
+		return nil, fmt.Errorf("page token secret must be at least %d bytes", minPageTokenSecretLength)
+	}
+	return &PageTokenCodec{key: []byte(secret)}, nil
+}
+
+// Encode serializes cursor as JSON and signs it.
+func (c *PageTokenCodec) Encode(cursor any) (string, error) {
+	payload, err := json.Marshal(cursor)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode page token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(c.sign(payload)), nil
+}
+
+// Decode verifies token and unmarshals its cursor into v.
+// It returns ErrInvalidPageToken for malformed or tampered tokens.
+func (c *PageTokenCodec) Decode(token string, v any) error {
+	encodedPayload, encodedSig, ok := strings.Cut(token, ".")
+	if !ok {
+		return ErrInvalidPageToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return ErrInvalidPageToken
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(encodedSig)
+	if err != nil || !hmac.Equal(sig, c.sign(payload)) {
+		return ErrInvalidPageToken
+	}
+	if err := json.Unmarshal(payload, v); err != nil {
+		return ErrInvalidPageToken
+	}
+	return nil
+}
+
+func (c *PageTokenCodec) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
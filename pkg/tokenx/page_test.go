@@ -0,0 +1,52 @@
+package tokenx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCursor struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestPageTokenCodec(t *testing.T) {
+	codec, err := NewPageTokenCodec(strings.Repeat("s", 32))
+	require.NoError(t, err)
+
+	token, err := codec.Encode(testCursor{ID: 42, Name: "alice"})
+	require.NoError(t, err)
+
+	var cursor testCursor
+	require.NoError(t, codec.Decode(token, &cursor))
+	assert.Equal(t, testCursor{ID: 42, Name: "alice"}, cursor)
+
+	t.Run("Tampered", func(t *testing.T) {
+		forged, err := codec.Encode(testCursor{ID: 1})
+		require.NoError(t, err)
+		payload, _, _ := strings.Cut(forged, ".")
+		_, sig, _ := strings.Cut(token, ".")
+
+		assert.ErrorIs(t, codec.Decode(payload+"."+sig, &cursor), ErrInvalidPageToken)
+	})
+
+	t.Run("OtherKey", func(t *testing.T) {
+		other, err := NewPageTokenCodec(strings.Repeat("o", 32))
+		require.NoError(t, err)
+		assert.ErrorIs(t, other.Decode(token, &cursor), ErrInvalidPageToken)
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		for _, token := range []string{"", "abc", "abc.def", "!!.!!"} {
+			assert.ErrorIs(t, codec.Decode(token, &cursor), ErrInvalidPageToken, token)
+		}
+	})
+}
+
+func TestNewPageTokenCodec_ShortSecret(t *testing.T) {
+	_, err := NewPageTokenCodec("short")
+	assert.Error(t, err)
+}